@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/client"
+	"pokedex-clone/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKey = "s3cret"
+
+// newServer serves the routes of newRouter calling the given upstream APIs, with API keys required.
+func newServer(
+	t *testing.T,
+	cfg *config.Config,
+	pokeAPI api.PokeAPI,
+	translationsAPI api.TranslationsAPI,
+) *httptest.Server {
+	t.Helper()
+
+	deps := newTestDeps(t, cfg, pokeAPI, translationsAPI)
+	deps.keyring.SetRequired(true)
+	assert.Nil(t, deps.keyring.SetConfigured([]string{"tests:" + testKey}))
+
+	server := httptest.NewServer(newRouter(cfg, deps))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newClient(url string) *client.Client {
+	c := client.New(url)
+	c.Auth = client.APIKey(testKey)
+	c.Backoff = time.Millisecond
+
+	return c
+}
+
+func TestClient(t *testing.T) {
+	species := &api.PokemonSpecies{
+		ID:          150,
+		Name:        "mewtwo",
+		IsLegendary: true,
+		Habitat:     api.NamedAPIResource{Name: "rare"},
+		FlavorTextEntries: []api.FlavorText{
+			{FlavorText: "It was created by a scientist.", Language: api.NamedAPIResource{Name: "en"}},
+		},
+	}
+	mewtwo := &client.Pokemon{
+		ID:          150,
+		Name:        "mewtwo",
+		Description: "It was created by a scientist.",
+		Habitat:     "rare",
+		IsLegendary: true,
+		Types:       []string{"psychic"},
+		Links: client.Links{
+			Self:       "/v2/pokemon/mewtwo",
+			Pokemon:    "/v2/pokemon/mewtwo",
+			Translated: "/v2/pokemon/translated/mewtwo",
+		},
+	}
+	translated := *mewtwo
+	translated.Description = "Created by a scientist, it was."
+	translated.Translation = &client.Translation{Type: "yoda", Translated: true}
+	translated.Links.Self = "/v2/pokemon/translated/mewtwo"
+
+	tests := map[string]struct {
+		auth       client.Auth
+		call       func(ctx context.Context, c *client.Client) (interface{}, error)
+		want       interface{}
+		wantErr    error
+		wantStatus int
+	}{
+		"pokemon": {
+			call: func(ctx context.Context, c *client.Client) (interface{}, error) {
+				return c.GetPokemon(ctx, "Mewtwo")
+			},
+			want: mewtwo,
+		},
+		"translated pokemon": {
+			call: func(ctx context.Context, c *client.Client) (interface{}, error) {
+				return c.GetTranslated(ctx, "150")
+			},
+			want: &translated,
+		},
+		"unknown pokemon": {
+			call: func(ctx context.Context, c *client.Client) (interface{}, error) {
+				return c.GetPokemon(ctx, "missingno")
+			},
+			want:       (*client.Pokemon)(nil),
+			wantErr:    client.ErrNotFound,
+			wantStatus: 404,
+		},
+		"invalid search": {
+			call: func(ctx context.Context, c *client.Client) (interface{}, error) {
+				return c.Search(ctx, "mew", client.SearchOptions{Mode: "regexp"})
+			},
+			want:       (*client.SearchResults)(nil),
+			wantErr:    client.ErrBadRequest,
+			wantStatus: 400,
+		},
+		"invalid api key": {
+			auth: client.APIKey("wrong"),
+			call: func(ctx context.Context, c *client.Client) (interface{}, error) {
+				return c.GetPokemon(ctx, "mewtwo")
+			},
+			want:       (*client.Pokemon)(nil),
+			wantErr:    client.ErrUnauthorized,
+			wantStatus: 401,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(species, nil).AnyTimes()
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "150").Return(species, nil).AnyTimes()
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound).AnyTimes()
+			mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(&api.Pokemon{
+				ID:    150,
+				Name:  "mewtwo",
+				Types: []api.PokemonType{{Slot: 1, Type: api.NamedAPIResource{Name: "psychic"}}},
+			}, nil).AnyTimes()
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), api.TTypeYoda).Return(
+				&api.TranslateAPIResponse{
+					Success:  api.Success{Total: 1},
+					Contents: api.Contents{Translated: "Created by a scientist, it was."},
+				}, nil).AnyTimes()
+			server := newServer(t, config.Default(), mockPokeAPI, mockTranslationsAPI)
+
+			c := newClient(server.URL)
+			if tt.auth != nil {
+				c.Auth = tt.auth
+			}
+
+			got, err := tt.call(context.Background(), c)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+
+			var apiErr *client.Error
+			if tt.wantStatus != 0 && assert.ErrorAs(t, err, &apiErr) {
+				assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+				assert.NotEmpty(t, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	gomock.InOrder(
+		mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(nil, errors.New("unavailable")),
+		mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(&api.NamedAPIResourceList{
+			Count: 2,
+			Results: []api.NamedAPIResource{
+				{Name: "mewtwo", URL: "https://pokeapi.co/api/v2/pokemon-species/150/"},
+				{Name: "mew", URL: "https://pokeapi.co/api/v2/pokemon-species/151/"},
+			},
+		}, nil),
+	)
+	server := newServer(t, config.Default(), mockPokeAPI, nil)
+
+	// the first search fails with a 502 while the species index can't be loaded
+	c := newClient(server.URL)
+	got, err := c.Search(context.Background(), "mew", client.SearchOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, &client.SearchResults{
+		Query: "mew",
+		Mode:  client.SearchModePrefix,
+		Results: []client.PokemonListItem{
+			{ID: 150, Name: "mewtwo", URL: "/pokemon/mewtwo"},
+			{ID: 151, Name: "mew", URL: "/pokemon/mew"},
+		},
+	}, got)
+}
+
+func TestClientRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&api.NamedAPIResourceList{}, nil).AnyTimes()
+	cfg := config.Default()
+	cfg.Auth.RequestsPerSecond, cfg.Auth.Burst = 0.001, 1
+	server := newServer(t, cfg, mockPokeAPI, nil)
+
+	c := newClient(server.URL)
+	_, err := c.Search(context.Background(), "mew", client.SearchOptions{})
+	assert.Nil(t, err)
+
+	// waiting for the Retry-After would take longer than MaxBackoff, so the error is returned right away
+	_, err = c.Search(context.Background(), "mew", client.SearchOptions{})
+	assert.ErrorIs(t, err, client.ErrRateLimited)
+	var apiErr *client.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Greater(t, apiErr.RetryAfter, c.MaxBackoff)
+	}
+}
+
+func TestClientBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	for _, name := range []string{"mewtwo", "mew"} {
+		mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), name).Return(&api.PokemonSpecies{Name: name}, nil)
+	}
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound)
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), gomock.Any()).Return(&api.Pokemon{}, nil).AnyTimes()
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&api.NamedAPIResourceList{}, nil).AnyTimes()
+	server := newServer(t, config.Default(), mockPokeAPI, nil)
+
+	c := newClient(server.URL)
+	c.BatchConcurrency = 2
+	results := c.Batch(context.Background(), []string{"mewtwo", "missingno", "mew"})
+
+	assert.Len(t, results, 3)
+	for i, name := range []string{"mewtwo", "missingno", "mew"} {
+		assert.Equal(t, name, results[i].Name)
+	}
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "mewtwo", results[0].Pokemon.Name)
+	assert.ErrorIs(t, results[1].Err, client.ErrNotFound)
+	assert.Nil(t, results[1].Pokemon)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, "mew", results[2].Pokemon.Name)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"pokedex-clone/pkg/config"
+)
+
+// runConfig implements the config subcommands, config print writes the effective configuration
+// for the given flags and environment as YAML.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: pokedex-clone config print [flags]")
+	}
+
+	cfg, err := config.Load("config print", args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return cfg.WriteYAML(os.Stdout)
+}
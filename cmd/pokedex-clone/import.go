@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"pokedex-clone/pkg/config"
+	"pokedex-clone/pkg/dataset"
+)
+
+// runImport converts the pokeapi CSV dump into the dataset file used by the local sources.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	csvDir := flags.String("csv", ".", "directory containing the pokeapi CSV files")
+	out := flags.String("out", config.Default().Upstream.Dataset, "path of the dataset file to write")
+	_ = flags.Parse(args)
+
+	d, err := dataset.Import(*csvDir)
+	if err != nil {
+		return err
+	}
+
+	if err = d.WriteFile(*out); err != nil {
+		return err
+	}
+
+	log.Printf("imported %d species, %d habitats and %d generations into %s",
+		len(d.Species), len(d.Habitats), len(d.Generations), *out)
+
+	return nil
+}
@@ -2,72 +2,574 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"pokedex-clone/pkg/admin"
 	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/config"
+	"pokedex-clone/pkg/dataset"
+	"pokedex-clone/pkg/graphqlapi"
+	"pokedex-clone/pkg/grpcapi"
+	"pokedex-clone/pkg/health"
+	"pokedex-clone/pkg/jobs"
+	"pokedex-clone/pkg/logging"
+	"pokedex-clone/pkg/metrics"
+	"pokedex-clone/pkg/openapi"
+	"pokedex-clone/pkg/pokedex"
 	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/ratelimit"
 	"pokedex-clone/pkg/storage"
+	"pokedex-clone/pkg/tracing"
+	"pokedex-clone/pkg/webhooks"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-)
-
-const (
-	ctxTimeout         = 5 * time.Second
-	serverTimeout      = 3 * time.Second
-	pokeAPIURL         = "https://pokeapi.co/api/v2/pokemon-species/"
-	translationsAPIURL = "https://api.funtranslations.com/translate/"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	storageAPI := storage.NewStore()
-	pokeAPIClient := api.NewClient(pokeAPIURL, serverTimeout)
-	pokeAPI := api.Poke{
-		Client: pokeAPIClient,
+	if len(os.Args) > 1 {
+		var command func(args []string) error
+		switch os.Args[1] {
+		case "import":
+			command = runImport
+		case "warm":
+			command = runWarm
+		case "config":
+			command = runConfig
+		}
+
+		if command != nil {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	serve(os.Args[1:])
+}
+
+func serve(args []string) {
+	load := func() (*config.Config, error) {
+		return config.Load("pokedex-clone", args, os.LookupEnv)
+	}
+
+	cfg, err := load()
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Redact)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the standard logger and the logging package functions write through logger too
+	slog.SetDefault(logger)
+
+	serviceMetrics := metrics.New()
+
+	tracerProvider, shutdownTracing, err := newTracerProvider(cfg.Tracing, cfg.Server.ShutdownTimeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing()
+	tracer := tracerProvider.Tracer(tracing.TracerName)
+
+	pokeClient := api.NewClient(cfg.Upstream.PokeAPIURL, cfg.Upstream.Timeout)
+	pokeClient.Name = "pokeapi"
+	pokeClient.AddHook(serviceMetrics.ObserveCall)
+	pokeClient.Tracer = tracer
+	pokeClient.Logger = logger
+	if cfg.Upstream.Conditional > 0 {
+		pokeClient.Validators = api.NewValidators(cfg.Upstream.Conditional)
+	}
+	pokeAPI, err := newPokeAPI(cfg.Upstream, pokeClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	storageAPI, err := newStorage(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = serviceMetrics.RegisterStore(storageAPI); err != nil {
+		log.Fatal(err)
+	}
+
+	translationsClient := api.NewClient(cfg.Upstream.TranslationsURL, cfg.Upstream.Timeout)
+	translationsClient.Name = "funtranslations"
+	translationsClient.AddHook(serviceMetrics.ObserveCall)
+	translationsClient.Tracer = tracer
+	translationsClient.Logger = logger
+	service := pokemon.NewService(storageAPI, pokeAPI, newTranslationsAPI(cfg, translationsClient))
+	service.Tracer = tracer
+	service.Logger = logger
+	limiter := ratelimit.New(cfg.Limits.RequestsPerSecond, cfg.Limits.Burst)
+
+	// issued keys get a store of their own, so flushing the cache doesn't revoke them
+	keyStore, err := newStorage(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyring := apikey.NewKeyring(keyStore)
+	keyLimiter := ratelimit.NewKeyed(cfg.Auth.RequestsPerSecond, cfg.Auth.Burst, clientKey)
+	translatedLimiter := ratelimit.NewKeyed(cfg.Auth.TranslatedRPS, cfg.Auth.TranslatedBurst, clientKey)
+
+	probes := health.New(cfg.Health.Timeout)
+	probes.AddCheck("storage", storageAPI.Ping)
+	if cfg.Upstream.BreakerThreshold > 0 {
+		for _, client := range []*api.Client{pokeClient, translationsClient} {
+			client.Breaker = api.NewBreaker(cfg.Upstream.BreakerThreshold, cfg.Upstream.BreakerCooldown)
+			if err = serviceMetrics.RegisterBreaker(client.Name, client.Breaker); err != nil {
+				log.Fatal(err)
+			}
+			probes.AddCheck(client.Name+"_breaker", client.Breaker.Check)
+		}
+	}
+	if cfg.Health.PokeAPIProbe {
+		probes.AddCheck("pokeapi", health.Cached(func(ctx context.Context) error {
+			_, err := pokeAPI.ListSpecies(ctx, 0, 1)
+			return err
+		}, cfg.Health.ProbeTTL))
+	}
+
+	apply := func(cfg *config.Config) {
+		service.SetSettings(serviceSettings(cfg))
+		pokeClient.SetBaseURL(cfg.Upstream.PokeAPIURL)
+		translationsClient.SetBaseURL(cfg.Upstream.TranslationsURL)
+		limiter.SetLimit(cfg.Limits.RequestsPerSecond, cfg.Limits.Burst)
+		keyLimiter.SetLimit(cfg.Auth.RequestsPerSecond, cfg.Auth.Burst)
+		translatedLimiter.SetLimit(cfg.Auth.TranslatedRPS, cfg.Auth.TranslatedBurst)
+		keyring.SetRequired(cfg.Auth.Required)
+		// keys are validated with the rest of the configuration
+		_ = keyring.SetConfigured(cfg.Auth.Keys)
+		level, _ := logging.ParseLevel(cfg.Log.Level)
+		logging.SetLevel(level)
+	}
+	apply(cfg)
+	reloader := &reloader{current: cfg, load: load, apply: apply}
+
+	docs, err := openapi.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	graphQL, err := graphqlapi.New(service, graphqlapi.Options{
+		MaxDepth:         cfg.GraphQL.MaxDepth,
+		MaxComplexity:    cfg.GraphQL.MaxComplexity,
+		AllowTranslation: translatedLimiter.Allow,
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-	translationsAPIClient := api.NewClient(translationsAPIURL, serverTimeout)
-	translationsAPI := api.Translations{
-		Client: translationsAPIClient,
+
+	indexCtx, stopIndex := context.WithCancel(context.Background())
+	defer stopIndex()
+
+	cache := &admin.Cache{Store: storageAPI}
+	var dispatcher *webhooks.Dispatcher
+	if cfg.Webhooks.Workers > 0 {
+		// subscriptions outlive cache flushes as well
+		webhookStore, storeErr := newStorage(cfg.Storage)
+		if storeErr != nil {
+			log.Fatal(storeErr)
+		}
+		go webhookStore.RunEviction(indexCtx, cfg.Cache.Eviction)
+		dispatcher = webhooks.New(webhookStore, webhooks.Options{
+			Workers:     cfg.Webhooks.Workers,
+			Timeout:     cfg.Webhooks.Timeout,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			Backoff:     cfg.Webhooks.Backoff,
+			MaxBackoff:  cfg.Webhooks.MaxBackoff,
+			TTL:         cfg.Webhooks.TTL,
+		})
+		dispatcher.Logger = logger
+		// hooks are added before anything uses the clients
+		pokeClient.AddHook(dispatcher.ObserveCall)
+		translationsClient.AddHook(dispatcher.ObserveCall)
+		service.OnTranslated = dispatcher.Translated
+		cache.OnInvalidate = dispatcher.Invalidated
+		go dispatcher.Run(indexCtx)
+	}
+
+	go service.Index.Run(indexCtx, cfg.Cache.IndexRefresh)
+	go storageAPI.RunEviction(indexCtx, cfg.Cache.Eviction)
+	go keyLimiter.RunEviction(indexCtx, cfg.Cache.Eviction)
+	go translatedLimiter.RunEviction(indexCtx, cfg.Cache.Eviction)
+
+	// jobs get a store of their own too, flushing the cache doesn't lose their results
+	jobStore, err := newStorage(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go jobStore.RunEviction(indexCtx, cfg.Cache.Eviction)
+	jobManager := jobs.New(indexCtx, service, jobStore, jobs.Options{
+		TTL:        cfg.Jobs.TTL,
+		Limiter:    translatedLimiter,
+		MaxRunning: cfg.Jobs.MaxRunning,
+	})
+
+	if cfg.Queue.Workers > 0 {
+		// and so do the queued translations
+		taskStore, storeErr := newStorage(cfg.Storage)
+		if storeErr != nil {
+			log.Fatal(storeErr)
+		}
+		go taskStore.RunEviction(indexCtx, cfg.Cache.Eviction)
+		service.Queue = queue.New(taskStore, service.Translate, queue.Options{
+			Workers:     cfg.Queue.Workers,
+			MaxAttempts: cfg.Queue.MaxAttempts,
+			Backoff:     cfg.Queue.Backoff,
+			MaxBackoff:  cfg.Queue.MaxBackoff,
+			TTL:         cfg.Queue.TTL,
+			Limiter:     translatedLimiter,
+		})
+		go service.Queue.Run(indexCtx)
+	}
+
+	// the pokedexes of the users are kept apart from the cache too
+	pokedexStore, err := newStorage(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tracker := pokedex.New(service.Index, pokedexStore)
+
+	if cfg.Warm.OnStart {
+		go func() {
+			report, warmErr := service.WarmCache(indexCtx, pokemon.WarmOptions{
+				RequestsPerSecond: cfg.Warm.RequestsPerSecond,
+				TranslationBudget: cfg.Warm.TranslationBudget,
+				ProgressEvery:     100,
+			})
+			if warmErr != nil {
+				logging.Warnf("cache warm-up stopped: [%v]", warmErr)
+			}
+			if report != nil {
+				logging.Infof("%s", report)
+			}
+		}()
 	}
-	service := pokemon.NewService(storageAPI, pokeAPI, translationsAPI)
 
-	// Creates a gin router with default middleware:
-	// logger and recovery (crash-free) middleware
-	router := gin.Default()
-	router.GET("/pokemon/:name", service.Get)
-	router.GET("/pokemon/translated/:name", service.GetTranslated)
+	router := newRouter(cfg, routerDeps{
+		service:           service,
+		limiter:           limiter,
+		keyring:           keyring,
+		keyLimiter:        keyLimiter,
+		translatedLimiter: translatedLimiter,
+		reloader:          reloader,
+		metrics:           serviceMetrics,
+		tracer:            tracer,
+		logger:            logger,
+		health:            probes,
+		cache:             cache,
+		keys:              &admin.Keys{Keyring: keyring},
+		docs:              docs,
+		graphQL:           graphQL,
+		jobs:              jobManager,
+		webhooks:          dispatcher,
+		pokedex:           tracker,
+	})
 
 	httpServer := &http.Server{
-		Addr:              ":5000",
+		Addr:              cfg.Server.Address,
 		Handler:           router,
-		ReadHeaderTimeout: serverTimeout,
-		WriteTimeout:      serverTimeout,
-		ReadTimeout:       serverTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		// the job event streams lift the write timeout of their connection
+		ConnContext: jobs.ConnContext,
 	}
+	// and end when shutting down, instead of holding it until their jobs finish
+	httpServer.RegisterOnShutdown(jobManager.CloseStreams)
 
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 
 	errChan := make(chan error)
 	go func() {
-		log.Printf("pokedex-clone service is starting")
+		logging.Infof("pokedex-clone service is starting on %s", cfg.Server.Address)
 		if err := httpServer.ListenAndServe(); err != nil {
 			errChan <- err
 		}
 	}()
 
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Address != "" {
+		listener, listenErr := net.Listen("tcp", cfg.GRPC.Address)
+		if listenErr != nil {
+			log.Fatal(listenErr)
+		}
+		grpcOpts := grpcapi.Options{
+			Keyring:           keyring,
+			Health:            probes,
+			Reflection:        cfg.GRPC.Reflection,
+			Limiter:           limiter,
+			KeyLimiter:        keyLimiter,
+			TranslatedLimiter: translatedLimiter,
+		}
+		// the calls are traced and recorded as the HTTP requests are, before being authenticated and limited
+		grpcServer = grpcapi.New(service, grpcOpts,
+			grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(tracer), serviceMetrics.HandleUnary),
+			grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor(tracer), serviceMetrics.HandleStream),
+		)
+		go func() {
+			logging.Infof("gRPC server is starting on %s", cfg.GRPC.Address)
+			if err := grpcServer.Serve(listener); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
+	for {
+		select {
+		case err := <-errChan:
+			log.Fatal(err)
+		case sig := <-signalChan:
+			if sig == syscall.SIGHUP {
+				// errors are logged by reload, which keeps the current configuration
+				_, _ = reloader.reload()
+				continue
+			}
+
+			// readiness fails first, so load balancers stop routing requests here before draining
+			probes.Drain()
+			drainDelay := reloader.config().Health.DrainDelay
+			logging.Infof("server shutdown initiated, draining in %s", drainDelay)
+			time.Sleep(drainDelay)
+
+			ctx, cancel := context.WithTimeout(context.Background(), reloader.config().Server.ShutdownTimeout)
+			defer cancel()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				logging.Errorf("%v", err)
+			}
+			if grpcServer != nil {
+				stopGRPC(ctx, grpcServer)
+			}
+			return
+		}
+	}
+}
+
+// routerDeps are the components newRouter routes requests to or wraps them with.
+type routerDeps struct {
+	service           *pokemon.Service
+	limiter           *ratelimit.Limiter
+	keyring           *apikey.Keyring
+	keyLimiter        *ratelimit.Keyed
+	translatedLimiter *ratelimit.Keyed
+	reloader          *reloader
+	metrics           *metrics.Metrics
+	tracer            trace.Tracer
+	logger            *slog.Logger
+	health            *health.Health
+	cache             *admin.Cache
+	keys              *admin.Keys
+	docs              *openapi.Docs
+	graphQL           *graphqlapi.Handler
+	jobs              *jobs.Manager
+	pokedex           *pokedex.Tracker
+	// webhooks is nil when webhooks are disabled
+	webhooks *webhooks.Dispatcher
+}
+
+// newRouter registers the routes of the service. The /admin routes are only registered when an
+// admin token is configured, /metrics, the probes and the API documentation aren't rate limited so they
+// keep working under load.
+// The service routes are limited overall, per API key, and per API key again for translations. They are
+// served under /v1 and /v2, the unversioned routes serve v1 and are deprecated along with it. /graphql,
+// /jobs, /tasks and /users share the limits of the service routes. /admin/webhooks is only registered when webhooks are
+// enabled.
+func newRouter(cfg *config.Config, deps routerDeps) *gin.Engine {
+	// Creates a gin router with request ID and logger, recovery (crash-free), tracing and metrics
+	// middleware, requests are logged at the info level and panics at the error level
+	router := gin.New()
+	// the proxies were validated when loading the configuration
+	_ = router.SetTrustedProxies(cfg.Server.TrustedProxies)
+	router.Use(
+		logging.Middleware(deps.logger),
+		gin.RecoveryWithWriter(slog.NewLogLogger(deps.logger.Handler(), slog.LevelError).Writer()),
+		tracing.Middleware(deps.tracer),
+		deps.metrics.Handle,
+	)
+	router.GET("/metrics", deps.metrics.Handler())
+	router.GET("/healthz", deps.health.Live)
+	router.GET("/readyz", deps.health.Ready)
+	deps.docs.Register(router)
+
+	limited := router.Group("", deps.limiter.Handle, deps.keyring.Authenticate, deps.keyLimiter.Handle)
+	// the dates were validated when loading the configuration
+	deprecation, sunset, _ := cfg.API.V1Dates()
+	for _, prefix := range []string{"", "/v1"} {
+		registerPokemon(limited.Group(prefix, pokemon.Deprecated(deprecation, sunset, prefix)), deps.service.V1(), deps)
+	}
+	registerPokemon(limited.Group(pokemon.V2Prefix), deps.service.V2(), deps)
+	// queries fetching translations take them from the translation limit of their API key one by one
+	deps.graphQL.Register(limited)
+	// jobs translate at the translation rate of their API key, in the background
+	deps.jobs.Register(limited)
+	if deps.service.Queue != nil {
+		deps.service.Queue.Register(limited)
+	}
+	deps.pokedex.Register(limited)
+
+	if cfg.Admin.Token != "" {
+		adminGroup := router.Group("/admin", deps.limiter.Handle, admin.Audit(deps.logger), admin.Auth(cfg.Admin.Token))
+		adminGroup.POST("/reload", deps.reloader.Handle)
+		deps.cache.Register(adminGroup)
+		deps.keys.Register(adminGroup)
+		if deps.webhooks != nil {
+			deps.webhooks.Register(adminGroup)
+		}
+	}
+
+	return router
+}
+
+// registerPokemon adds the pokemon routes of an API version to group, the searches are shared by every version.
+func registerPokemon(group *gin.RouterGroup, version *pokemon.Version, deps routerDeps) {
+	group.GET("/pokemon", version.List)
+	group.GET("/pokemon/search", deps.service.Search)
+	group.GET("/pokemon/:name", version.Get)
+	group.GET("/pokemon/translated/:name", deps.service.UnlessQueued(deps.translatedLimiter.Handle), version.GetTranslated)
+	group.GET("/search", deps.service.SearchDescriptions)
+}
+
+// stopGRPC lets the in-flight gRPC calls finish, cancelling the ones still running when ctx is done.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
 	select {
-	case err := <-errChan:
-		log.Fatal(err)
-	case <-signalChan:
-		ctx, cancel := context.WithTimeout(context.Background(), ctxTimeout)
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+// serviceSettings returns the reloadable service settings of a valid configuration.
+func serviceSettings(cfg *config.Config) pokemon.Settings {
+	special, _ := api.TranslationTypeByName(cfg.Translator.Special)
+	fallback, _ := api.TranslationTypeByName(cfg.Translator.Default)
+
+	return pokemon.Settings{
+		SpeciesTTL:     cfg.Cache.SpeciesTTL,
+		TranslationTTL: cfg.Cache.TranslationTTL,
+		Translation: pokemon.TranslationRules{
+			Habitats:  cfg.Translator.SpecialHabitats,
+			Legendary: cfg.Translator.SpecialLegendary,
+			Special:   special,
+			Default:   fallback,
+		},
+	}
+}
+
+// newPokeAPI returns the pokeapi implementation for the configured source.
+func newPokeAPI(cfg config.Upstream, client *api.Client) (api.PokeAPI, error) {
+	remote := api.Poke{
+		Client: client,
+	}
+
+	switch cfg.Source {
+	case config.SourceRemote:
+		return remote, nil
+	case config.SourceLocal, config.SourceLocalWithFallback:
+		d, err := dataset.ReadFile(cfg.Dataset)
+		if err != nil {
+			return nil, fmt.Errorf("loading dataset for %s source: %w", cfg.Source, err)
+		}
+
+		local := api.LocalPokeAPI{
+			Dataset: d,
+		}
+		if cfg.Source == config.SourceLocal {
+			return local, nil
+		}
+
+		return api.FallbackPokeAPI{
+			Primary:  local,
+			Fallback: remote,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q", cfg.Source)
+	}
+}
+
+// clientKey identifies the client of a request by its API key, or by its IP address when it has none, so anonymous
+// clients get the limits of a key too.
+func clientKey(c *gin.Context) string {
+	if key, ok := apikey.FromContext(c); ok {
+		return key.ID
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// newTracerProvider returns the tracer provider for the configured exporter, and a function
+// flushing the spans not exported yet within timeout.
+func newTracerProvider(cfg config.Tracing, timeout time.Duration) (trace.TracerProvider, func(), error) {
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case config.ExporterNone:
+		return trace.NewNoopTracerProvider(), func() {}, nil
+	case config.ExporterStdout:
+		exporter = tracing.NewStdoutExporter(os.Stdout)
+	case config.ExporterOTLP:
+		var err error
+		if exporter, err = tracing.NewOTLPExporter(context.Background(), cfg.Endpoint); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown span exporter %q", cfg.Exporter)
+	}
+
+	provider := tracing.NewProvider(exporter, cfg.ServiceName, cfg.SampleRatio)
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
-		log.Println("server shutdown initiated")
-		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Println(err)
+		if err := provider.Shutdown(ctx); err != nil {
+			logging.Errorf("failed to flush spans: [%v]", err)
 		}
 	}
+
+	return provider, shutdown, nil
+}
+
+// newStorage returns the cache storage for the configured backend.
+func newStorage(cfg config.Storage) (*storage.Store, error) {
+	switch cfg.Backend {
+	case config.BackendMemory:
+		return storage.NewStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// newTranslationsAPI returns the configured translations provider.
+func newTranslationsAPI(cfg *config.Config, client *api.Client) api.TranslationsAPI {
+	if cfg.Translator.Provider == config.TranslatorNone {
+		return api.NoTranslations{}
+	}
+
+	return api.Translations{
+		Client: client,
+	}
 }
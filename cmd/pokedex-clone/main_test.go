@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/config"
+	"pokedex-clone/pkg/graphqlapi"
+	"pokedex-clone/pkg/health"
+	"pokedex-clone/pkg/jobs"
+	"pokedex-clone/pkg/metrics"
+	"pokedex-clone/pkg/openapi"
+	"pokedex-clone/pkg/pokedex"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/ratelimit"
+	"pokedex-clone/pkg/storage"
+	"pokedex-clone/pkg/webhooks"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+// routeParam matches the gin path parameters, named and catch-all.
+var routeParam = regexp.MustCompile(`[:*]([^/]+)`) //nolint:gochecknoglobals // compiled once
+
+// newTestDeps returns the dependencies of newRouter, with the limits of cfg and calling the given upstream APIs,
+// which may be nil for tests not reaching them.
+func newTestDeps(
+	t *testing.T,
+	cfg *config.Config,
+	pokeAPI api.PokeAPI,
+	translationsAPI api.TranslationsAPI,
+) routerDeps {
+	t.Helper()
+
+	docs, err := openapi.New()
+	assert.Nil(t, err)
+
+	store := storage.NewStore()
+	service := pokemon.NewService(store, pokeAPI, translationsAPI)
+	service.Queue = queue.New(storage.NewStore(), service.Translate, queue.Options{})
+	graphQL, err := graphqlapi.New(service, graphqlapi.Options{})
+	assert.Nil(t, err)
+	keyring := apikey.NewKeyring(store)
+
+	return routerDeps{
+		service:           service,
+		limiter:           ratelimit.New(cfg.Limits.RequestsPerSecond, cfg.Limits.Burst),
+		keyring:           keyring,
+		keyLimiter:        ratelimit.NewKeyed(cfg.Auth.RequestsPerSecond, cfg.Auth.Burst, clientKey),
+		translatedLimiter: ratelimit.NewKeyed(cfg.Auth.TranslatedRPS, cfg.Auth.TranslatedBurst, clientKey),
+		reloader:          &reloader{current: cfg},
+		metrics:           metrics.New(),
+		tracer:            trace.NewNoopTracerProvider().Tracer(""),
+		logger:            slog.New(slog.NewTextHandler(io.Discard)),
+		health:            health.New(time.Second),
+		cache:             &admin.Cache{Store: store},
+		keys:              &admin.Keys{Keyring: keyring},
+		docs:              docs,
+		graphQL:           graphQL,
+		jobs:              jobs.New(context.Background(), service, storage.NewStore(), jobs.Options{}),
+		webhooks:          webhooks.New(storage.NewStore(), webhooks.Options{}),
+		pokedex:           pokedex.New(service.Index, storage.NewStore()),
+	}
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	cfg := config.Default()
+	// every route is registered, the admin ones included
+	cfg.Admin.Token = "s3cret"
+	deps := newTestDeps(t, cfg, nil, nil)
+	router := newRouter(cfg, deps)
+
+	var routes []openapi.Operation
+	for _, route := range router.Routes() {
+		// the Swagger UI files aren't part of the API
+		if route.Path == openapi.DocsPath || strings.HasPrefix(route.Path, openapi.DocsPath+"/") {
+			continue
+		}
+		routes = append(routes, openapi.Operation{
+			Method: route.Method,
+			Path:   routeParam.ReplaceAllString(route.Path, "{$1}"),
+		})
+	}
+
+	assert.ElementsMatch(t, deps.docs.Operations(), routes)
+}
+
+func TestAnonymousClientsAreLimited(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.TranslatedRPS, cfg.Auth.TranslatedBurst = 0.001, 1
+	router := newRouter(cfg, newTestDeps(t, cfg, nil, nil))
+
+	serve := func(remoteAddr, forwardedFor string) int {
+		// the invalid name is rejected after the limiter took its token, without calling the upstream APIs
+		req := httptest.NewRequest(http.MethodGet, "/pokemon/translated/pika$chu", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve("192.0.2.1:4242", ""))
+	assert.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:4343", ""))
+	// clients can't pass for another one without a trusted proxy
+	assert.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:4444", "198.51.100.7"))
+	assert.Equal(t, http.StatusBadRequest, serve("192.0.2.2:4242", ""))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/config"
+	"pokedex-clone/pkg/logging"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// reloader loads the configuration again on SIGHUP or POST /admin/reload and applies its
+// reloadable keys to the running server, without restarting it.
+type reloader struct {
+	sync.Mutex
+	current *config.Config
+	load    func() (*config.Config, error)
+	apply   func(cfg *config.Config)
+}
+
+func (r *reloader) config() *config.Config {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.current
+}
+
+// reload applies the reloadable changes and logs every change. The current configuration is
+// kept when the new one fails to load or validate.
+func (r *reloader) reload() ([]config.Change, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		logging.Errorf("failed to reload config, keeping the current one: [%v]", err)
+		return nil, err
+	}
+
+	reloaded, changes := r.current.Reload(next)
+	for _, change := range changes {
+		if change.Reloadable {
+			logging.Infof("config reloaded %s", change)
+		} else {
+			logging.Warnf("config change needs a restart, ignoring %s", change)
+		}
+	}
+	if len(changes) == 0 {
+		logging.Infof("config reloaded, nothing changed")
+	}
+
+	r.apply(reloaded)
+	r.current = reloaded
+
+	return changes, nil
+}
+
+// Handle reloads the configuration and responds with the changes.
+func (r *reloader) Handle(c *gin.Context) {
+	changes, err := r.reload()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	admin.AddAudit(c, slog.Int("changes", len(changes)))
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
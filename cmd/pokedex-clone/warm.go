@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os/signal"
+	"pokedex-clone/pkg/config"
+	"pokedex-clone/pkg/pokemon"
+	"strings"
+	"syscall"
+)
+
+const warmPageSize = 100
+
+// runWarm fills the cache of a running server by requesting every species it lists, so the
+// server caches them through its own service logic.
+func runWarm(args []string) error {
+	flags := flag.NewFlagSet("warm", flag.ExitOnError)
+	target := flags.String("target", "http://localhost:5000", "base URL of the pokedex-clone server to warm")
+	concurrency := flags.Int("concurrency", 4, "species warmed at the same time")
+	rps := flags.Float64("rps", 5, "species requests started per second, 0 for no limit")
+	budget := flags.Int("translation-budget", 0, "translated requests the run may spend, 0 to skip translations")
+	progressFile := flags.String("progress-file", "", "file recording warmed species so an interrupted run can resume")
+	_ = flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	server := warmServer{
+		baseURL: strings.TrimSuffix(*target, "/"),
+		client:  &http.Client{Timeout: config.Default().Upstream.Timeout},
+	}
+
+	names, err := server.listNames(ctx)
+	if err != nil {
+		return fmt.Errorf("listing species from %s: %w", server.baseURL, err)
+	}
+
+	report, err := pokemon.Warm(ctx, server, names, pokemon.WarmOptions{
+		Concurrency:       *concurrency,
+		RequestsPerSecond: *rps,
+		TranslationBudget: *budget,
+		ProgressFile:      *progressFile,
+		ProgressEvery:     warmPageSize,
+	})
+	if report != nil {
+		log.Println(report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d species failed to warm", report.Failed)
+	}
+
+	return nil
+}
+
+// warmServer implements pokemon.WarmTarget against a running server over HTTP.
+type warmServer struct {
+	baseURL string
+	client  *http.Client
+}
+
+// WarmSpecies requests the species, the server doesn't tell whether it was cached already.
+func (w warmServer) WarmSpecies(ctx context.Context, name string) (bool, error) {
+	return false, w.get(ctx, "/pokemon/"+url.PathEscape(name), nil)
+}
+
+// WarmTranslated requests the translated species, assuming the request spent a translation
+// since the server doesn't tell whether it was served from its cache.
+func (w warmServer) WarmTranslated(ctx context.Context, name string) (bool, error) {
+	return true, w.get(ctx, "/pokemon/translated/"+url.PathEscape(name), nil)
+}
+
+// listNames pages through the species listing of the server.
+func (w warmServer) listNames(ctx context.Context) ([]string, error) {
+	var names []string
+	for offset := 0; ; offset += warmPageSize {
+		var page pokemon.PokemonList
+		if err := w.get(ctx, fmt.Sprintf("/pokemon?offset=%d&limit=%d", offset, warmPageSize), &page); err != nil {
+			return nil, err
+		}
+
+		for _, p := range page.Results {
+			names = append(names, p.Name)
+		}
+
+		if page.Next == "" {
+			return names, nil
+		}
+	}
+}
+
+func (w warmServer) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status code %d", path, res.StatusCode)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,55 @@
+// Admin package provides the /admin API of the service: bearer token authentication, an audit
+// log of every admin request, and the cache inspection and invalidation endpoints.
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+const auditKey = "admin.audit"
+
+// Auth rejects requests without the admin bearer token.
+func Auth(token string) gin.HandlerFunc {
+	want := []byte("Bearer " + token)
+
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), want) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Audit logs every admin request once served, rejected ones included, along with the attributes
+// the handler added with AddAudit. It must run before Auth.
+func Audit(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("query", c.Request.URL.RawQuery),
+			slog.Int("status", c.Writer.Status()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if extra, ok := c.Get(auditKey); ok {
+			attrs = append(attrs, extra.([]slog.Attr)...)
+		}
+
+		logger.LogAttrs(c.Request.Context(), slog.LevelInfo, "admin audit", attrs...)
+	}
+}
+
+// AddAudit adds attrs to the audit log line of the request, e.g. what an operation changed.
+func AddAudit(c *gin.Context, attrs ...slog.Attr) {
+	if existing, ok := c.Get(auditKey); ok {
+		attrs = append(existing.([]slog.Attr), attrs...)
+	}
+	c.Set(auditKey, attrs)
+}
@@ -0,0 +1,204 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"pokedex-clone/pkg/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+const defaultCacheLimit = 100
+
+// Cache serves the cache inspection and invalidation endpoints.
+type Cache struct {
+	Store *storage.Store
+	// OnInvalidate is called after every deletion, e.g. to notify the webhook subscriptions, when not nil.
+	OnInvalidate func(Invalidation)
+}
+
+// Invalidation describes a deletion, Key, Pattern and Flush tell whether one key, the keys matching a pattern or
+// every key was deleted.
+type Invalidation struct {
+	Key     string `json:"key,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Flush   bool   `json:"flush,omitempty"`
+	Removed int    `json:"removed"`
+}
+
+// CacheQuery holds the pagination and filter parameters of the key listing.
+type CacheQuery struct {
+	Prefix string `form:"prefix"`
+	Offset int    `form:"offset" binding:"min=0"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// CacheList is a single page of the cached entries, without their values.
+type CacheList struct {
+	Count    int          `json:"count"`
+	Next     string       `json:"next,omitempty"`
+	Previous string       `json:"previous,omitempty"`
+	Results  []CacheEntry `json:"results"`
+}
+
+// CacheEntry describes a cached value. Age and TTL are durations such as "1h2m3s", TTL and
+// ExpiresAt are omitted for values that never expire.
+type CacheEntry struct {
+	Key       string      `json:"key"`
+	Source    string      `json:"source,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Age       string      `json:"age"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	TTL       string      `json:"ttl,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// Removed is the response of the deletions.
+type Removed struct {
+	Removed int `json:"removed"`
+}
+
+// Register adds the cache routes to group:
+//
+//	GET    /cache           list the keys, paginated and filtered by prefix
+//	GET    /cache/*key      get an entry with its value
+//	DELETE /cache/*key      delete an entry
+//	DELETE /cache?pattern=  delete the keys matching a path.Match pattern
+//	POST   /cache/flush     delete everything
+func (h *Cache) Register(group *gin.RouterGroup) {
+	group.GET("/cache", h.List)
+	group.GET("/cache/*key", h.Get)
+	group.DELETE("/cache", h.DeleteMatching)
+	group.DELETE("/cache/*key", h.Delete)
+	group.POST("/cache/flush", h.Flush)
+}
+
+// List returns a page of the cached entries, sorted by key.
+func (h *Cache) List(c *gin.Context) {
+	var req CacheQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultCacheLimit
+	}
+
+	metas := h.Store.List(req.Prefix)
+	now := time.Now()
+	page := CacheList{Count: len(metas), Results: make([]CacheEntry, 0, req.Limit)}
+	for i := req.Offset; i < len(metas) && i < req.Offset+req.Limit; i++ {
+		page.Results = append(page.Results, newCacheEntry(metas[i], now))
+	}
+
+	if req.Offset+req.Limit < len(metas) {
+		page.Next = pageLink(c.Request.URL, req.Offset+req.Limit, req.Limit)
+	}
+	if req.Offset > 0 {
+		prevOffset := req.Offset - req.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		page.Previous = pageLink(c.Request.URL, prevOffset, req.Limit)
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Get returns the entry of a key with its value.
+func (h *Cache) Get(c *gin.Context) {
+	key := cacheKey(c)
+	e, ok := h.Store.Inspect(key)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "key " + strconv.Quote(key) + " not cached"})
+		return
+	}
+
+	entry := newCacheEntry(e.Meta, time.Now())
+	entry.Value = e.Value
+	c.JSON(http.StatusOK, entry)
+}
+
+// Delete removes a key.
+func (h *Cache) Delete(c *gin.Context) {
+	key := cacheKey(c)
+	AddAudit(c, slog.String("key", key))
+
+	if !h.Store.Exist(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "key " + strconv.Quote(key) + " not cached"})
+		return
+	}
+
+	h.Store.Remove(key)
+	h.invalidated(Invalidation{Key: key, Removed: 1})
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteMatching removes the keys matching the pattern query parameter, where * matches
+// any characters but /, e.g. generation/* or mewtwo*.
+func (h *Cache) DeleteMatching(c *gin.Context) {
+	pattern := c.Query("pattern")
+	if pattern == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pattern is required, use POST /admin/cache/flush to delete everything"})
+		return
+	}
+
+	removed, err := h.Store.RemoveMatching(pattern)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pattern: " + err.Error()})
+		return
+	}
+
+	h.invalidated(Invalidation{Pattern: pattern, Removed: removed})
+	AddAudit(c, slog.String("pattern", pattern), slog.Int("removed", removed))
+	c.JSON(http.StatusOK, Removed{Removed: removed})
+}
+
+// Flush removes every key.
+func (h *Cache) Flush(c *gin.Context) {
+	removed := h.Store.Flush()
+	h.invalidated(Invalidation{Flush: true, Removed: removed})
+
+	AddAudit(c, slog.Int("removed", removed))
+	c.JSON(http.StatusOK, Removed{Removed: removed})
+}
+
+func (h *Cache) invalidated(invalidation Invalidation) {
+	if h.OnInvalidate != nil {
+		h.OnInvalidate(invalidation)
+	}
+}
+
+// cacheKey returns the key of the *key route parameter, keys may contain slashes.
+func cacheKey(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("key"), "/")
+}
+
+func newCacheEntry(m storage.Meta, now time.Time) CacheEntry {
+	entry := CacheEntry{
+		Key:       m.Key,
+		Source:    m.Source,
+		CreatedAt: m.CreatedAt,
+		Age:       now.Sub(m.CreatedAt).Round(time.Second).String(),
+	}
+	if !m.ExpiresAt.IsZero() {
+		expiresAt := m.ExpiresAt
+		entry.ExpiresAt = &expiresAt
+		entry.TTL = m.ExpiresAt.Sub(now).Round(time.Second).String()
+	}
+
+	return entry
+}
+
+func pageLink(reqURL *url.URL, offset, limit int) string {
+	query := reqURL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	return reqURL.Path + "?" + query.Encode()
+}
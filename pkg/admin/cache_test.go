@@ -0,0 +1,252 @@
+package admin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/logging"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const token = "s3cret"
+
+func newRouter(t *testing.T, cache *admin.Cache, audit *bytes.Buffer) *gin.Engine {
+	t.Helper()
+
+	logger, err := logging.New(audit, logging.FormatJSON, nil)
+	assert.Nil(t, err)
+
+	router := gin.New()
+	group := router.Group("/admin", admin.Audit(logger), admin.Auth(token))
+	cache.Register(group)
+
+	return router
+}
+
+func newStore(t *testing.T) *storage.Store {
+	t.Helper()
+
+	store := storage.NewStore()
+	assert.Nil(t, store.SaveWithSource("mewtwo", map[string]string{"name": "mewtwo"}, time.Hour, "pokeapi"))
+	assert.Nil(t, store.SaveWithSource("mewtwoyoda.json", "translated", 0, "funtranslations"))
+	assert.Nil(t, store.SaveWithSource("generation/generation-i", "members", time.Hour, "pokeapi"))
+	assert.Nil(t, store.SaveWithSource("id/150", "mewtwo", 0, "pokeapi"))
+	assert.Nil(t, store.SaveWithTTL("expired", "gone", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	return store
+}
+
+func serve(router *gin.Engine, method, target, auth string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", "Bearer "+auth)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestCacheList(t *testing.T) {
+	tests := map[string]struct {
+		target       string
+		wantStatus   int
+		wantKeys     []string
+		wantCount    int
+		wantNext     string
+		wantPrevious string
+	}{
+		"lists every unexpired key in order": {
+			target:     "/admin/cache",
+			wantStatus: http.StatusOK,
+			wantKeys:   []string{"generation/generation-i", "id/150", "mewtwo", "mewtwoyoda.json"},
+			wantCount:  4,
+		},
+		"filters by prefix": {
+			target:     "/admin/cache?prefix=mewtwo",
+			wantStatus: http.StatusOK,
+			wantKeys:   []string{"mewtwo", "mewtwoyoda.json"},
+			wantCount:  2,
+		},
+		"paginates": {
+			target:       "/admin/cache?offset=1&limit=2",
+			wantStatus:   http.StatusOK,
+			wantKeys:     []string{"id/150", "mewtwo"},
+			wantCount:    4,
+			wantNext:     "/admin/cache?limit=2&offset=3",
+			wantPrevious: "/admin/cache?limit=2&offset=0",
+		},
+		"rejects a negative offset": {
+			target:     "/admin/cache?offset=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(newRouter(t, &admin.Cache{Store: newStore(t)}, &bytes.Buffer{}), http.MethodGet, tt.target, token)
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page admin.CacheList
+			assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &page))
+			keys := make([]string, 0, len(page.Results))
+			for _, e := range page.Results {
+				keys = append(keys, e.Key)
+				assert.Nil(t, e.Value, "listings leave the values out")
+			}
+			assert.Equal(t, tt.wantKeys, keys)
+			assert.Equal(t, tt.wantCount, page.Count)
+			assert.Equal(t, tt.wantNext, page.Next)
+			assert.Equal(t, tt.wantPrevious, page.Previous)
+		})
+	}
+}
+
+func TestCacheGet(t *testing.T) {
+	router := newRouter(t, &admin.Cache{Store: newStore(t)}, &bytes.Buffer{})
+
+	rr := serve(router, http.MethodGet, "/admin/cache/id/150", token)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var entry admin.CacheEntry
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &entry))
+	assert.Equal(t, "id/150", entry.Key)
+	assert.Equal(t, "pokeapi", entry.Source)
+	assert.Equal(t, "mewtwo", entry.Value)
+	assert.Equal(t, "0s", entry.Age)
+	assert.Empty(t, entry.TTL, "the dex number never expires")
+	assert.Nil(t, entry.ExpiresAt)
+
+	rr = serve(router, http.MethodGet, "/admin/cache/mewtwo", token)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &entry))
+	assert.Equal(t, "1h0m0s", entry.TTL)
+	assert.NotNil(t, entry.ExpiresAt)
+	assert.Equal(t, map[string]interface{}{"name": "mewtwo"}, entry.Value)
+
+	for _, target := range []string{"/admin/cache/missing", "/admin/cache/expired"} {
+		assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, target, token).Code, target)
+	}
+}
+
+func TestCacheDelete(t *testing.T) {
+	tests := map[string]struct {
+		method      string
+		target      string
+		wantStatus  int
+		wantRemoved int
+		wantKeys    []string
+		wantAudit   string
+		// wantInvalidations are the deletions passed to OnInvalidate
+		wantInvalidations []admin.Invalidation
+	}{
+		"deletes a key": {
+			method:            http.MethodDelete,
+			target:            "/admin/cache/mewtwoyoda.json",
+			wantStatus:        http.StatusNoContent,
+			wantKeys:          []string{"generation/generation-i", "id/150", "mewtwo"},
+			wantAudit:         `"key":"mewtwoyoda.json"`,
+			wantInvalidations: []admin.Invalidation{{Key: "mewtwoyoda.json", Removed: 1}},
+		},
+		"deleting a missing key is not found": {
+			method:     http.MethodDelete,
+			target:     "/admin/cache/missing",
+			wantStatus: http.StatusNotFound,
+			wantKeys:   []string{"generation/generation-i", "id/150", "mewtwo", "mewtwoyoda.json"},
+			wantAudit:  `"status":404`,
+		},
+		"deletes by pattern": {
+			method:            http.MethodDelete,
+			target:            "/admin/cache?pattern=mewtwo*",
+			wantStatus:        http.StatusOK,
+			wantRemoved:       2,
+			wantKeys:          []string{"generation/generation-i", "id/150"},
+			wantAudit:         `"pattern":"mewtwo*","removed":2`,
+			wantInvalidations: []admin.Invalidation{{Pattern: "mewtwo*", Removed: 2}},
+		},
+		"patterns don't match slashes with *": {
+			method:            http.MethodDelete,
+			target:            "/admin/cache?pattern=*",
+			wantStatus:        http.StatusOK,
+			wantRemoved:       3,
+			wantKeys:          []string{"generation/generation-i", "id/150"},
+			wantInvalidations: []admin.Invalidation{{Pattern: "*", Removed: 3}},
+		},
+		"rejects a missing pattern": {
+			method:     http.MethodDelete,
+			target:     "/admin/cache",
+			wantStatus: http.StatusBadRequest,
+			wantKeys:   []string{"generation/generation-i", "id/150", "mewtwo", "mewtwoyoda.json"},
+		},
+		"rejects an invalid pattern": {
+			method:     http.MethodDelete,
+			target:     "/admin/cache?pattern=%5B",
+			wantStatus: http.StatusBadRequest,
+			wantKeys:   []string{"generation/generation-i", "id/150", "mewtwo", "mewtwoyoda.json"},
+		},
+		"flushes everything": {
+			method:            http.MethodPost,
+			target:            "/admin/cache/flush",
+			wantStatus:        http.StatusOK,
+			wantRemoved:       5,
+			wantKeys:          []string{},
+			wantAudit:         `"removed":5`,
+			wantInvalidations: []admin.Invalidation{{Flush: true, Removed: 5}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			var audit bytes.Buffer
+			var invalidations []admin.Invalidation
+			cache := &admin.Cache{Store: store, OnInvalidate: func(invalidation admin.Invalidation) {
+				invalidations = append(invalidations, invalidation)
+			}}
+			rr := serve(newRouter(t, cache, &audit), tt.method, tt.target, token)
+			assert.Equal(t, tt.wantStatus, rr.Code)
+
+			if tt.wantRemoved > 0 {
+				var removed admin.Removed
+				assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &removed))
+				assert.Equal(t, tt.wantRemoved, removed.Removed)
+			}
+
+			keys := []string{}
+			for _, m := range store.List("") {
+				keys = append(keys, m.Key)
+			}
+			assert.Equal(t, tt.wantKeys, keys)
+			assert.Equal(t, tt.wantInvalidations, invalidations)
+
+			assert.Contains(t, audit.String(), `"msg":"admin audit"`)
+			assert.Contains(t, audit.String(), tt.wantAudit)
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	store := newStore(t)
+
+	for name, auth := range map[string]string{"missing token": "", "wrong token": "guess"} {
+		t.Run(name, func(t *testing.T) {
+			var audit bytes.Buffer
+			rr := serve(newRouter(t, &admin.Cache{Store: store}, &audit), http.MethodPost, "/admin/cache/flush", auth)
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.Len(t, store.List(""), 4)
+
+			// rejected requests are audited too
+			assert.Contains(t, audit.String(), `"path":"/admin/cache/flush","query":"","status":401`)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"pokedex-clone/pkg/apikey"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// Keys serves the API key endpoints.
+type Keys struct {
+	Keyring *apikey.Keyring
+}
+
+// IssueRequest is the body of POST /admin/keys, Name identifies the client in listings and logs.
+type IssueRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// IssuedKey is the response of POST /admin/keys, the only one carrying the secret key.
+type IssuedKey struct {
+	apikey.Key
+	Secret string `json:"key"`
+}
+
+// Register adds the API key routes to group:
+//
+//	GET    /keys      list the keys, without their secrets
+//	POST   /keys      issue a key
+//	DELETE /keys/:id  revoke an issued key
+func (h *Keys) Register(group *gin.RouterGroup) {
+	group.GET("/keys", h.List)
+	group.POST("/keys", h.Issue)
+	group.DELETE("/keys/:id", h.Revoke)
+}
+
+// List returns every key.
+func (h *Keys) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": h.Keyring.List()})
+}
+
+// Issue creates a key, the secret key is only part of this response.
+func (h *Keys) Issue(c *gin.Context) {
+	var req IssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, secret, err := h.Keyring.Issue(req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	AddAudit(c, slog.String("key_id", key.ID), slog.String("key_name", key.Name))
+	c.JSON(http.StatusCreated, IssuedKey{Key: key, Secret: secret})
+}
+
+// Revoke removes an issued key, configured keys are removed from the configuration instead.
+func (h *Keys) Revoke(c *gin.Context) {
+	id := c.Param("id")
+	AddAudit(c, slog.String("key_id", id))
+
+	switch err := h.Keyring.Revoke(id); {
+	case errors.Is(err, apikey.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apikey.ErrConfigured):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
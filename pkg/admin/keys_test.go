@@ -0,0 +1,65 @@
+package admin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/logging"
+	"pokedex-clone/pkg/storage"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"web:k3y"}))
+
+	var audit bytes.Buffer
+	logger, err := logging.New(&audit, logging.FormatJSON, nil)
+	assert.Nil(t, err)
+
+	router := gin.New()
+	group := router.Group("/admin", admin.Audit(logger), admin.Auth(token))
+	(&admin.Keys{Keyring: keyring}).Register(group)
+
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/admin/keys", `{}`).Code, "the name is required")
+
+	rr := send(http.MethodPost, "/admin/keys", `{"name": "cli"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var issued admin.IssuedKey
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &issued))
+	assert.Equal(t, "cli", issued.Name)
+	key, ok := keyring.Lookup(issued.Secret)
+	assert.True(t, ok, "the issued key authenticates")
+	assert.Equal(t, issued.ID, key.ID)
+
+	rr = send(http.MethodGet, "/admin/keys", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), issued.Secret, "listings leave the secrets out")
+	assert.Contains(t, rr.Body.String(), `"name":"web"`)
+	assert.Contains(t, rr.Body.String(), `"name":"cli"`)
+
+	assert.Equal(t, http.StatusConflict, send(http.MethodDelete, "/admin/keys/config:web", "").Code)
+	assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/admin/keys/"+issued.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, "/admin/keys/"+issued.ID, "").Code)
+	_, ok = keyring.Lookup(issued.Secret)
+	assert.False(t, ok, "the revoked key doesn't authenticate")
+
+	assert.Contains(t, audit.String(), `"key_id":"`+issued.ID+`","key_name":"cli"`)
+	assert.NotContains(t, audit.String(), issued.Secret)
+}
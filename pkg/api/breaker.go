@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned for the requests a client fails without sending them, while its breaker is open.
+var ErrBreakerOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a Breaker, ordered from healthy to failing.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen lets a single request through, which closes the breaker when it succeeds.
+	BreakerHalfOpen
+	// BreakerOpen fails every request until the cooldown is over.
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// Breaker stops a client from calling an upstream API which keeps failing: it opens after threshold
+// consecutive failures, and lets a request through again once cooldown has passed. Requests without a
+// response, and 429 and 5xx responses, are failures.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probing is set while the request let through by the half-open breaker is in flight.
+	probing bool
+}
+
+// NewBreaker returns a closed breaker, threshold must be positive.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// State returns the state of the breaker, an open breaker whose cooldown is over is half-open.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.current()
+}
+
+// Check is a health.Check failing while the breaker is open.
+func (b *Breaker) Check(context.Context) error {
+	if state := b.State(); state == BreakerOpen {
+		return ErrBreakerOpen
+	}
+
+	return nil
+}
+
+// allow reports whether a request may be sent, record must be called with its outcome when it is.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = b.current()
+	switch b.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+
+	return true
+}
+
+// record counts the outcome of a request allowed through, err is the error of the request and status the
+// status code of its response, zero when there is none.
+func (b *Breaker) record(status int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the caller gave up, which says nothing about the upstream API
+	case status == 0 && err != nil, status == http.StatusTooManyRequests, status >= http.StatusInternalServerError:
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+		}
+	default:
+		b.state = BreakerClosed
+		b.failures = 0
+	}
+}
+
+func (b *Breaker) current() BreakerState {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
@@ -3,43 +3,161 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"pokedex-clone/pkg/logging"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
 )
 
 type Client struct {
-	BaseURL    string
+	// Name identifies the upstream API in the calls passed to hooks, e.g. "pokeapi".
+	Name       string
 	HTTPClient *http.Client
+	// Tracer creates a client span around every request, NewClient sets one that records nothing.
+	// The W3C traceparent of the request context is sent upstream either way.
+	Tracer trace.Tracer
+	// Logger logs every request at the debug level, NewClient sets the default logger.
+	Logger *slog.Logger
+	// Breaker, when set, fails requests without sending them while the upstream API keeps failing.
+	Breaker *Breaker
+	// Validators, when set, makes GET requests conditional on the validators of the previous response
+	// to the same URL, a 304 Not Modified response is decoded from the body remembered then.
+	Validators *Validators
+	baseURL    atomic.Value
+	hooks      []Hook
 	// todo retry/backoff
 }
 
+// Call describes a finished upstream request.
+type Call struct {
+	// API is the Name of the client.
+	API string
+	// Operation is what was requested, e.g. "pokemon-species" or the translation type "yoda".
+	Operation string
+	// StatusCode is zero when no response was received.
+	StatusCode int
+	Header     http.Header
+	Duration   time.Duration
+	Err        error
+}
+
+// Hook is called after every request sent by a client, e.g. to record metrics.
+type Hook func(call Call)
+
 func NewClient(url string, timeout time.Duration) *Client {
-	return &Client{
-		BaseURL: url,
+	c := &Client{
 		HTTPClient: &http.Client{
 			Timeout: timeout,
 		},
+		Tracer: trace.NewNoopTracerProvider().Tracer(""),
+		Logger: slog.Default(),
 	}
+	c.SetBaseURL(url)
+
+	return c
+}
+
+// BaseURL returns the URL requests are relative to.
+func (c *Client) BaseURL() string {
+	url, _ := c.baseURL.Load().(string)
+	return url
 }
 
-func (c *Client) sendRequest(req *http.Request, v interface{}) error {
+// AddHook registers h for every later request, hooks must be added before the client is used.
+func (c *Client) AddHook(h Hook) {
+	c.hooks = append(c.hooks, h)
+}
+
+// SetBaseURL points later requests at url, requests in flight are unaffected.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL.Store(url)
+}
+
+// sendRequest sends req and decodes its response into v, operation labels the call for hooks.
+// The request ID of the request context, if any, is sent upstream in the X-Request-ID header.
+func (c *Client) sendRequest(req *http.Request, operation string, v interface{}) (err error) {
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "application/json; charset=utf-8")
 
+	ctx, span := c.Tracer.Start(req.Context(), c.Name+" "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("http.url", req.URL.String()),
+			attribute.String("peer.service", c.Name),
+		))
+	req = req.WithContext(ctx)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	conditional := c.Validators != nil && req.Method == http.MethodGet
+	if conditional {
+		c.Validators.prepare(req)
+	}
+
+	call := Call{API: c.Name, Operation: operation}
+	started := time.Now()
+	defer func() {
+		call.Duration = time.Since(started)
+		call.Err = err
+
+		if call.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.status_code", call.StatusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		attrs := []interface{}{
+			"api", call.API, "operation", call.Operation, "status", call.StatusCode, "duration", call.Duration,
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		c.Logger.DebugCtx(ctx, "upstream call", attrs...)
+
+		for _, h := range c.hooks {
+			h(call)
+		}
+	}()
+
+	if c.Breaker != nil {
+		if !c.Breaker.allow() {
+			return fmt.Errorf("%s: %w", c.Name, ErrBreakerOpen)
+		}
+		defer func() { c.Breaker.record(call.StatusCode, err) }()
+	}
+
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
+	call.StatusCode = res.StatusCode
+	call.Header = res.Header
 
 	defer res.Body.Close()
 
+	if conditional {
+		return c.decodeConditional(req, res, v)
+	}
+
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
 		var errRes apiError
 		if err = json.NewDecoder(res.Body).Decode(&errRes); err == nil {
 			return errRes
 		}
 
-		return fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+		return statusError{code: res.StatusCode}
 	}
 
 	if err = json.NewDecoder(res.Body).Decode(&v); err != nil {
@@ -48,3 +166,39 @@ func (c *Client) sendRequest(req *http.Request, v interface{}) error {
 
 	return nil
 }
+
+// decodeConditional decodes the response to a conditional request into v, remembering its body
+// and validators, or decoding the body remembered before when the response is a 304.
+func (c *Client) decodeConditional(req *http.Request, res *http.Response, v interface{}) error {
+	url := req.URL.String()
+
+	if res.StatusCode == http.StatusNotModified {
+		body, ok := c.Validators.body(url)
+		if !ok {
+			return fmt.Errorf("not modified response without a remembered body for %s", url)
+		}
+
+		return json.Unmarshal(body, v)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		var errRes apiError
+		if err = json.Unmarshal(body, &errRes); err == nil {
+			return errRes
+		}
+
+		return statusError{code: res.StatusCode}
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		return err
+	}
+	c.Validators.remember(url, res.Header, body)
+
+	return nil
+}
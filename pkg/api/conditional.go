@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Validators remembers the ETag and Last-Modified validators of upstream responses along with their
+// bodies, so a client refreshing an entry sends a conditional request and reuses the body on a 304.
+// The bodies kept are bounded by their total size.
+type Validators struct {
+	mu      sync.Mutex
+	max     int
+	size    int
+	entries map[string]validated
+}
+
+type validated struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// NewValidators returns Validators keeping responses whose bodies total at most max bytes, or any number of them
+// when max isn't positive.
+func NewValidators(max int) *Validators {
+	return &Validators{max: max, entries: map[string]validated{}}
+}
+
+// Len returns the number of responses kept.
+func (v *Validators) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return len(v.entries)
+}
+
+// Size returns the total size of the bodies kept, in bytes.
+func (v *Validators) Size() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.size
+}
+
+// prepare adds the conditional headers for the response remembered for req, if any.
+func (v *Validators) prepare(req *http.Request) {
+	v.mu.Lock()
+	e, ok := v.entries[req.URL.String()]
+	v.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
+
+// body returns the body remembered for url.
+func (v *Validators) body(url string) ([]byte, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e, ok := v.entries[url]
+
+	return e.body, ok
+}
+
+// remember keeps body for url when the response carries validators and fits, forgetting it otherwise.
+func (v *Validators) remember(url string, header http.Header, body []byte) {
+	e := validated{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified"), body: body}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.forget(url)
+	if e.etag == "" && e.lastModified == "" || v.max > 0 && len(body) > v.max {
+		return
+	}
+
+	// map iteration order is random, which makes this a random eviction
+	for evicted := range v.entries {
+		if v.max <= 0 || v.size+len(body) <= v.max {
+			break
+		}
+		v.forget(evicted)
+	}
+	v.entries[url] = e
+	v.size += len(body)
+}
+
+func (v *Validators) forget(url string) {
+	if e, ok := v.entries[url]; ok {
+		v.size -= len(e.body)
+		delete(v.entries, url)
+	}
+}
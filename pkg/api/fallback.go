@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"pokedex-clone/pkg/logging"
+)
+
+// FallbackPokeAPI serves requests from Primary and retries them against Fallback when Primary
+// fails, e.g. a local dataset backed by the remote pokeapi for species imported after it.
+type FallbackPokeAPI struct {
+	Primary  PokeAPI
+	Fallback PokeAPI
+}
+
+func (f FallbackPokeAPI) GetSpecies(ctx context.Context, name string) (*PokemonSpecies, error) {
+	res, err := f.Primary.GetSpecies(ctx, name)
+	if err != nil {
+		logging.Warnf("falling back for species %s: [%v]", name, err)
+		return f.Fallback.GetSpecies(ctx, name)
+	}
+
+	return res, nil
+}
+
+func (f FallbackPokeAPI) ListSpecies(ctx context.Context, offset, limit int) (*NamedAPIResourceList, error) {
+	res, err := f.Primary.ListSpecies(ctx, offset, limit)
+	if err != nil {
+		logging.Warnf("falling back for species list: [%v]", err)
+		return f.Fallback.ListSpecies(ctx, offset, limit)
+	}
+
+	return res, nil
+}
+
+func (f FallbackPokeAPI) GetGeneration(ctx context.Context, name string) (*Generation, error) {
+	res, err := f.Primary.GetGeneration(ctx, name)
+	if err != nil {
+		logging.Warnf("falling back for generation %s: [%v]", name, err)
+		return f.Fallback.GetGeneration(ctx, name)
+	}
+
+	return res, nil
+}
+
+func (f FallbackPokeAPI) GetHabitat(ctx context.Context, name string) (*PokemonHabitat, error) {
+	res, err := f.Primary.GetHabitat(ctx, name)
+	if err != nil {
+		logging.Warnf("falling back for habitat %s: [%v]", name, err)
+		return f.Fallback.GetHabitat(ctx, name)
+	}
+
+	return res, nil
+}
+
+func (f FallbackPokeAPI) GetPokemon(ctx context.Context, name string) (*Pokemon, error) {
+	res, err := f.Primary.GetPokemon(ctx, name)
+	if err != nil {
+		logging.Warnf("falling back for pokemon %s: [%v]", name, err)
+		return f.Fallback.GetPokemon(ctx, name)
+	}
+
+	return res, nil
+}
+
+func (f FallbackPokeAPI) GetEvolutionChain(ctx context.Context, id int) (*EvolutionChain, error) {
+	res, err := f.Primary.GetEvolutionChain(ctx, id)
+	if err != nil {
+		logging.Warnf("falling back for evolution chain %d: [%v]", id, err)
+		return f.Fallback.GetEvolutionChain(ctx, id)
+	}
+
+	return res, nil
+}
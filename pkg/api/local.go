@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"pokedex-clone/pkg/dataset"
+	"strconv"
+)
+
+// LocalPokeAPI serves pokeapi resources from an imported dataset instead of the network.
+type LocalPokeAPI struct {
+	Dataset *dataset.Dataset
+}
+
+func (l LocalPokeAPI) GetSpecies(_ context.Context, name string) (*PokemonSpecies, error) {
+	s, ok := l.Dataset.Lookup(name)
+	if !ok {
+		return nil, notFound("pokemon-species", name)
+	}
+
+	entries := make([]FlavorText, 0, len(s.FlavorTexts))
+	for _, ft := range s.FlavorTexts {
+		entries = append(entries, FlavorText{
+			FlavorText: ft.Text,
+			Language:   NamedAPIResource{Name: ft.Language},
+			Version:    NamedAPIResource{Name: ft.Version},
+		})
+	}
+
+	return &PokemonSpecies{
+		ID:                 s.ID,
+		Name:               s.Name,
+		FlavorTextEntries:  entries,
+		Habitat:            namedResource("pokemon-habitat", s.Habitat),
+		IsLegendary:        s.IsLegendary,
+		Generation:         namedResource("generation", s.Generation),
+		EvolvesFromSpecies: l.evolvesFrom(s),
+		EvolutionChain:     APIResource{URL: namedResource("evolution-chain", dataset.Named{ID: s.EvolutionChainID}).URL},
+	}, nil
+}
+
+// evolvesFrom references the species s evolves from, when it is part of the dataset.
+func (l LocalPokeAPI) evolvesFrom(s *dataset.Species) *NamedAPIResource {
+	if s.EvolvesFromID == 0 {
+		return nil
+	}
+
+	from, ok := l.Dataset.Lookup(strconv.Itoa(s.EvolvesFromID))
+	if !ok {
+		return nil
+	}
+	res := namedResource("pokemon-species", dataset.Named{ID: from.ID, Name: from.Name})
+
+	return &res
+}
+
+func (l LocalPokeAPI) ListSpecies(_ context.Context, offset, limit int) (*NamedAPIResourceList, error) {
+	count := len(l.Dataset.Species)
+
+	res := &NamedAPIResourceList{Count: count}
+	for i := offset; i < count && i < offset+limit; i++ {
+		s := l.Dataset.Species[i]
+		res.Results = append(res.Results, namedResource("pokemon-species", dataset.Named{ID: s.ID, Name: s.Name}))
+	}
+
+	if offset+limit < count {
+		next := fmt.Sprintf("pokemon-species/?offset=%d&limit=%d", offset+limit, limit)
+		res.Next = &next
+	}
+
+	return res, nil
+}
+
+func (l LocalPokeAPI) GetGeneration(_ context.Context, name string) (*Generation, error) {
+	generation, ok := l.lookupGeneration(name)
+	if !ok {
+		return nil, notFound("generation", name)
+	}
+
+	res := &Generation{
+		ID:         generation.ID,
+		Name:       generation.Name,
+		MainRegion: namedResource("region", generation.MainRegion),
+	}
+	for _, s := range l.Dataset.Species {
+		if s.Generation.ID == generation.ID {
+			res.PokemonSpecies = append(res.PokemonSpecies, namedResource("pokemon-species", dataset.Named{ID: s.ID, Name: s.Name}))
+		}
+	}
+
+	return res, nil
+}
+
+func (l LocalPokeAPI) GetHabitat(_ context.Context, name string) (*PokemonHabitat, error) {
+	habitat, ok := lookupNamed(l.Dataset.Habitats, name)
+	if !ok {
+		return nil, notFound("pokemon-habitat", name)
+	}
+
+	res := &PokemonHabitat{ID: habitat.ID, Name: habitat.Name}
+	for _, s := range l.Dataset.Species {
+		if s.Habitat.ID == habitat.ID {
+			res.PokemonSpecies = append(res.PokemonSpecies, namedResource("pokemon-species", dataset.Named{ID: s.ID, Name: s.Name}))
+		}
+	}
+
+	return res, nil
+}
+
+// GetPokemon returns the default pokemon of a species, whose types are empty when the dataset was
+// imported without them.
+func (l LocalPokeAPI) GetPokemon(_ context.Context, name string) (*Pokemon, error) {
+	s, ok := l.Dataset.Lookup(name)
+	if !ok {
+		return nil, notFound("pokemon", name)
+	}
+
+	res := &Pokemon{ID: s.ID, Name: s.Name, Types: make([]PokemonType, 0, len(s.Types))}
+	for i, t := range s.Types {
+		res.Types = append(res.Types, PokemonType{Slot: i + 1, Type: NamedAPIResource{Name: t}})
+	}
+
+	return res, nil
+}
+
+// GetEvolutionChain builds the chain from the species of the dataset in it. Species whose previous
+// species wasn't imported start the chain, and the conditions of the evolutions are left out.
+func (l LocalPokeAPI) GetEvolutionChain(_ context.Context, id int) (*EvolutionChain, error) {
+	var roots []dataset.Species
+	next := make(map[int][]dataset.Species)
+	for _, s := range l.Dataset.Species {
+		if s.EvolutionChainID != id {
+			continue
+		}
+		if _, hasPrevious := l.Dataset.Lookup(strconv.Itoa(s.EvolvesFromID)); hasPrevious {
+			next[s.EvolvesFromID] = append(next[s.EvolvesFromID], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil, notFound("evolution-chain", strconv.Itoa(id))
+	}
+
+	var link func(s dataset.Species) ChainLink
+	link = func(s dataset.Species) ChainLink {
+		res := ChainLink{
+			Species:   namedResource("pokemon-species", dataset.Named{ID: s.ID, Name: s.Name}),
+			EvolvesTo: make([]ChainLink, 0, len(next[s.ID])),
+		}
+		for _, to := range next[s.ID] {
+			res.EvolvesTo = append(res.EvolvesTo, link(to))
+		}
+
+		return res
+	}
+
+	return &EvolutionChain{ID: id, Chain: link(roots[0])}, nil
+}
+
+// namedResource builds a resource reference whose URL ends with the id, as pokeapi ones do.
+func namedResource(resource string, n dataset.Named) NamedAPIResource {
+	if n.ID == 0 {
+		return NamedAPIResource{}
+	}
+
+	return NamedAPIResource{
+		Name: n.Name,
+		URL:  fmt.Sprintf("%s/%d/", resource, n.ID),
+	}
+}
+
+func lookupNamed(named []dataset.Named, nameOrID string) (dataset.Named, bool) {
+	id, _ := strconv.Atoi(nameOrID)
+	for _, n := range named {
+		if n.Name == nameOrID || n.ID == id {
+			return n, true
+		}
+	}
+
+	return dataset.Named{}, false
+}
+
+func (l LocalPokeAPI) lookupGeneration(nameOrID string) (dataset.Generation, bool) {
+	id, _ := strconv.Atoi(nameOrID)
+	for _, g := range l.Dataset.Generations {
+		if g.Name == nameOrID || g.ID == id {
+			return g, true
+		}
+	}
+
+	return dataset.Generation{}, false
+}
+
+func notFound(resource, name string) error {
+	return apiError{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("%s %s not found in local dataset", resource, name),
+	}
+}
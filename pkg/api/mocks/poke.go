@@ -49,3 +49,78 @@ func (mr *MockPokeAPIMockRecorder) GetSpecies(ctx, name interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpecies", reflect.TypeOf((*MockPokeAPI)(nil).GetSpecies), ctx, name)
 }
+
+// ListSpecies mocks base method.
+func (m *MockPokeAPI) ListSpecies(ctx context.Context, offset, limit int) (*api.NamedAPIResourceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSpecies", ctx, offset, limit)
+	ret0, _ := ret[0].(*api.NamedAPIResourceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSpecies indicates an expected call of ListSpecies.
+func (mr *MockPokeAPIMockRecorder) ListSpecies(ctx, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSpecies", reflect.TypeOf((*MockPokeAPI)(nil).ListSpecies), ctx, offset, limit)
+}
+
+// GetGeneration mocks base method.
+func (m *MockPokeAPI) GetGeneration(ctx context.Context, name string) (*api.Generation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGeneration", ctx, name)
+	ret0, _ := ret[0].(*api.Generation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGeneration indicates an expected call of GetGeneration.
+func (mr *MockPokeAPIMockRecorder) GetGeneration(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGeneration", reflect.TypeOf((*MockPokeAPI)(nil).GetGeneration), ctx, name)
+}
+
+// GetHabitat mocks base method.
+func (m *MockPokeAPI) GetHabitat(ctx context.Context, name string) (*api.PokemonHabitat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitat", ctx, name)
+	ret0, _ := ret[0].(*api.PokemonHabitat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitat indicates an expected call of GetHabitat.
+func (mr *MockPokeAPIMockRecorder) GetHabitat(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitat", reflect.TypeOf((*MockPokeAPI)(nil).GetHabitat), ctx, name)
+}
+
+// GetPokemon mocks base method.
+func (m *MockPokeAPI) GetPokemon(ctx context.Context, name string) (*api.Pokemon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPokemon", ctx, name)
+	ret0, _ := ret[0].(*api.Pokemon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPokemon indicates an expected call of GetPokemon.
+func (mr *MockPokeAPIMockRecorder) GetPokemon(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemon", reflect.TypeOf((*MockPokeAPI)(nil).GetPokemon), ctx, name)
+}
+
+// GetEvolutionChain mocks base method.
+func (m *MockPokeAPI) GetEvolutionChain(ctx context.Context, id int) (*api.EvolutionChain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvolutionChain", ctx, id)
+	ret0, _ := ret[0].(*api.EvolutionChain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvolutionChain indicates an expected call of GetEvolutionChain.
+func (mr *MockPokeAPIMockRecorder) GetEvolutionChain(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvolutionChain", reflect.TypeOf((*MockPokeAPI)(nil).GetEvolutionChain), ctx, id)
+}
@@ -4,12 +4,20 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 //go:generate mockgen -destination mocks/poke.go -package mocks -source poke.go
 
 type PokeAPI interface {
 	GetSpecies(ctx context.Context, name string) (*PokemonSpecies, error)
+	ListSpecies(ctx context.Context, offset, limit int) (*NamedAPIResourceList, error)
+	GetGeneration(ctx context.Context, name string) (*Generation, error)
+	GetHabitat(ctx context.Context, name string) (*PokemonHabitat, error)
+	GetPokemon(ctx context.Context, name string) (*Pokemon, error)
+	GetEvolutionChain(ctx context.Context, id int) (*EvolutionChain, error)
 }
 
 type Poke struct {
@@ -18,17 +26,74 @@ type Poke struct {
 }
 
 func (p Poke) GetSpecies(ctx context.Context, name string) (*PokemonSpecies, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s", p.Client.BaseURL, name), nil)
-	if err != nil {
+	var res PokemonSpecies
+	if err := p.get(ctx, "pokemon-species/"+url.PathEscape(name), &res); err != nil {
 		return nil, err
 	}
 
-	req = req.WithContext(ctx)
+	return &res, nil
+}
 
-	var res PokemonSpecies
-	if reqErr := p.Client.sendRequest(req, &res); reqErr != nil {
-		return nil, reqErr
+// ListSpecies returns a page of the paginated species list.
+func (p Poke) ListSpecies(ctx context.Context, offset, limit int) (*NamedAPIResourceList, error) {
+	var res NamedAPIResourceList
+	if err := p.get(ctx, fmt.Sprintf("pokemon-species/?offset=%d&limit=%d", offset, limit), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetGeneration returns the generation with the given name or id, including the species it introduced.
+func (p Poke) GetGeneration(ctx context.Context, name string) (*Generation, error) {
+	var res Generation
+	if err := p.get(ctx, "generation/"+url.PathEscape(name), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetHabitat returns the habitat with the given name or id, including the species living in it.
+func (p Poke) GetHabitat(ctx context.Context, name string) (*PokemonHabitat, error) {
+	var res PokemonHabitat
+	if err := p.get(ctx, "pokemon-habitat/"+url.PathEscape(name), &res); err != nil {
+		return nil, err
 	}
 
 	return &res, nil
 }
+
+// GetPokemon returns the pokemon with the given name or id, the default pokemon of a species shares its id.
+func (p Poke) GetPokemon(ctx context.Context, name string) (*Pokemon, error) {
+	var res Pokemon
+	if err := p.get(ctx, "pokemon/"+url.PathEscape(name), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetEvolutionChain returns the evolution chain with the given id, as referenced by the species in it.
+func (p Poke) GetEvolutionChain(ctx context.Context, id int) (*EvolutionChain, error) {
+	var res EvolutionChain
+	if err := p.get(ctx, "evolution-chain/"+strconv.Itoa(id), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (p Poke) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, p.Client.BaseURL()+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	// the resource, e.g. pokemon-species, is the operation reported to hooks
+	operation := strings.SplitN(path, "/", 2)[0]
+
+	return p.Client.sendRequest(req, operation, v)
+}
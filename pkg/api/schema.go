@@ -1,6 +1,15 @@
 package api
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound matches the errors of requests for resources the upstream API doesn't have.
+var ErrNotFound = errors.New("not found")
 
 type apiError struct {
 	Code    int    `json:"code"`
@@ -11,12 +20,34 @@ func (e apiError) Error() string {
 	return fmt.Sprintf("code: %v message: %s", e.Code, e.Message)
 }
 
+func (e apiError) Is(target error) bool {
+	return target == ErrNotFound && e.Code == http.StatusNotFound
+}
+
+// statusError is returned for unsuccessful responses whose body isn't an apiError.
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unknown error, status code: %d", e.code)
+}
+
+func (e statusError) Is(target error) bool {
+	return target == ErrNotFound && e.code == http.StatusNotFound
+}
+
 // PokemonSpecies represents the returned payload from pokeapi.
 type PokemonSpecies struct {
+	ID                int              `json:"id"`
 	Name              string           `json:"name"`
 	FlavorTextEntries []FlavorText     `json:"flavor_text_entries"`
 	Habitat           NamedAPIResource `json:"habitat"`
 	IsLegendary       bool             `json:"is_legendary"`
+	Generation        NamedAPIResource `json:"generation"`
+	// EvolvesFromSpecies is nil for the first species of an evolution chain.
+	EvolvesFromSpecies *NamedAPIResource `json:"evolves_from_species"`
+	EvolutionChain     APIResource       `json:"evolution_chain"`
 }
 
 type FlavorText struct {
@@ -30,6 +61,79 @@ type NamedAPIResource struct {
 	URL  string `json:"url"`
 }
 
+// ID returns the numeric id pokeapi embeds as the last path segment of the resource URL.
+func (r NamedAPIResource) ID() (int, error) {
+	return APIResource{URL: r.URL}.ID()
+}
+
+// APIResource references an unnamed pokeapi resource, e.g. an evolution chain.
+type APIResource struct {
+	URL string `json:"url"`
+}
+
+// ID returns the numeric id pokeapi embeds as the last path segment of the resource URL.
+func (r APIResource) ID() (int, error) {
+	trimmed := strings.TrimSuffix(r.URL, "/")
+	return strconv.Atoi(trimmed[strings.LastIndex(trimmed, "/")+1:])
+}
+
+// NamedAPIResourceList is a single page of a paginated pokeapi resource list.
+type NamedAPIResourceList struct {
+	Count    int                `json:"count"`
+	Next     *string            `json:"next"`
+	Previous *string            `json:"previous"`
+	Results  []NamedAPIResource `json:"results"`
+}
+
+// Generation represents a pokeapi generation and the species introduced in it, which live in its main region.
+type Generation struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	MainRegion     NamedAPIResource   `json:"main_region"`
+	PokemonSpecies []NamedAPIResource `json:"pokemon_species"`
+}
+
+// Pokemon represents a pokeapi pokemon, a form of a species with its own types.
+type Pokemon struct {
+	ID    int           `json:"id"`
+	Name  string        `json:"name"`
+	Types []PokemonType `json:"types"`
+}
+
+// PokemonType is one of the types of a pokemon, pokemon with two types have them in slots 1 and 2.
+type PokemonType struct {
+	Slot int              `json:"slot"`
+	Type NamedAPIResource `json:"type"`
+}
+
+// PokemonHabitat represents a pokeapi habitat and the species living in it.
+type PokemonHabitat struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	PokemonSpecies []NamedAPIResource `json:"pokemon_species"`
+}
+
+// EvolutionChain represents a pokeapi evolution chain, the tree of the species evolving from its first one.
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// ChainLink is a species of an evolution chain along with the species it evolves to.
+type ChainLink struct {
+	Species NamedAPIResource `json:"species"`
+	// EvolutionDetails are the ways the species evolves from the previous one, empty for the first species.
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail is one way a species evolves, the conditions pokeapi leaves out are nil.
+type EvolutionDetail struct {
+	Trigger  NamedAPIResource  `json:"trigger"`
+	MinLevel *int              `json:"min_level"`
+	Item     *NamedAPIResource `json:"item"`
+}
+
 type TranslationText struct {
 	Text string `json:"text"`
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 //go:generate mockgen -destination mocks/translations.go -package mocks -source translations.go
@@ -25,6 +26,16 @@ const (
 	TTypeShakespeare TranslationType = "shakespeare.json"
 )
 
+// TranslationTypeByName returns the translation type for names such as "yoda" used in configuration.
+func TranslationTypeByName(name string) (TranslationType, bool) {
+	switch t := TranslationType(name + ".json"); t {
+	case TTypeYoda, TTypeShakespeare:
+		return t, true
+	default:
+		return "", false
+	}
+}
+
 func (t Translations) GetTranslation(
 	ctx context.Context,
 	name, text string,
@@ -36,7 +47,7 @@ func (t Translations) GetTranslation(
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, t.Client.BaseURL+string(translationType), bytes.NewBuffer(b))
+	req, err := http.NewRequest(http.MethodPost, t.Client.BaseURL()+string(translationType), bytes.NewBuffer(b))
 	if err != nil {
 		return nil, err
 	}
@@ -44,9 +55,16 @@ func (t Translations) GetTranslation(
 	req = req.WithContext(ctx)
 
 	var res TranslateAPIResponse
-	if reqErr := t.Client.sendRequest(req, &res); reqErr != nil {
+	if reqErr := t.Client.sendRequest(req, strings.TrimSuffix(string(translationType), ".json"), &res); reqErr != nil {
 		return nil, reqErr
 	}
 
 	return &res, nil
 }
+
+// NoTranslations is a TranslationsAPI that never translates, descriptions are served as they are.
+type NoTranslations struct{}
+
+func (NoTranslations) GetTranslation(context.Context, string, string, TranslationType) (*TranslateAPIResponse, error) {
+	return &TranslateAPIResponse{}, nil
+}
@@ -0,0 +1,220 @@
+// Apikey package provides the API keys clients authenticate with. Keys are either configured,
+// or issued and revoked through the admin API and kept in a storage backend. Only the SHA-256
+// hash of a key is kept, the key itself is shown once when issued.
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"pokedex-clone/pkg/random"
+	"pokedex-clone/pkg/storage"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the API key of a request.
+const Header = "X-API-Key"
+
+// Sources of the keys.
+const (
+	SourceConfig = "config"
+	SourceIssued = "issued"
+)
+
+const (
+	storePrefix = "apikey/"
+	contextKey  = "apikey"
+	keyPrefix   = "pdx_"
+)
+
+// ErrNotFound is returned when revoking a key that doesn't exist.
+var ErrNotFound = errors.New("api key not found")
+
+// ErrConfigured is returned when revoking a configured key, which must be removed from the configuration.
+var ErrConfigured = errors.New("configured api keys can't be revoked, remove them from the configuration")
+
+// Key identifies a client, without the secret key itself.
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Source    string     `json:"source"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	hash      string
+}
+
+// Keyring holds the configured and issued keys.
+type Keyring struct {
+	store      *storage.Store
+	configured atomic.Value
+	required   atomic.Bool
+}
+
+// NewKeyring returns a Keyring keeping issued keys in store, apart from the cache so that flushing it revokes no key.
+func NewKeyring(store *storage.Store) *Keyring {
+	k := &Keyring{store: store}
+	k.configured.Store(map[string]Key{})
+
+	return k
+}
+
+// SetRequired makes Authenticate reject requests without a key, or accept them again.
+func (k *Keyring) SetRequired(required bool) {
+	k.required.Store(required)
+}
+
+// Required tells whether requests without a key are rejected.
+func (k *Keyring) Required() bool {
+	return k.required.Load()
+}
+
+// SetConfigured replaces the configured keys, given as name:key pairs.
+func (k *Keyring) SetConfigured(pairs []string) error {
+	keys := make(map[string]Key, len(pairs))
+	for _, pair := range pairs {
+		name, secret, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || secret == "" {
+			return fmt.Errorf("api key of %q must be a name:key pair", name)
+		}
+
+		key := Key{ID: SourceConfig + ":" + name, Name: name, Source: SourceConfig, hash: hash(secret)}
+		keys[key.hash] = key
+	}
+
+	k.configured.Store(keys)
+
+	return nil
+}
+
+// Issue creates a key for the client name, returning it along with the secret key to hand over.
+func (k *Keyring) Issue(name string) (Key, string, error) {
+	secret, err := random.Hex(24)
+	if err != nil {
+		return Key{}, "", err
+	}
+	id, err := random.Hex(8)
+	if err != nil {
+		return Key{}, "", err
+	}
+	secret = keyPrefix + secret
+
+	now := time.Now().UTC()
+	key := Key{ID: id, Name: name, Source: SourceIssued, CreatedAt: &now, hash: hash(secret)}
+	if err = k.store.Save(storePrefix+key.hash, key); err != nil {
+		return Key{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+// Revoke removes the issued key with the given id.
+func (k *Keyring) Revoke(id string) error {
+	for _, key := range k.List() {
+		if key.ID != id {
+			continue
+		}
+		if key.Source == SourceConfig {
+			return ErrConfigured
+		}
+
+		k.store.Remove(storePrefix + key.hash)
+		return nil
+	}
+
+	return ErrNotFound
+}
+
+// List returns every key, configured ones first, sorted by name.
+func (k *Keyring) List() []Key {
+	configured, _ := k.configured.Load().(map[string]Key)
+
+	keys := make([]Key, 0, len(configured))
+	for _, key := range configured {
+		keys = append(keys, key)
+	}
+
+	var issued []Key
+	for _, m := range k.store.List(storePrefix) {
+		if e, ok := k.store.Inspect(m.Key); ok {
+			if key, isKey := e.Value.(Key); isKey {
+				issued = append(issued, key)
+			}
+		}
+	}
+
+	sortKeys(keys)
+	sortKeys(issued)
+
+	return append(keys, issued...)
+}
+
+// Lookup returns the key matching secret.
+func (k *Keyring) Lookup(secret string) (Key, bool) {
+	h := hash(secret)
+
+	configured, _ := k.configured.Load().(map[string]Key)
+	if key, ok := configured[h]; ok {
+		return key, true
+	}
+
+	if e, ok := k.store.Inspect(storePrefix + h); ok {
+		key, isKey := e.Value.(Key)
+		return key, isKey
+	}
+
+	return Key{}, false
+}
+
+// Authenticate is the gin middleware identifying clients by the key in their X-API-Key header.
+// Unknown keys are rejected with 401, so are requests without a key once SetRequired(true) is called.
+func (k *Keyring) Authenticate(c *gin.Context) {
+	secret := c.GetHeader(Header)
+	if secret == "" {
+		if k.required.Load() {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing api key, set the " + Header + " header"})
+			return
+		}
+		c.Next()
+		return
+	}
+
+	key, ok := k.Lookup(secret)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+		return
+	}
+
+	c.Set(contextKey, key)
+	c.Next()
+}
+
+// FromContext returns the key the request was authenticated with, if any.
+func FromContext(c *gin.Context) (Key, bool) {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return Key{}, false
+	}
+	key, ok := value.(Key)
+
+	return key, ok
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].ID < keys[j].ID
+	})
+}
@@ -0,0 +1,105 @@
+package apikey_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"web:k3y"}))
+	assert.NotNil(t, keyring.SetConfigured([]string{"web"}), "keys are name:key pairs")
+
+	key, ok := keyring.Lookup("k3y")
+	assert.True(t, ok, "a failed SetConfigured keeps the current keys")
+	assert.Equal(t, "config:web", key.ID)
+	assert.Equal(t, "web", key.Name)
+	assert.Equal(t, apikey.SourceConfig, key.Source)
+
+	issued, secret, err := keyring.Issue("cli")
+	assert.Nil(t, err)
+	assert.Regexp(t, "^pdx_[0-9a-f]{48}$", secret)
+	assert.Equal(t, apikey.SourceIssued, issued.Source)
+	assert.NotNil(t, issued.CreatedAt)
+
+	key, ok = keyring.Lookup(secret)
+	assert.True(t, ok)
+	assert.Equal(t, issued.ID, key.ID)
+
+	names := []string{}
+	for _, k := range keyring.List() {
+		names = append(names, k.Name)
+	}
+	assert.Equal(t, []string{"web", "cli"}, names)
+
+	assert.ErrorIs(t, keyring.Revoke("config:web"), apikey.ErrConfigured)
+	assert.Nil(t, keyring.Revoke(issued.ID))
+	assert.ErrorIs(t, keyring.Revoke(issued.ID), apikey.ErrNotFound)
+	_, ok = keyring.Lookup(secret)
+	assert.False(t, ok)
+
+	assert.Nil(t, keyring.SetConfigured(nil))
+	_, ok = keyring.Lookup("k3y")
+	assert.False(t, ok, "keys removed from the configuration stop working")
+}
+
+func TestAuthenticate(t *testing.T) {
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"web:k3y"}))
+
+	router := gin.New()
+	router.Use(keyring.Authenticate)
+	router.GET("/pokemon", func(c *gin.Context) {
+		key, _ := apikey.FromContext(c)
+		c.String(http.StatusOK, key.Name)
+	})
+
+	tests := map[string]struct {
+		required   bool
+		key        string
+		wantStatus int
+		wantBody   string
+	}{
+		"a valid key identifies the client": {
+			key:        "k3y",
+			wantStatus: http.StatusOK,
+			wantBody:   "web",
+		},
+		"an invalid key is rejected": {
+			key:        "guess",
+			wantStatus: http.StatusUnauthorized,
+		},
+		"no key is accepted when keys aren't required": {
+			wantStatus: http.StatusOK,
+		},
+		"no key is rejected when keys are required": {
+			required:   true,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			keyring.SetRequired(tt.required)
+
+			req, err := http.NewRequest(http.MethodGet, "/pokemon", nil)
+			assert.Nil(t, err)
+			if tt.key != "" {
+				req.Header.Set(apikey.Header, tt.key)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import "net/http"
+
+// APIKeyHeader carries the API key of a request.
+const APIKeyHeader = "X-API-Key"
+
+// Auth authorizes the requests of a client, e.g. setting a header.
+type Auth interface {
+	Authorize(req *http.Request) error
+}
+
+// AuthFunc is an Auth calling itself.
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authorize(req *http.Request) error {
+	return f(req)
+}
+
+// APIKey authorizes requests with key, identifying the client for its own rate limits.
+func APIKey(key string) Auth {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set(APIKeyHeader, key)
+		return nil
+	})
+}
+
+// BearerToken authorizes requests with token in the Authorization header.
+func BearerToken(token string) Auth {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
@@ -0,0 +1,271 @@
+// Client package provides a typed Go client of the pokedex-clone v2 API, with context support, retries of
+// failed and rate limited requests, errors mirroring the server responses and pluggable authentication.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultMaxRetries       = 2
+	defaultBackoff          = 100 * time.Millisecond
+	defaultMaxBackoff       = 5 * time.Second
+	defaultBatchConcurrency = 8
+
+	// prefix is the path prefix of the API version the client calls.
+	prefix = "/v2"
+)
+
+// Client calls a pokedex-clone server, its fields must be set before it is used.
+type Client struct {
+	HTTPClient *http.Client
+	// Auth authorizes every request, requests are sent anonymously when nil.
+	Auth Auth
+	// MaxRetries is the number of times a request is retried after a transport error, a 429 or a 502,
+	// 503 or 504 response. Zero disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled at every retry up to MaxBackoff. Rate limited
+	// requests wait for their Retry-After instead, and aren't retried when it's longer than MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// BatchConcurrency is the number of requests Batch sends at once.
+	BatchConcurrency int
+	baseURL          string
+}
+
+// New returns a client of the server at baseURL, e.g. http://localhost:5000, retrying requests twice.
+func New(baseURL string) *Client {
+	return &Client{
+		HTTPClient:       &http.Client{Timeout: defaultTimeout},
+		MaxRetries:       defaultMaxRetries,
+		Backoff:          defaultBackoff,
+		MaxBackoff:       defaultMaxBackoff,
+		BatchConcurrency: defaultBatchConcurrency,
+		baseURL:          strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// GetPokemon returns a species by name or national dex number, with its first english description.
+func (c *Client) GetPokemon(ctx context.Context, name string) (*Pokemon, error) {
+	var res Pokemon
+	if err := c.get(ctx, "/pokemon/"+url.PathEscape(name), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetTranslated returns a species by name or national dex number, with its description translated.
+func (c *Client) GetTranslated(ctx context.Context, name string) (*Pokemon, error) {
+	var res Pokemon
+	if err := c.get(ctx, "/pokemon/translated/"+url.PathEscape(name), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// BatchResult is the outcome of fetching one of the species of a batch.
+type BatchResult struct {
+	Name    string
+	Pokemon *Pokemon
+	Err     error
+}
+
+// Batch fetches every species of names, BatchConcurrency at a time as the server serves them one by one.
+// The results are in the order of names, each failing on its own.
+func (c *Client) Batch(ctx context.Context, names []string) []BatchResult {
+	concurrency := c.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	res := make([]BatchResult, len(names))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			p, err := c.GetPokemon(ctx, name)
+			res[i] = BatchResult{Name: name, Pokemon: p, Err: err}
+		}(i, name)
+	}
+	wg.Wait()
+
+	return res
+}
+
+// SearchOptions refine Search, the zero value searches by prefix with the server default limit.
+type SearchOptions struct {
+	// Mode is SearchModePrefix or SearchModeFuzzy.
+	Mode  string
+	Limit int
+}
+
+const (
+	SearchModePrefix = "prefix"
+	SearchModeFuzzy  = "fuzzy"
+)
+
+// Search autocompletes species names.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) (*SearchResults, error) {
+	params := url.Values{"q": {query}}
+	if opts.Mode != "" {
+		params.Set("mode", opts.Mode)
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var res SearchResults
+	if err := c.get(ctx, "/pokemon/search", params, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// DescriptionOptions refine SearchDescriptions, the zero value searches every language.
+type DescriptionOptions struct {
+	Lang  string
+	Limit int
+}
+
+// SearchDescriptions searches the descriptions of the species the server fetched so far.
+func (c *Client) SearchDescriptions(
+	ctx context.Context, query string, opts DescriptionOptions,
+) (*DescriptionResults, error) {
+	params := url.Values{"q": {query}}
+	if opts.Lang != "" {
+		params.Set("lang", opts.Lang)
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var res DescriptionResults
+	if err := c.get(ctx, "/search", params, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// get sends a GET request for path, retrying it as configured, and decodes the response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	target := c.baseURL + prefix + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, target, v)
+		if err == nil {
+			return nil
+		}
+
+		delay, retry := c.retryDelay(ctx, err, attempt)
+		if !retry {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Auth != nil {
+		if err = c.Auth.Authorize(req); err != nil {
+			return fmt.Errorf("failed to authorize the request: %w", err)
+		}
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return newError(res)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// retryDelay tells whether the request failing with err is retried, and after which delay.
+func (c *Client) retryDelay(ctx context.Context, err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.MaxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+
+	delay := c.Backoff << attempt
+	if delay > c.MaxBackoff || delay <= 0 {
+		delay = c.MaxBackoff
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// transport errors are retried, failing to build or authorize the request isn't
+		var urlErr *url.Error
+		return delay, errors.As(err, &urlErr)
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		if apiErr.RetryAfter > c.MaxBackoff {
+			return 0, false
+		}
+		if apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		return delay, true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return delay, true
+	default:
+		return 0, false
+	}
+}
+
+// newError reads the error envelope of res.
+func newError(res *http.Response) *Error {
+	apiErr := &Error{StatusCode: res.StatusCode}
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorSize))
+	if err != nil || json.Unmarshal(body, apiErr) != nil || apiErr.Message == "" {
+		apiErr.Message = http.StatusText(res.StatusCode)
+	}
+
+	return apiErr
+}
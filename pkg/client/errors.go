@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxErrorSize is the size of the error responses read, larger bodies are truncated.
+const maxErrorSize = 64 << 10
+
+// The errors matching the Error of every status code the server responds with, checked with errors.Is.
+var (
+	ErrBadRequest    = errors.New("bad request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrNotFound      = errors.New("not found")
+	ErrNotAcceptable = errors.New("not acceptable")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrServer        = errors.New("server error")
+	ErrBadGateway    = errors.New("bad gateway")
+	ErrUnavailable   = errors.New("service unavailable")
+)
+
+// Error is a response of the server with an error status code, decoded from its error envelope.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"error"`
+	// Suggestions are the species named like the one that wasn't found, if any.
+	Suggestions []PokemonListItem `json:"suggestions,omitempty"`
+	// RetryAfter is the delay before a rate limited request may be sent again.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("pokedex-clone: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap returns the error of the status code, nil for status codes the server doesn't respond with.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusNotAcceptable:
+		return ErrNotAcceptable
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError:
+		return ErrServer
+	case http.StatusBadGateway:
+		return ErrBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+// Pokemon is a species as served by the v2 API.
+type Pokemon struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Habitat     string `json:"habitat"`
+	IsLegendary bool   `json:"isLegendary"`
+	// Types are ordered by slot, they are empty when the server couldn't fetch them.
+	Types []string `json:"types"`
+	// Translation is only set on translated pokemon.
+	Translation *Translation `json:"translation,omitempty"`
+	Links       Links        `json:"links"`
+}
+
+// Translation tells how the description of a translated pokemon was translated.
+type Translation struct {
+	// Type is yoda or shakespeare, empty for descriptions that aren't in english.
+	Type string `json:"type,omitempty"`
+	// Translated is false when the description was served untranslated.
+	Translated bool `json:"translated"`
+}
+
+// Links are the routes serving a pokemon.
+type Links struct {
+	Self       string `json:"self"`
+	Pokemon    string `json:"pokemon"`
+	Translated string `json:"translated"`
+}
+
+// PokemonListItem identifies a species.
+type PokemonListItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// SearchResults are the species whose names match a query.
+type SearchResults struct {
+	Query   string            `json:"query"`
+	Mode    string            `json:"mode"`
+	Results []PokemonListItem `json:"results"`
+}
+
+// DescriptionResults are the descriptions matching a query, best first.
+type DescriptionResults struct {
+	Query   string             `json:"query"`
+	Results []DescriptionMatch `json:"results"`
+}
+
+// DescriptionMatch is a description matching a query.
+type DescriptionMatch struct {
+	ID       int     `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	URL      string  `json:"url"`
+	Score    float64 `json:"score"`
+	Language string  `json:"language"`
+	Version  string  `json:"version"`
+	// Snippet is the description with the matched words wrapped in <em> tags.
+	Snippet string `json:"snippet"`
+}
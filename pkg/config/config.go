@@ -0,0 +1,450 @@
+// Config package provides the service configuration. Values come from defaults, an optional
+// YAML or TOML file, POKEDEX_* environment variables and command line flags, each source
+// overriding the previous one.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/logging"
+	"strings"
+	"time"
+)
+
+// Species sources selectable with upstream.source.
+const (
+	SourceRemote            = "remote"
+	SourceLocal             = "local"
+	SourceLocalWithFallback = "local-with-remote-fallback"
+)
+
+// Storage backends selectable with storage.backend.
+const (
+	BackendMemory = "memory"
+)
+
+// Span exporters selectable with tracing.exporter.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Translators selectable with translator.provider.
+const (
+	TranslatorFunTranslations = "funtranslations"
+	TranslatorNone            = "none"
+)
+
+// ErrInvalid is returned when the configuration fails validation.
+var ErrInvalid = errors.New("invalid configuration")
+
+// Config is the effective service configuration. Keys tagged reload can change while the service
+// runs, see Reload, the others need a restart.
+type Config struct {
+	Server     Server     `config:"server"`
+	Upstream   Upstream   `config:"upstream"`
+	Storage    Storage    `config:"storage"`
+	Cache      Cache      `config:"cache"`
+	Translator Translator `config:"translator"`
+	Limits     Limits     `config:"limits"`
+	Auth       Auth       `config:"auth"`
+	Log        Log        `config:"log"`
+	Admin      Admin      `config:"admin"`
+	Tracing    Tracing    `config:"tracing"`
+	Health     Health     `config:"health"`
+	Warm       Warm       `config:"warm"`
+	API        API        `config:"api"`
+	GRPC       GRPC       `config:"grpc"`
+	GraphQL    GraphQL    `config:"graphql"`
+	Jobs       Jobs       `config:"jobs"`
+	Queue      Queue      `config:"queue"`
+	Webhooks   Webhooks   `config:"webhooks"`
+}
+
+type Server struct {
+	Address           string        `config:"address" usage:"address the HTTP server listens on"`
+	ReadTimeout       time.Duration `config:"read_timeout" usage:"maximum duration for reading a request"`
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" usage:"maximum duration for reading request headers"`
+	WriteTimeout      time.Duration `config:"write_timeout" usage:"maximum duration for writing a response"`
+	ShutdownTimeout   time.Duration `config:"shutdown_timeout" usage:"time in-flight requests get to finish on shutdown"`
+	// TrustedProxies are the proxies whose X-Forwarded-For header gives the client IP, which is the remote
+	// address of the connection otherwise.
+	TrustedProxies []string `config:"trusted_proxies" usage:"comma separated IPs or CIDRs of the proxies in front"`
+}
+
+type Upstream struct {
+	PokeAPIURL      string        `config:"pokeapi_url" reload:"true" usage:"base URL of the pokeapi"`
+	TranslationsURL string        `config:"translations_url" reload:"true" usage:"base URL of the funtranslations API"`
+	Timeout         time.Duration `config:"timeout" usage:"timeout of upstream requests"`
+	Source          string        `config:"source" usage:"species source: remote, local or local-with-remote-fallback"`
+	Dataset         string        `config:"dataset" usage:"dataset written by the import command, used by local sources"`
+	// Conditional is the total size of the pokeapi responses kept to refresh them with conditional requests.
+	Conditional int `config:"conditional_bytes" usage:"pokeapi response bytes kept for conditional requests, 0 disables"`
+	// BreakerThreshold is how many upstream calls in a row must fail to open the circuit breaker of an API.
+	BreakerThreshold int           `config:"breaker_threshold" usage:"failed calls in a row opening a breaker, 0 disables"`
+	BreakerCooldown  time.Duration `config:"breaker_cooldown" usage:"how long an open breaker fails upstream calls"`
+}
+
+type Storage struct {
+	Backend string `config:"backend" usage:"cache storage backend: memory"`
+}
+
+type Cache struct {
+	SpeciesTTL     time.Duration `config:"species_ttl" reload:"true" usage:"species cache lifetime, 0 is forever"`
+	TranslationTTL time.Duration `config:"translation_ttl" reload:"true" usage:"translation cache lifetime, 0 is forever"`
+	IndexRefresh   time.Duration `config:"index_refresh" usage:"how often the species name index is refreshed"`
+	Eviction       time.Duration `config:"eviction_interval" usage:"how often to evict expired values and idle limits"`
+}
+
+type Translator struct {
+	Provider string `config:"provider" usage:"translations provider: funtranslations or none"`
+	// Special is used for the species living in SpecialHabitats, and legendary species when
+	// SpecialLegendary is set, Default for every other species.
+	Special          string   `config:"special" reload:"true" usage:"translation of special species: yoda or shakespeare"`
+	SpecialHabitats  []string `config:"special_habitats" reload:"true" usage:"comma separated habitats of special species"`
+	SpecialLegendary bool     `config:"special_legendary" reload:"true" usage:"whether legendary species are special"`
+	Default          string   `config:"default" reload:"true" usage:"translation of other species: yoda or shakespeare"`
+}
+
+type Limits struct {
+	RequestsPerSecond float64 `config:"requests_per_second" reload:"true" usage:"requests per second, 0 is unlimited"`
+	Burst             int     `config:"burst" reload:"true" usage:"requests accepted at once above the rate"`
+}
+
+// Auth configures API keys. The per-key limits apply to every request with a key, the translated ones
+// additionally to /pokemon/translated, which spends the translation quota.
+type Auth struct {
+	Required          bool     `config:"required" reload:"true" usage:"reject requests without an API key"`
+	Keys              []string `config:"keys" reload:"true" secret:"true" usage:"comma separated name:key API keys"`
+	RequestsPerSecond float64  `config:"requests_per_second" reload:"true" usage:"requests per second of every API key"`
+	Burst             int      `config:"burst" reload:"true" usage:"requests every API key may send at once"`
+	TranslatedRPS     float64  `config:"translated_requests_per_second" reload:"true" usage:"translation rate of a key"`
+	TranslatedBurst   int      `config:"translated_burst" reload:"true" usage:"translations a key may request at once"`
+}
+
+type Log struct {
+	Level  string   `config:"level" reload:"true" usage:"log level: debug, info, warn or error"`
+	Format string   `config:"format" usage:"log line format: text or json"`
+	Redact []string `config:"redact" usage:"comma separated log attributes logged as <redacted>"`
+}
+
+type Admin struct {
+	Token string `config:"token" secret:"true" usage:"bearer token of the /admin endpoints, which are disabled without it"`
+}
+
+type Tracing struct {
+	Exporter    string  `config:"exporter" usage:"span exporter: none, stdout or otlp"`
+	Endpoint    string  `config:"endpoint" usage:"traces URL of the OTLP over HTTP collector"`
+	SampleRatio float64 `config:"sample_ratio" usage:"share of new traces recorded, from 0 to 1"`
+	ServiceName string  `config:"service_name" usage:"service name reported with the spans"`
+}
+
+type Health struct {
+	Timeout      time.Duration `config:"timeout" usage:"time the readiness checks get"`
+	PokeAPIProbe bool          `config:"pokeapi_probe" usage:"check the pokeapi responds for readiness"`
+	ProbeTTL     time.Duration `config:"probe_ttl" usage:"how long the result of the pokeapi probe is reused"`
+	DrainDelay   time.Duration `config:"drain_delay" usage:"time readiness fails for on shutdown before draining"`
+}
+
+type Warm struct {
+	OnStart           bool    `config:"on_start" usage:"warm the cache with every species after starting"`
+	RequestsPerSecond float64 `config:"requests_per_second" usage:"species requests per second of the start-up warm-up"`
+	TranslationBudget int     `config:"translation_budget" usage:"translations the background warm-up may spend"`
+}
+
+// DateLayout is the layout of the dates in the configuration.
+const DateLayout = "2006-01-02"
+
+// API configures the versions of the API, the v1 routes, and the unversioned ones serving v1, announce
+// their deprecation in favor of the v2 routes.
+type API struct {
+	V1Deprecation string `config:"v1_deprecation" usage:"date the v1 routes were deprecated on, as YYYY-MM-DD"`
+	V1Sunset      string `config:"v1_sunset" usage:"date the v1 routes are removed on, as YYYY-MM-DD, empty if undecided"`
+}
+
+// GRPC configures the gRPC server, which serves the pokemon on a port of its own.
+type GRPC struct {
+	Address    string `config:"address" usage:"address the gRPC server listens on, empty disables it"`
+	Reflection bool   `config:"reflection" usage:"serve the gRPC reflection service, for tools such as grpcurl"`
+}
+
+// GraphQL limits the queries of the /graphql endpoint.
+type GraphQL struct {
+	MaxDepth      int `config:"max_depth" usage:"deepest field a GraphQL query may select"`
+	MaxComplexity int `config:"max_complexity" usage:"highest cost of a GraphQL query, every field resolved costs one"`
+}
+
+// Jobs configures the batch translation jobs.
+type Jobs struct {
+	TTL        time.Duration `config:"ttl" usage:"time finished jobs are kept for, 0 keeps them"`
+	MaxRunning int           `config:"max_running" usage:"running jobs a client may have, 0 is unlimited"`
+}
+
+// Queue configures the queue of the translations requested with the respond-async preference.
+type Queue struct {
+	Workers     int           `config:"workers" usage:"translations attempted at once, 0 disables the queue"`
+	MaxAttempts int           `config:"max_attempts" usage:"attempts of a translation before it fails"`
+	Backoff     time.Duration `config:"backoff" usage:"delay before retrying a translation, doubling every retry"`
+	MaxBackoff  time.Duration `config:"max_backoff" usage:"longest delay before retrying a translation"`
+	TTL         time.Duration `config:"ttl" usage:"time finished translation tasks are kept for, 0 keeps them"`
+}
+
+// Webhooks configures the delivery of the events to the webhook subscriptions.
+type Webhooks struct {
+	Workers     int           `config:"workers" usage:"deliveries attempted at once, 0 disables webhooks"`
+	Timeout     time.Duration `config:"timeout" usage:"maximum duration of a delivery"`
+	MaxAttempts int           `config:"max_attempts" usage:"attempts of a delivery before it is dead-lettered"`
+	Backoff     time.Duration `config:"backoff" usage:"delay before retrying a delivery, doubling every retry"`
+	MaxBackoff  time.Duration `config:"max_backoff" usage:"longest delay before retrying a delivery"`
+	TTL         time.Duration `config:"ttl" usage:"time dead letters and finished deliveries are kept for, 0 keeps them"`
+}
+
+// V1Dates returns the deprecation and sunset dates of the v1 routes, the sunset is zero when undecided.
+func (a API) V1Dates() (deprecation, sunset time.Time, err error) {
+	if deprecation, err = time.Parse(DateLayout, a.V1Deprecation); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if a.V1Sunset != "" {
+		if sunset, err = time.Parse(DateLayout, a.V1Sunset); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	return deprecation, sunset, nil
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address:           ":5000",
+			ReadTimeout:       3 * time.Second,
+			ReadHeaderTimeout: 3 * time.Second,
+			WriteTimeout:      3 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
+		},
+		Upstream: Upstream{
+			PokeAPIURL:       "https://pokeapi.co/api/v2/",
+			TranslationsURL:  "https://api.funtranslations.com/translate/",
+			Timeout:          3 * time.Second,
+			Source:           SourceRemote,
+			Dataset:          "pokedex-dataset.json",
+			Conditional:      8 << 20,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Storage: Storage{
+			Backend: BackendMemory,
+		},
+		Cache: Cache{
+			SpeciesTTL:   24 * time.Hour,
+			IndexRefresh: 24 * time.Hour,
+			Eviction:     time.Minute,
+		},
+		Translator: Translator{
+			Provider:         TranslatorFunTranslations,
+			Special:          "yoda",
+			SpecialHabitats:  []string{"cave"},
+			SpecialLegendary: true,
+			Default:          "shakespeare",
+		},
+		Limits: Limits{
+			Burst: 20,
+		},
+		Auth: Auth{
+			RequestsPerSecond: 5,
+			Burst:             10,
+			TranslatedRPS:     0.2,
+			TranslatedBurst:   5,
+		},
+		Log: Log{
+			Level:  logging.Info.String(),
+			Format: logging.FormatText,
+			Redact: []string{"authorization", "password", "token", "api_key"},
+		},
+		Tracing: Tracing{
+			Exporter:    ExporterNone,
+			Endpoint:    "http://localhost:4318/v1/traces",
+			SampleRatio: 1,
+			ServiceName: "pokedex-clone",
+		},
+		Health: Health{
+			Timeout:    2 * time.Second,
+			ProbeTTL:   30 * time.Second,
+			DrainDelay: 5 * time.Second,
+		},
+		Warm: Warm{
+			RequestsPerSecond: 5,
+		},
+		API: API{
+			V1Deprecation: "2026-10-19",
+			V1Sunset:      "2027-04-19",
+		},
+		GRPC: GRPC{
+			Address:    ":5001",
+			Reflection: true,
+		},
+		GraphQL: GraphQL{
+			MaxDepth:      15,
+			MaxComplexity: 1000,
+		},
+		Jobs: Jobs{
+			TTL:        24 * time.Hour,
+			MaxRunning: 5,
+		},
+		Queue: Queue{
+			Workers:     2,
+			MaxAttempts: 5,
+			Backoff:     time.Second,
+			MaxBackoff:  5 * time.Minute,
+			TTL:         time.Hour,
+		},
+		Webhooks: Webhooks{
+			Workers:     2,
+			Timeout:     5 * time.Second,
+			MaxAttempts: 8,
+			Backoff:     time.Second,
+			MaxBackoff:  10 * time.Minute,
+			TTL:         7 * 24 * time.Hour,
+		},
+	}
+}
+
+// Validate reports every invalid value at once. Upstream URLs get a trailing slash when they
+// lack one, since API paths are appended to them.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Address != "", "server.address must not be empty")
+	check(c.GRPC.Address == "" || c.GRPC.Address != c.Server.Address, "grpc.address must differ from server.address")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "server.trusted_proxies must be IPs or CIDRs, got %q", proxy)
+	}
+
+	for _, u := range []struct {
+		key   string
+		value *string
+	}{
+		{"upstream.pokeapi_url", &c.Upstream.PokeAPIURL},
+		{"upstream.translations_url", &c.Upstream.TranslationsURL},
+	} {
+		parsed, err := url.Parse(*u.value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s must be an absolute http(s) URL, got %q", u.key, *u.value))
+			continue
+		}
+		if !strings.HasSuffix(*u.value, "/") {
+			*u.value += "/"
+		}
+	}
+
+	check(c.Upstream.Timeout > 0, "upstream.timeout must be positive")
+	check(oneOf(c.Upstream.Source, SourceRemote, SourceLocal, SourceLocalWithFallback),
+		"upstream.source must be one of remote, local or local-with-remote-fallback, got %q", c.Upstream.Source)
+	check(c.Upstream.Source == SourceRemote || c.Upstream.Dataset != "",
+		"upstream.dataset is required by the %s source", c.Upstream.Source)
+	check(c.Upstream.Conditional >= 0, "upstream.conditional_bytes must not be negative")
+	check(c.Upstream.BreakerThreshold >= 0, "upstream.breaker_threshold must not be negative")
+	check(c.Upstream.BreakerThreshold == 0 || c.Upstream.BreakerCooldown > 0,
+		"upstream.breaker_cooldown must be positive when the breaker is enabled")
+
+	check(oneOf(c.Storage.Backend, BackendMemory), "storage.backend must be memory, got %q", c.Storage.Backend)
+
+	check(c.Cache.SpeciesTTL >= 0, "cache.species_ttl must not be negative")
+	check(c.Cache.TranslationTTL >= 0, "cache.translation_ttl must not be negative")
+	check(c.Cache.IndexRefresh > 0, "cache.index_refresh must be positive")
+	check(c.Cache.Eviction > 0, "cache.eviction_interval must be positive")
+
+	check(oneOf(c.Translator.Provider, TranslatorFunTranslations, TranslatorNone),
+		"translator.provider must be funtranslations or none, got %q", c.Translator.Provider)
+	_, ok := api.TranslationTypeByName(c.Translator.Special)
+	check(ok, "translator.special must be yoda or shakespeare, got %q", c.Translator.Special)
+	_, ok = api.TranslationTypeByName(c.Translator.Default)
+	check(ok, "translator.default must be yoda or shakespeare, got %q", c.Translator.Default)
+
+	check(c.Limits.RequestsPerSecond >= 0, "limits.requests_per_second must not be negative")
+	check(c.Limits.RequestsPerSecond == 0 || c.Limits.Burst > 0,
+		"limits.burst must be positive when limits.requests_per_second is set")
+
+	check(c.Auth.RequestsPerSecond >= 0, "auth.requests_per_second must not be negative")
+	check(c.Auth.RequestsPerSecond == 0 || c.Auth.Burst > 0,
+		"auth.burst must be positive when auth.requests_per_second is set")
+	check(c.Auth.TranslatedRPS >= 0, "auth.translated_requests_per_second must not be negative")
+	check(c.Auth.TranslatedRPS == 0 || c.Auth.TranslatedBurst > 0,
+		"auth.translated_burst must be positive when auth.translated_requests_per_second is set")
+	for _, pair := range c.Auth.Keys {
+		name, key, _ := strings.Cut(pair, ":")
+		check(name != "" && key != "", "auth.keys must be name:key pairs")
+	}
+
+	_, err := logging.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	check(oneOf(c.Log.Format, logging.FormatText, logging.FormatJSON),
+		"log.format must be text or json, got %q", c.Log.Format)
+
+	check(oneOf(c.Tracing.Exporter, ExporterNone, ExporterStdout, ExporterOTLP),
+		"tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	endpoint, endpointErr := url.Parse(c.Tracing.Endpoint)
+	check(c.Tracing.Exporter != ExporterOTLP || (endpointErr == nil && endpoint.Host != ""),
+		"tracing.endpoint must be an absolute URL, got %q", c.Tracing.Endpoint)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	check(c.Tracing.ServiceName != "", "tracing.service_name must not be empty")
+
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+	check(c.Health.ProbeTTL >= 0, "health.probe_ttl must not be negative")
+	check(c.Health.DrainDelay >= 0, "health.drain_delay must not be negative")
+
+	check(c.Warm.RequestsPerSecond >= 0, "warm.requests_per_second must not be negative")
+	check(c.Warm.TranslationBudget >= 0, "warm.translation_budget must not be negative")
+
+	deprecation, sunset, datesErr := c.API.V1Dates()
+	check(datesErr == nil, "api.v1_deprecation and api.v1_sunset must be YYYY-MM-DD dates: %v", datesErr)
+	check(sunset.IsZero() || sunset.After(deprecation), "api.v1_sunset must be after api.v1_deprecation")
+
+	check(c.GraphQL.MaxDepth > 0, "graphql.max_depth must be positive")
+	check(c.GraphQL.MaxComplexity > 0, "graphql.max_complexity must be positive")
+	check(c.Jobs.TTL >= 0, "jobs.ttl must not be negative")
+	check(c.Jobs.MaxRunning >= 0, "jobs.max_running must not be negative")
+	check(c.Queue.Workers >= 0, "queue.workers must not be negative")
+	check(c.Queue.MaxAttempts > 0, "queue.max_attempts must be positive")
+	check(c.Queue.Backoff > 0, "queue.backoff must be positive")
+	check(c.Queue.MaxBackoff >= c.Queue.Backoff, "queue.max_backoff must not be shorter than queue.backoff")
+	check(c.Queue.TTL >= 0, "queue.ttl must not be negative")
+	check(c.Webhooks.Workers >= 0, "webhooks.workers must not be negative")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.Backoff > 0, "webhooks.backoff must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.Backoff,
+		"webhooks.max_backoff must not be shorter than webhooks.backoff")
+	check(c.Webhooks.TTL >= 0, "webhooks.ttl must not be negative")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
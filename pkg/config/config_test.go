@@ -0,0 +1,219 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"pokedex-clone/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func envMap(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "pokedex.yaml", `
+server:
+  address: ":6000"
+  shutdown_timeout: 10s
+cache:
+  species_ttl: 1h
+translator:
+  provider: none
+`)
+	tomlFile := writeFile(t, "pokedex.toml", `
+[server]
+address = ":6000"
+shutdown_timeout = "10s"
+
+[cache]
+species_ttl = "1h"
+
+[translator]
+provider = "none"
+`)
+
+	tests := map[string]struct {
+		args        []string
+		env         map[string]string
+		wantAddress string
+		wantTTL     time.Duration
+	}{
+		"defaults": {
+			wantAddress: ":5000",
+			wantTTL:     24 * time.Hour,
+		},
+		"yaml file overrides defaults": {
+			args:        []string{"-config", yamlFile},
+			wantAddress: ":6000",
+			wantTTL:     time.Hour,
+		},
+		"toml file named by the environment": {
+			env:         map[string]string{"POKEDEX_CONFIG": tomlFile},
+			wantAddress: ":6000",
+			wantTTL:     time.Hour,
+		},
+		"environment overrides the file": {
+			args:        []string{"-config", yamlFile},
+			env:         map[string]string{"POKEDEX_SERVER_ADDRESS": ":7000"},
+			wantAddress: ":7000",
+			wantTTL:     time.Hour,
+		},
+		"flags override the environment": {
+			args:        []string{"-config", yamlFile, "-server.address", ":8000", "-cache.species_ttl=5m"},
+			env:         map[string]string{"POKEDEX_SERVER_ADDRESS": ":7000"},
+			wantAddress: ":8000",
+			wantTTL:     5 * time.Minute,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.Load("test", tc.args, envMap(tc.env))
+			assert.Nil(t, err)
+
+			assert.Equal(t, tc.wantAddress, cfg.Server.Address)
+			assert.Equal(t, tc.wantTTL, cfg.Cache.SpeciesTTL)
+			if len(tc.args) > 0 || len(tc.env) > 0 {
+				assert.Equal(t, 10*time.Second, cfg.Server.ShutdownTimeout)
+				assert.Equal(t, config.TranslatorNone, cfg.Translator.Provider)
+			}
+		})
+	}
+}
+
+func TestLoadFails(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		env  map[string]string
+		file string
+	}{
+		"unknown source": {
+			args: []string{"-upstream.source", "cloud"},
+		},
+		"unknown storage backend": {
+			env: map[string]string{"POKEDEX_STORAGE_BACKEND": "redis"},
+		},
+		"relative upstream url": {
+			args: []string{"-upstream.pokeapi_url", "pokeapi.co"},
+		},
+		"malformed duration": {
+			args: []string{"-server.read_timeout", "soon"},
+		},
+		"negative ttl": {
+			args: []string{"-cache.translation_ttl", "-1h"},
+		},
+		"breaker without cooldown": {
+			args: []string{"-upstream.breaker_cooldown", "0s"},
+		},
+		"webhook backoff above its maximum": {
+			args: []string{"-webhooks.backoff", "1h"},
+		},
+		"unknown key in file": {
+			file: "server:\n  port: 5000\n",
+		},
+		"unknown flag": {
+			args: []string{"-port", "5000"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			args := tc.args
+			if tc.file != "" {
+				args = append(args, "-config", writeFile(t, "pokedex.yml", tc.file))
+			}
+
+			_, err := config.Load("test", args, envMap(tc.env))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestValidateAddsTrailingSlash(t *testing.T) {
+	cfg := config.Default()
+	cfg.Upstream.PokeAPIURL = "http://localhost:8000/api/v2"
+
+	assert.Nil(t, cfg.Validate())
+	assert.Equal(t, "http://localhost:8000/api/v2/", cfg.Upstream.PokeAPIURL)
+}
+
+func TestWriteYAMLRoundTrips(t *testing.T) {
+	cfg, err := config.Load("test", []string{"-warm.on_start", "-warm.requests_per_second", "2.5"}, envMap(nil))
+	assert.Nil(t, err)
+
+	var b bytes.Buffer
+	assert.Nil(t, cfg.WriteYAML(&b))
+
+	reloaded, err := config.Load("test", []string{"-config", writeFile(t, "printed.yaml", b.String())}, envMap(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, cfg, reloaded)
+}
+
+func TestWriteYAMLRedactsSecrets(t *testing.T) {
+	cfg, err := config.Load("test", []string{"-admin.token", "s3cret", "-auth.keys", "web:k3y,cli:k4y"}, envMap(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"web:k3y", "cli:k4y"}, cfg.Auth.Keys)
+
+	var b bytes.Buffer
+	assert.Nil(t, cfg.WriteYAML(&b))
+	assert.Contains(t, b.String(), "  token: <redacted>\n")
+	assert.Contains(t, b.String(), "  keys: <redacted>\n")
+	for _, secret := range []string{"s3cret", "k3y", "k4y"} {
+		assert.NotContains(t, b.String(), secret)
+	}
+}
+
+func TestLoadLists(t *testing.T) {
+	yamlFile := writeFile(t, "pokedex.yaml", "translator:\n  special_habitats: [cave, rare]\n")
+
+	cfg, err := config.Load("test", []string{"-config", yamlFile}, envMap(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cave", "rare"}, cfg.Translator.SpecialHabitats)
+
+	cfg, err = config.Load("test", nil, envMap(map[string]string{"POKEDEX_TRANSLATOR_SPECIAL_HABITATS": "sea, ,urban"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sea", "urban"}, cfg.Translator.SpecialHabitats)
+}
+
+func TestReloadAppliesReloadableKeys(t *testing.T) {
+	current := config.Default()
+	current.Admin.Token = "old-secret"
+
+	next := config.Default()
+	next.Server.Address = ":6000"
+	next.Cache.SpeciesTTL = time.Hour
+	next.Log.Level = "debug"
+	next.Admin.Token = "new-secret"
+
+	reloaded, changes := current.Reload(next)
+
+	assert.Equal(t, ":5000", reloaded.Server.Address)
+	assert.Equal(t, time.Hour, reloaded.Cache.SpeciesTTL)
+	assert.Equal(t, "debug", reloaded.Log.Level)
+	assert.Equal(t, "old-secret", reloaded.Admin.Token)
+	assert.Equal(t, 24*time.Hour, current.Cache.SpeciesTTL)
+
+	assert.Equal(t, []config.Change{
+		{Key: "server.address", Old: ":5000", New: ":6000"},
+		{Key: "cache.species_ttl", Old: "24h0m0s", New: "1h0m0s", Reloadable: true},
+		{Key: "log.level", Old: "info", New: "debug", Reloadable: true},
+		{Key: "admin.token", Old: "<redacted>", New: "<redacted>"},
+	}, changes)
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPrefix prefixes the environment variable of every key, e.g. POKEDEX_SERVER_ADDRESS.
+	EnvPrefix = "POKEDEX_"
+	// FileFlag and FileEnv name the configuration file.
+	FileFlag = "config"
+	FileEnv  = EnvPrefix + "CONFIG"
+)
+
+// Load builds the configuration from, in increasing precedence, the defaults, the file named by
+// the -config flag or POKEDEX_CONFIG, the environment and the flags in args. Every key can be set
+// as a flag named after it, e.g. -server.address, or as an environment variable, e.g.
+// POKEDEX_SERVER_ADDRESS. lookupEnv is usually os.LookupEnv.
+func Load(name string, args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := flags.String(FileFlag, "", "YAML or TOML configuration file, also read from "+FileEnv)
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		flags.Var(flagValue{field: f, values: flagValues}, f.key, f.usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	if *path == "" {
+		*path, _ = lookupEnv(FileEnv)
+	}
+	if *path != "" {
+		fileValues, err := readFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		for key := range fileValues {
+			if _, known := fields[key]; !known {
+				return nil, fmt.Errorf("%s: unknown key %s", *path, key)
+			}
+		}
+		if err = apply(fields, fileValues, *path); err != nil {
+			return nil, err
+		}
+	}
+
+	envValues := make(map[string]string)
+	for key, f := range fields {
+		if v, ok := lookupEnv(f.env()); ok {
+			envValues[key] = v
+		}
+	}
+	if err := apply(fields, envValues, "environment"); err != nil {
+		return nil, err
+	}
+
+	if err := apply(fields, flagValues, "flags"); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// WriteYAML writes the configuration in the format read by Load.
+func (c *Config) WriteYAML(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := make(map[string]*yaml.Node)
+
+	for _, f := range c.orderedFields() {
+		section, key, _ := strings.Cut(f.key, ".")
+		node, ok := sections[section]
+		if !ok {
+			node = &yaml.Node{Kind: yaml.MappingNode}
+			sections[section] = node
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: section}, node)
+		}
+
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: f.display()}
+		switch f.value.Kind() { //nolint:exhaustive // only the kinds config fields use
+		case reflect.String:
+			// strings are quoted when they would read as something else
+			value.Tag = "!!str"
+		case reflect.Slice:
+			if f.secret && f.value.Len() > 0 {
+				break
+			}
+			value = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			for i := 0; i < f.value.Len(); i++ {
+				value.Content = append(value.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.value.Index(i).String()})
+			}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// field is a configurable value, key is its dotted path such as server.address.
+type field struct {
+	key    string
+	usage  string
+	reload bool
+	secret bool
+	value  reflect.Value
+}
+
+func (f field) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+}
+
+func (f field) String() string {
+	if !f.value.IsValid() {
+		return ""
+	}
+	switch v := f.value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// display is the value shown to people, secrets are redacted.
+func (f field) display() string {
+	if f.secret && f.String() != "" {
+		return "<redacted>"
+	}
+
+	return f.String()
+}
+
+func (f field) set(raw string) error {
+	if f.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.value.Kind() { //nolint:exhaustive // only the kinds config fields use
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(i))
+	case reflect.Float64:
+		x, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(x)
+	case reflect.Slice:
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		f.value.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported kind %s", f.value.Kind())
+	}
+
+	return nil
+}
+
+// flagValue records a flag for its field, so flags are applied after the file and environment.
+type flagValue struct {
+	field
+	values map[string]string
+}
+
+func (v flagValue) Set(raw string) error {
+	v.values[v.key] = raw
+	return nil
+}
+
+// IsBoolFlag lets boolean keys be set with a bare flag such as -warm.on_start.
+func (v flagValue) IsBoolFlag() bool {
+	return v.value.Kind() == reflect.Bool
+}
+
+// orderedFields returns the fields of c in declaration order.
+func (c *Config) orderedFields() []field {
+	var fields []field
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionKey := sections.Type().Field(i).Tag.Get("config")
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j)
+			fields = append(fields, field{
+				key:    sectionKey + "." + tag.Tag.Get("config"),
+				usage:  tag.Tag.Get("usage"),
+				reload: tag.Tag.Get("reload") == "true",
+				secret: tag.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+
+	return fields
+}
+
+func (c *Config) fields() map[string]field {
+	fields := make(map[string]field)
+	for _, f := range c.orderedFields() {
+		fields[f.key] = f
+	}
+
+	return fields
+}
+
+// apply sets the fields named in values, source names where the values come from in errors.
+func apply(fields map[string]field, values map[string]string, source string) error {
+	for key, raw := range values {
+		if err := fields[key].set(raw); err != nil {
+			return fmt.Errorf("%s: invalid %s %q: %w", source, key, raw, err)
+		}
+	}
+
+	return nil
+}
+
+// readFile reads a YAML or TOML file, picked by extension, into dotted keys.
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &doc)
+	case ".toml":
+		err = toml.Unmarshal(b, &doc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported configuration format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	for section, v := range doc {
+		keys, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: %s must be a table of keys", path, section)
+		}
+		for key, value := range keys {
+			if list, isList := value.([]interface{}); isList {
+				items := make([]string, 0, len(list))
+				for _, item := range list {
+					items = append(items, fmt.Sprint(item))
+				}
+				value = strings.Join(items, ",")
+			}
+			values[section+"."+key] = fmt.Sprint(value)
+		}
+	}
+
+	return values, nil
+}
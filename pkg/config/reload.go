@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Change is a key whose value differs between two configurations.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Reloadable changes are applied by Reload, the others wait for a restart.
+	Reloadable bool `json:"reloadable"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff lists the keys whose value differs between c and next, in declaration order.
+func (c *Config) Diff(next *Config) []Change {
+	nextFields := next.fields()
+
+	var changes []Change
+	for _, f := range c.orderedFields() {
+		n := nextFields[f.key]
+		if reflect.DeepEqual(f.value.Interface(), n.value.Interface()) {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Key:        f.key,
+			Old:        f.display(),
+			New:        n.display(),
+			Reloadable: f.reload,
+		})
+	}
+
+	return changes
+}
+
+// Reload returns a copy of c with the reloadable keys of next, which must be valid, and the
+// changes between c and next. Changes to other keys are reported but not applied.
+func (c *Config) Reload(next *Config) (*Config, []Change) {
+	reloaded := c.clone()
+	fields := reloaded.fields()
+	nextFields := next.fields()
+
+	changes := c.Diff(next)
+	for _, change := range changes {
+		if change.Reloadable {
+			fields[change.Key].value.Set(nextFields[change.Key].value)
+		}
+	}
+
+	return reloaded, changes
+}
+
+func (c *Config) clone() *Config {
+	clone := *c
+	clone.Translator.SpecialHabitats = append([]string(nil), c.Translator.SpecialHabitats...)
+	clone.Auth.Keys = append([]string(nil), c.Auth.Keys...)
+	clone.Log.Redact = append([]string(nil), c.Log.Redact...)
+
+	return &clone
+}
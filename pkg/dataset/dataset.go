@@ -0,0 +1,449 @@
+// Dataset package imports the CSV dump pokeapi publishes of its whole database into a local
+// store, so the service can run without any network access.
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSV files read by Import, as found in the data/v2/csv directory of the pokeapi repository.
+const (
+	SpeciesFile     = "pokemon_species.csv"
+	FlavorTextFile  = "pokemon_species_flavor_text.csv"
+	HabitatsFile    = "pokemon_habitats.csv"
+	LanguagesFile   = "languages.csv"
+	VersionsFile    = "versions.csv"
+	GenerationsFile = "generations.csv"
+	RegionsFile     = "regions.csv"
+	TypesFile       = "types.csv"
+	PokemonTypes    = "pokemon_types.csv"
+)
+
+// ErrMissingColumn is returned when a CSV file lacks a column the importer relies on.
+var ErrMissingColumn = errors.New("missing column")
+
+// Dataset is the imported subset of the pokeapi database.
+type Dataset struct {
+	Species     []Species    `json:"species"`
+	Habitats    []Named      `json:"habitats"`
+	Generations []Generation `json:"generations"`
+
+	byName map[string]int
+	byID   map[int]int
+}
+
+// Named is a resource with an id and an identifier, e.g. a habitat or a generation.
+type Named struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Generation is a generation along with the main region of the species it introduced, which is zero when unknown.
+type Generation struct {
+	Named
+	MainRegion Named `json:"main_region"`
+}
+
+type Species struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Generation  Named        `json:"generation"`
+	Habitat     Named        `json:"habitat"`
+	IsLegendary bool         `json:"is_legendary"`
+	FlavorTexts []FlavorText `json:"flavor_texts"`
+	// Types are those of the default pokemon of the species, by slot.
+	Types []string `json:"types,omitempty"`
+	// EvolvesFromID is the species this one evolves from, zero for the first species of a chain.
+	EvolvesFromID int `json:"evolves_from_species_id,omitempty"`
+	// EvolutionChainID is the evolution chain of the species, zero when it was imported without one.
+	EvolutionChainID int `json:"evolution_chain_id,omitempty"`
+}
+
+type FlavorText struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Version  string `json:"version"`
+}
+
+// Import reads the pokeapi CSV files from dir. generations.csv is optional, generation names
+// are derived from their ids when it is missing. regions.csv is optional too, generations are
+// imported without their main region when either is missing, and so are types.csv and
+// pokemon_types.csv, species are imported without types when either is missing.
+func Import(dir string) (*Dataset, error) {
+	languages, err := readNames(filepath.Join(dir, LanguagesFile))
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := readNames(filepath.Join(dir, VersionsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	habitats, err := readNames(filepath.Join(dir, HabitatsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	generations, mainRegions, err := readGenerations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dataset{}
+	speciesIdx := make(map[int]int)
+
+	err = readCSV(filepath.Join(dir, SpeciesFile), func(row map[string]string) error {
+		id, convErr := strconv.Atoi(row["id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		generationID, convErr := atoiOrZero(row["generation_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		habitatID, convErr := atoiOrZero(row["habitat_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		// the evolution columns are optional, missing ones read as empty
+		evolvesFromID, convErr := atoiOrZero(row["evolves_from_species_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		evolutionChainID, convErr := atoiOrZero(row["evolution_chain_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		generationName, ok := generations[generationID]
+		if !ok && generationID > 0 {
+			generationName = "generation-" + strings.ToLower(roman(generationID))
+			generations[generationID] = generationName
+		}
+
+		speciesIdx[id] = len(d.Species)
+		d.Species = append(d.Species, Species{
+			ID:               id,
+			Name:             row["identifier"],
+			Generation:       Named{ID: generationID, Name: generationName},
+			Habitat:          Named{ID: habitatID, Name: habitats[habitatID]},
+			IsLegendary:      row["is_legendary"] == "1",
+			EvolvesFromID:    evolvesFromID,
+			EvolutionChainID: evolutionChainID,
+		})
+
+		return nil
+	}, "id", "identifier", "generation_id", "habitat_id", "is_legendary")
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCSV(filepath.Join(dir, FlavorTextFile), func(row map[string]string) error {
+		speciesID, convErr := strconv.Atoi(row["species_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		versionID, convErr := strconv.Atoi(row["version_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		languageID, convErr := strconv.Atoi(row["language_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		idx, ok := speciesIdx[speciesID]
+		if !ok {
+			return fmt.Errorf("flavor text for unknown species %d", speciesID)
+		}
+
+		d.Species[idx].FlavorTexts = append(d.Species[idx].FlavorTexts, FlavorText{
+			Text:     row["flavor_text"],
+			Language: languages[languageID],
+			Version:  versions[versionID],
+		})
+
+		return nil
+	}, "species_id", "version_id", "language_id", "flavor_text")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = importTypes(dir, d, speciesIdx); err != nil {
+		return nil, err
+	}
+
+	d.Habitats = sortedNames(habitats)
+	for _, generation := range sortedNames(generations) {
+		d.Generations = append(d.Generations, Generation{Named: generation, MainRegion: mainRegions[generation.ID]})
+	}
+	sort.Slice(d.Species, func(a, b int) bool { return d.Species[a].ID < d.Species[b].ID })
+	d.buildIndex()
+
+	return d, nil
+}
+
+// importTypes adds the types of the default pokemon of every species, which shares its id.
+func importTypes(dir string, d *Dataset, speciesIdx map[int]int) error {
+	types, err := readNames(filepath.Join(dir, TypesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	slots := make(map[int]map[int]string)
+	err = readCSV(filepath.Join(dir, PokemonTypes), func(row map[string]string) error {
+		pokemonID, convErr := strconv.Atoi(row["pokemon_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		typeID, convErr := strconv.Atoi(row["type_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		slot, convErr := strconv.Atoi(row["slot"])
+		if convErr != nil {
+			return convErr
+		}
+
+		// the other forms and megas have ids past the species ones
+		if _, ok := speciesIdx[pokemonID]; ok {
+			if slots[pokemonID] == nil {
+				slots[pokemonID] = make(map[int]string)
+			}
+			slots[pokemonID][slot] = types[typeID]
+		}
+
+		return nil
+	}, "pokemon_id", "type_id", "slot")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for id, bySlot := range slots {
+		for _, n := range sortedNames(bySlot) {
+			d.Species[speciesIdx[id]].Types = append(d.Species[speciesIdx[id]].Types, n.Name)
+		}
+	}
+
+	return nil
+}
+
+// ReadFile loads a dataset previously written by WriteFile.
+func ReadFile(path string) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var d Dataset
+	if err = json.NewDecoder(f).Decode(&d); err != nil {
+		return nil, fmt.Errorf("decoding dataset %s: %w", path, err)
+	}
+	d.buildIndex()
+
+	return &d, nil
+}
+
+// WriteFile stores the dataset as JSON at path.
+func (d *Dataset) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(f).Encode(d); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Lookup returns the species with the given identifier or national dex number.
+func (d *Dataset) Lookup(nameOrID string) (*Species, bool) {
+	idx, ok := d.byName[nameOrID]
+	if !ok {
+		id, err := strconv.Atoi(nameOrID)
+		if err != nil {
+			return nil, false
+		}
+		if idx, ok = d.byID[id]; !ok {
+			return nil, false
+		}
+	}
+
+	return &d.Species[idx], true
+}
+
+func (d *Dataset) buildIndex() {
+	d.byName = make(map[string]int, len(d.Species))
+	d.byID = make(map[int]int, len(d.Species))
+	for i, s := range d.Species {
+		d.byName[s.Name] = i
+		d.byID[s.ID] = i
+	}
+}
+
+// readNames reads an id to identifier mapping from a CSV file with id and identifier columns.
+// readGenerations returns the names of the generations of generations.csv, and the names of their main regions, by
+// generation id. Both are empty when generations.csv is missing, the regions when regions.csv is.
+func readGenerations(dir string) (generations map[int]string, mainRegions map[int]Named, err error) {
+	regions, err := readNames(filepath.Join(dir, RegionsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		regions = make(map[int]string)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	generations, mainRegions = make(map[int]string), make(map[int]Named)
+	err = readCSV(filepath.Join(dir, GenerationsFile), func(row map[string]string) error {
+		id, convErr := strconv.Atoi(row["id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		regionID, convErr := atoiOrZero(row["main_region_id"])
+		if convErr != nil {
+			return convErr
+		}
+
+		generations[id] = row["identifier"]
+		if region, ok := regions[regionID]; ok {
+			mainRegions[id] = Named{ID: regionID, Name: region}
+		}
+
+		return nil
+	}, "id", "identifier")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	return generations, mainRegions, nil
+}
+
+func readNames(path string) (map[int]string, error) {
+	names := make(map[int]string)
+	err := readCSV(path, func(row map[string]string) error {
+		id, err := strconv.Atoi(row["id"])
+		if err != nil {
+			return err
+		}
+		names[id] = row["identifier"]
+
+		return nil
+	}, "id", "identifier")
+
+	return names, err
+}
+
+// readCSV calls fn for every row of the CSV file at path, keyed by column name.
+func readCSV(path string, fn func(row map[string]string) error, required ...string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("reading header of %s: %w", path, err)
+	}
+
+	for _, col := range required {
+		if !contains(header, col) {
+			return fmt.Errorf("%s: %w %s", path, ErrMissingColumn, col)
+		}
+	}
+
+	row := make(map[string]string, len(header))
+	for line := 2; ; line++ {
+		record, readErr := r.Read()
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("%s: %w", path, readErr)
+		}
+
+		for i, col := range header {
+			row[col] = record[i]
+		}
+
+		if fnErr := fn(row); fnErr != nil {
+			return fmt.Errorf("%s line %d: %w", path, line, fnErr)
+		}
+	}
+}
+
+func atoiOrZero(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(s)
+}
+
+func sortedNames(names map[int]string) []Named {
+	sorted := make([]Named, 0, len(names))
+	for id, name := range names {
+		sorted = append(sorted, Named{ID: id, Name: name})
+	}
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+
+	return sorted
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roman returns n in roman numerals, pokeapi names generations "generation-i", "generation-ii" etc.
+func roman(n int) string {
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+		{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+
+	var b strings.Builder
+	for _, num := range numerals {
+		for n >= num.value {
+			b.WriteString(num.symbol)
+			n -= num.value
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package dataset_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"pokedex-clone/pkg/dataset"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	d, err := dataset.Import("testdata")
+	assert.Nil(t, err)
+
+	assert.Len(t, d.Species, 4)
+	// generation-vi isn't in generations.csv, its name is derived from its id and its region unknown
+	assert.Equal(t, []dataset.Generation{
+		{Named: dataset.Named{ID: 1, Name: "generation-i"}, MainRegion: dataset.Named{ID: 1, Name: "kanto"}},
+		{Named: dataset.Named{ID: 6, Name: "generation-vi"}},
+	}, d.Generations)
+	assert.Len(t, d.Habitats, 5)
+
+	pikachu, ok := d.Lookup("25")
+	assert.True(t, ok)
+	assert.Equal(t, "pikachu", pikachu.Name)
+	assert.Equal(t, "forest", pikachu.Habitat.Name)
+	assert.Equal(t, 172, pikachu.EvolvesFromID)
+	assert.Equal(t, 10, pikachu.EvolutionChainID)
+	assert.Equal(t, []dataset.FlavorText{
+		{
+			Text:     "When several of\nthese POKéMON\ngather, their\nelectricity could\nbuild and cause\nlightning storms.",
+			Language: "en",
+			Version:  "red",
+		},
+		{
+			Text:     "Lorsque plusieurs de ces Pokémon se réunissent, leur électricité peut provoquer des orages.",
+			Language: "fr",
+			Version:  "red",
+		},
+	}, pikachu.FlavorTexts)
+
+	mewtwo, ok := d.Lookup("mewtwo")
+	assert.True(t, ok)
+	assert.True(t, mewtwo.IsLegendary)
+	assert.Equal(t, []string{"psychic"}, mewtwo.Types)
+
+	bulbasaur, ok := d.Lookup("bulbasaur")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"grass", "poison"}, bulbasaur.Types, "types are ordered by slot")
+	assert.Zero(t, bulbasaur.EvolvesFromID, "the first species of a chain evolves from none")
+
+	flabebe, ok := d.Lookup("flabebe")
+	assert.True(t, ok)
+	assert.Equal(t, dataset.Named{}, flabebe.Habitat)
+
+	_, ok = d.Lookup("missingno")
+	assert.False(t, ok)
+}
+
+func TestWriteFileRoundTrip(t *testing.T) {
+	d, err := dataset.Import("testdata")
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "dataset.json")
+	assert.Nil(t, d.WriteFile(path))
+
+	loaded, err := dataset.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, d.Species, loaded.Species)
+
+	bulbasaur, ok := loaded.Lookup("1")
+	assert.True(t, ok)
+	assert.Equal(t, "bulbasaur", bulbasaur.Name)
+}
+
+func TestImportFailsOnMissingColumn(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{dataset.LanguagesFile, dataset.VersionsFile, dataset.HabitatsFile} {
+		b, err := os.ReadFile(filepath.Join("testdata", name))
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), b, 0o600))
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, dataset.SpeciesFile), []byte("id,identifier\n1,bulbasaur\n"), 0o600))
+
+	_, err := dataset.Import(dir)
+	assert.True(t, errors.Is(err, dataset.ErrMissingColumn))
+}
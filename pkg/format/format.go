@@ -0,0 +1,214 @@
+// Format package provides the content negotiation of responses, picking JSON, YAML, XML, CSV or MessagePack
+// from the format query parameter or the Accept header, and marshalling values in the format picked.
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a representation of responses, named as in the format query parameter.
+type Format string
+
+const (
+	JSON    Format = "json"
+	YAML    Format = "yaml"
+	XML     Format = "xml"
+	CSV     Format = "csv"
+	MsgPack Format = "msgpack"
+)
+
+// Param is the query parameter selecting a format, it takes precedence over the Accept header.
+const Param = "format"
+
+// ErrNotAcceptable is returned when none of the formats is acceptable to the client.
+var ErrNotAcceptable = errors.New("none of the accepted media types is available, " +
+	"use json, yaml, xml, csv or msgpack")
+
+// ErrNotTabular is returned when marshalling a value that doesn't implement Table to CSV.
+var ErrNotTabular = errors.New("csv isn't available for this response")
+
+// Table is implemented by values that can be written as CSV, the header is the first record.
+type Table interface {
+	CSVHeader() []string
+	CSVRecords() [][]string
+}
+
+// Formats returns every format, in order of preference when a client accepts several equally.
+func Formats() []Format {
+	return []Format{JSON, YAML, XML, CSV, MsgPack}
+}
+
+// ContentType returns the Content-Type of responses in f.
+func (f Format) ContentType() string {
+	switch f {
+	case YAML:
+		return "application/yaml; charset=utf-8"
+	case XML:
+		return "application/xml; charset=utf-8"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case MsgPack:
+		return "application/msgpack"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// mediaTypes returns the media types clients may ask f with, the first one is canonical.
+func (f Format) mediaTypes() []string {
+	switch f {
+	case YAML:
+		return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+	case XML:
+		return []string{"application/xml", "text/xml"}
+	case CSV:
+		return []string{"text/csv"}
+	case MsgPack:
+		return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+	default:
+		return []string{"application/json"}
+	}
+}
+
+// Marshal returns v in f, values must implement Table to be marshalled to CSV.
+func (f Format) Marshal(v interface{}) ([]byte, error) {
+	switch f {
+	case JSON:
+		return json.Marshal(v)
+	case YAML:
+		return yaml.Marshal(v)
+	case XML:
+		b, err := xml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), b...), nil
+	case CSV:
+		return marshalCSV(v)
+	case MsgPack:
+		var b []byte
+		err := codec.NewEncoderBytes(&b, &codec.MsgpackHandle{}).Encode(v)
+		return b, err
+	default:
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+}
+
+func marshalCSV(v interface{}) ([]byte, error) {
+	table, ok := v.(Table)
+	if !ok {
+		return nil, ErrNotTabular
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(table.CSVHeader()); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(table.CSVRecords()); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// FromRequest returns the format of the response to req, from its format query parameter or else
+// its Accept header, JSON when neither is set.
+func FromRequest(req *http.Request) (Format, error) {
+	if param := req.URL.Query().Get(Param); param != "" {
+		for _, f := range Formats() {
+			if string(f) == strings.ToLower(param) {
+				return f, nil
+			}
+		}
+
+		return "", ErrNotAcceptable
+	}
+
+	return Negotiate(req.Header.Get("Accept"))
+}
+
+// Negotiate returns the format the accept header prefers, following the quality values and
+// precedence of the media ranges of RFC 9110. An empty header accepts any format.
+func Negotiate(accept string) (Format, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := Format(""), 0.0
+	for _, f := range Formats() {
+		if q := quality(ranges, f); q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	if best == "" {
+		return "", ErrNotAcceptable
+	}
+
+	return best, nil
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok {
+			continue
+		}
+
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// quality returns the quality value of the most specific range matching one of the media types of f.
+func quality(ranges []mediaRange, f Format) float64 {
+	q, specificity := 0.0, -1
+	for _, mediaType := range f.mediaTypes() {
+		typ, subtype, _ := strings.Cut(mediaType, "/")
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.typ == typ && r.subtype == subtype:
+				s = 2
+			case r.typ == typ && r.subtype == "*":
+				s = 1
+			case r.typ == "*" && r.subtype == "*":
+				s = 0
+			}
+			if s > specificity || (s == specificity && s >= 0 && r.q > q) {
+				q, specificity = r.q, s
+			}
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,100 @@
+package format_test
+
+import (
+	"net/http/httptest"
+	"pokedex-clone/pkg/format"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := map[string]struct {
+		target  string
+		accept  string
+		want    format.Format
+		wantErr error
+	}{
+		"defaults to json": {
+			target: "/pokemon/mewtwo",
+			want:   format.JSON,
+		},
+		"any media type is json": {
+			target: "/pokemon/mewtwo",
+			accept: "*/*",
+			want:   format.JSON,
+		},
+		"exact media type": {
+			target: "/pokemon/mewtwo",
+			accept: "text/csv",
+			want:   format.CSV,
+		},
+		"media type aliases": {
+			target: "/pokemon/mewtwo",
+			accept: "application/x-msgpack",
+			want:   format.MsgPack,
+		},
+		"media types are case insensitive": {
+			target: "/pokemon/mewtwo",
+			accept: "Application/XML",
+			want:   format.XML,
+		},
+		"highest quality wins": {
+			target: "/pokemon/mewtwo",
+			accept: "application/json;q=0.5, application/yaml;q=0.9, text/csv;q=0.1",
+			want:   format.YAML,
+		},
+		"equal quality prefers json": {
+			target: "/pokemon/mewtwo",
+			accept: "text/csv, application/xml, application/json",
+			want:   format.JSON,
+		},
+		"subtype wildcard": {
+			target: "/pokemon/mewtwo",
+			accept: "text/*",
+			want:   format.YAML,
+		},
+		"the most specific range applies": {
+			target: "/pokemon/mewtwo",
+			accept: "*/*, application/json;q=0",
+			want:   format.YAML,
+		},
+		"the format parameter takes precedence": {
+			target: "/pokemon/mewtwo?format=XML",
+			accept: "application/json",
+			want:   format.XML,
+		},
+		"unsupported media types are not acceptable": {
+			target:  "/pokemon/mewtwo",
+			accept:  "text/html, image/*",
+			wantErr: format.ErrNotAcceptable,
+		},
+		"excluded media types are not acceptable": {
+			target:  "/pokemon/mewtwo",
+			accept:  "application/json;q=0",
+			wantErr: format.ErrNotAcceptable,
+		},
+		"unsupported format parameters are not acceptable": {
+			target:  "/pokemon/mewtwo?format=html",
+			wantErr: format.ErrNotAcceptable,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			got, err := format.FromRequest(req)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMarshalCSVNeedsTable(t *testing.T) {
+	_, err := format.CSV.Marshal(map[string]string{"name": "mewtwo"})
+	assert.ErrorIs(t, err, format.ErrNotTabular)
+}
@@ -0,0 +1,72 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go/trace/noop"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+)
+
+type budgetKey struct{}
+
+// budget is the cost a query may spend: every field it resolves costs one, the fields selected in a list
+// once per item. Introspection is free.
+type budget struct {
+	limit int64
+	spent int64
+}
+
+func withBudget(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, budgetKey{}, &budget{limit: int64(limit)})
+}
+
+// costTracer charges the fields of a query to its budget as they are resolved, which the graphql package
+// does before calling their resolvers. The fields beyond the budget fail with the complexity error, and
+// their selections aren't resolved, so a query fetches nothing past its limit.
+type costTracer struct {
+	noop.Tracer
+}
+
+func (t costTracer) TraceField(
+	ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{},
+) (context.Context, tracer.FieldFinishFunc) {
+	ctx, finish := t.Tracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok || strings.HasPrefix(typeName, "__") || strings.HasPrefix(fieldName, "__") {
+		return ctx, finish
+	}
+
+	if atomic.AddInt64(&b.spent, 1) > b.limit {
+		return &exceededContext{
+			Context: ctx,
+			err:     fmt.Errorf("query complexity exceeds the limit of %d", b.limit),
+		}, finish
+	}
+
+	return ctx, finish
+}
+
+// exceededContext is the context of the fields beyond the budget of their query, it is done already, and
+// reports the complexity error as its cause, which the graphql package answers the field with.
+type exceededContext struct {
+	context.Context
+	err error
+}
+
+var closed = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (c *exceededContext) Done() <-chan struct{} {
+	return closed
+}
+
+func (c *exceededContext) Err() error {
+	return c.err
+}
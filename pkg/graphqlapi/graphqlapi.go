@@ -0,0 +1,122 @@
+// Graphqlapi package provides the GraphQL endpoint of the service, which serves a pokemon along with its species,
+// evolution chain and translated description in one query, selecting only the fields it needs.
+package graphqlapi
+
+import (
+	_ "embed" // the schema is embedded
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pokedex-clone/pkg/pokemon"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	// Path is the route of the GraphQL endpoint.
+	Path = "/graphql"
+	// MaxBatchSize is the number of names the pokemons query may hold.
+	MaxBatchSize = 100
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Options configure the handler returned by New.
+type Options struct {
+	// MaxDepth is the deepest field a query may select, counting the fields of Query as the first level.
+	// Queries of any depth are executed when zero.
+	MaxDepth int
+	// MaxComplexity is the highest cost of a query, see budget, the fields beyond it fail. Queries of
+	// any cost are executed when zero.
+	MaxComplexity int
+	// AllowTranslation reports whether the client of c may fetch one more translation, it is called once for
+	// every species a query requests translated. Translations aren't limited when nil.
+	AllowTranslation func(c *gin.Context) bool
+}
+
+// Handler serves GraphQL queries with GET and POST requests, as described by the GraphQL over HTTP specification.
+type Handler struct {
+	service *pokemon.Service
+	opts    Options
+	schema  *graphql.Schema
+}
+
+// New returns the handler of the GraphQL queries, resolving them with service.
+func New(service *pokemon.Service, opts Options) (*Handler, error) {
+	parsed, err := graphql.ParseSchema(schema, &resolver{},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(opts.MaxDepth),
+		graphql.Tracer(costTracer{}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GraphQL schema: %w", err)
+	}
+
+	return &Handler{service: service, opts: opts, schema: parsed}, nil
+}
+
+// Register adds the GraphQL routes to routes.
+func (h *Handler) Register(routes gin.IRoutes) {
+	routes.GET(Path, h.Serve)
+	routes.POST(Path, h.Serve)
+}
+
+// Request is a GraphQL query, sent as a JSON body or as query parameters, the variables being JSON encoded.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Serve executes the query of the request. Requests that aren't GraphQL queries are rejected with a 400,
+// queries failing validation, or exceeding the depth limit, are answered with their errors, and the fields
+// beyond the complexity limit with theirs.
+func (h *Handler) Serve(c *gin.Context) {
+	req, err := bindRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	allowTranslation := func() bool { return true }
+	if h.opts.AllowTranslation != nil {
+		allowTranslation = func() bool { return h.opts.AllowTranslation(c) }
+	}
+
+	ctx := withLoaders(c.Request.Context(), newLoaders(h.service, allowTranslation))
+	if h.opts.MaxComplexity > 0 {
+		ctx = withBudget(ctx, h.opts.MaxComplexity)
+	}
+	c.JSON(http.StatusOK, h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+func bindRequest(c *gin.Context) (*Request, error) {
+	var req Request
+	if c.Request.Method == http.MethodPost {
+		if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("decoding request: %w", err)
+		}
+	} else {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, fmt.Errorf("decoding variables: %w", err)
+			}
+		}
+	}
+
+	if req.Query == "" {
+		return nil, errors.New("query must not be empty")
+	}
+
+	return &req, nil
+}
+
+func errorResponse(err error) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", err)}}
+}
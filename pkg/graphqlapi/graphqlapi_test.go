@@ -0,0 +1,344 @@
+package graphqlapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/graphqlapi"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+func newRouter(t *testing.T, service *pokemon.Service, opts graphqlapi.Options) *gin.Engine {
+	t.Helper()
+
+	handler, err := graphqlapi.New(service, opts)
+	assert.Nil(t, err)
+
+	router := gin.New()
+	handler.Register(router)
+
+	return router
+}
+
+func post(t *testing.T, router *gin.Engine, query string, variables map[string]interface{}) (int, *response) {
+	t.Helper()
+
+	body, err := json.Marshal(graphqlapi.Request{Query: query, Variables: variables})
+	assert.Nil(t, err)
+	req := httptest.NewRequest(http.MethodPost, graphqlapi.Path, strings.NewReader(string(body)))
+
+	return serve(t, router, req)
+}
+
+func serve(t *testing.T, router *gin.Engine, req *http.Request) (int, *response) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var res response
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+	// requests failing before execution have no data at all
+	if len(res.Data) == 0 {
+		res.Data = json.RawMessage("null")
+	}
+
+	return rr.Code, &res
+}
+
+func pikachuSpecies() *api.PokemonSpecies {
+	return &api.PokemonSpecies{
+		ID:   25,
+		Name: "pikachu",
+		FlavorTextEntries: []api.FlavorText{
+			{
+				FlavorText: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+				Language:   api.NamedAPIResource{Name: "en"},
+				Version:    api.NamedAPIResource{Name: "red"},
+			},
+			{
+				FlavorText: "Lorsque plusieurs de ces Pokémon se réunissent, leur électricité peut provoquer des orages.",
+				Language:   api.NamedAPIResource{Name: "fr"},
+				Version:    api.NamedAPIResource{Name: "red"},
+			},
+		},
+		Habitat:    api.NamedAPIResource{Name: "forest", URL: "https://pokeapi.co/api/v2/pokemon-habitat/2/"},
+		Generation: api.NamedAPIResource{Name: "generation-i", URL: "https://pokeapi.co/api/v2/generation/1/"},
+		EvolvesFromSpecies: &api.NamedAPIResource{
+			Name: "pichu",
+			URL:  "https://pokeapi.co/api/v2/pokemon-species/172/",
+		},
+		EvolutionChain: api.APIResource{URL: "https://pokeapi.co/api/v2/evolution-chain/10/"},
+	}
+}
+
+func pikachuChain() *api.EvolutionChain {
+	thunderStone := api.NamedAPIResource{Name: "thunder-stone"}
+
+	return &api.EvolutionChain{
+		ID: 10,
+		Chain: api.ChainLink{
+			Species: api.NamedAPIResource{Name: "pichu", URL: "https://pokeapi.co/api/v2/pokemon-species/172/"},
+			EvolvesTo: []api.ChainLink{{
+				Species:          api.NamedAPIResource{Name: "pikachu", URL: "https://pokeapi.co/api/v2/pokemon-species/25/"},
+				EvolutionDetails: []api.EvolutionDetail{{Trigger: api.NamedAPIResource{Name: "level-up"}}},
+				EvolvesTo: []api.ChainLink{{
+					Species: api.NamedAPIResource{Name: "raichu", URL: "https://pokeapi.co/api/v2/pokemon-species/26/"},
+					EvolutionDetails: []api.EvolutionDetail{{
+						Trigger: api.NamedAPIResource{Name: "use-item"},
+						Item:    &thunderStone,
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := map[string]struct {
+		query      string
+		variables  map[string]interface{}
+		opts       graphqlapi.Options
+		wantData   string
+		wantErrors []string
+	}{
+		"pokemon": {
+			query: `{ pokemon(name: "Pikachu") { id name habitat isLegendary types } }`,
+			wantData: `{"pokemon": {
+				"id": 25, "name": "pikachu", "habitat": "forest", "isLegendary": false, "types": ["electric"]
+			}}`,
+		},
+		"translated description": {
+			query:     `query($name: String!) { pokemon(name: $name) { translation { type translated description } } }`,
+			variables: map[string]interface{}{"name": "25"},
+			wantData: `{"pokemon": {"translation": {
+				"type": "shakespeare", "translated": true, "description": "Thee electricity couldst buildeth."
+			}}}`,
+		},
+		"species": {
+			query: `{ species(name: "25") {
+				id name generation habitat
+				flavorTexts(language: "fr") { language version }
+				evolvesFrom { id name }
+			} }`,
+			wantData: `{"species": {
+				"id": 25, "name": "pikachu", "generation": "generation-i", "habitat": "forest",
+				"flavorTexts": [{"language": "fr", "version": "red"}],
+				"evolvesFrom": {"id": 172, "name": "pichu"}
+			}}`,
+		},
+		"evolution chain": {
+			query: `{ pokemon(name: "pikachu") { evolutionChain {
+				species { name }
+				evolvesTo {
+					species { name }
+					details { trigger }
+					evolvesTo { species { id name } details { trigger minLevel item } }
+				}
+			} } }`,
+			wantData: `{"pokemon": {"evolutionChain": {
+				"species": {"name": "pichu"},
+				"evolvesTo": [{
+					"species": {"name": "pikachu"},
+					"details": [{"trigger": "level-up"}],
+					"evolvesTo": [{
+						"species": {"id": 26, "name": "raichu"},
+						"details": [{"trigger": "use-item", "minLevel": null, "item": "thunder-stone"}]
+					}]
+				}]
+			}}}`,
+		},
+		"unknown pokemon in a list": {
+			query:      `{ pokemons(names: ["pikachu", "missingno"]) { name } }`,
+			wantData:   `{"pokemons": [{"name": "pikachu"}, null]}`,
+			wantErrors: []string{"not found"},
+		},
+		"invalid name": {
+			query:      `{ pokemon(name: "pika chu!") { name } }`,
+			wantData:   `{"pokemon": null}`,
+			wantErrors: []string{pokemon.ErrInvalidIdentifier.Error()},
+		},
+		"translation limit": {
+			query:      `{ pokemon(name: "pikachu") { name translation { description } } }`,
+			opts:       graphqlapi.Options{AllowTranslation: func(*gin.Context) bool { return false }},
+			wantData:   `{"pokemon": {"name": "pikachu", "translation": null}}`,
+			wantErrors: []string{graphqlapi.ErrTranslationLimited.Error()},
+		},
+		"too deep": {
+			query:      `{ pokemon(name: "pikachu") { evolutionChain { evolvesTo { evolvesTo { species { name } } } } } }`,
+			opts:       graphqlapi.Options{MaxDepth: 5},
+			wantData:   `null`,
+			wantErrors: []string{`"name" has depth 6 that exceeds max depth 5`},
+		},
+		"complexity of the limited lists": {
+			query:    `{ species(name: "pikachu") { flavorTexts(limit: 1) { language } } }`,
+			opts:     graphqlapi.Options{MaxComplexity: 3},
+			wantData: `{"species": {"flavorTexts": [{"language": "en"}]}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			for _, ident := range []string{"pikachu", "25"} {
+				mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), ident).Return(pikachuSpecies(), nil).AnyTimes()
+			}
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound).AnyTimes()
+			mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "25").Return(&api.Pokemon{
+				ID:    25,
+				Name:  "pikachu",
+				Types: []api.PokemonType{{Slot: 1, Type: api.NamedAPIResource{Name: "electric"}}},
+			}, nil).AnyTimes()
+			mockPokeAPI.EXPECT().GetEvolutionChain(gomock.Any(), 10).Return(pikachuChain(), nil).AnyTimes()
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "pikachu", gomock.Any(), api.TTypeShakespeare).Return(
+				&api.TranslateAPIResponse{
+					Success:  api.Success{Total: 1},
+					Contents: api.Contents{Translated: "Thee electricity couldst buildeth."},
+				}, nil).AnyTimes()
+			service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+
+			code, res := post(t, newRouter(t, service, tt.opts), tt.query, tt.variables)
+			assert.Equal(t, http.StatusOK, code)
+			assert.JSONEq(t, tt.wantData, string(res.Data))
+			assert.Len(t, res.Errors, len(tt.wantErrors))
+			for i, want := range tt.wantErrors {
+				if i < len(res.Errors) {
+					assert.Contains(t, res.Errors[i].Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryBatching(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	for i, name := range []string{"pichu", "pikachu", "raichu"} {
+		species := pikachuSpecies()
+		species.ID, species.Name = []int{172, 25, 26}[i], name
+		// once by the pokemon, once by the evolution chain field
+		mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), name).Return(species, nil).Times(2)
+	}
+	// the chain shared by the three pokemon is fetched once
+	mockPokeAPI.EXPECT().GetEvolutionChain(gomock.Any(), 10).Return(pikachuChain(), nil).Times(1)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	code, res := post(t, newRouter(t, service, graphqlapi.Options{}), `{
+		pokemons(names: ["pichu", "pikachu", "raichu"]) { name evolutionChain { species { name } } }
+		again: pokemon(name: "pikachu") { name species { id name } }
+	}`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, res.Errors)
+	assert.JSONEq(t, `{
+		"pokemons": [
+			{"name": "pichu", "evolutionChain": {"species": {"name": "pichu"}}},
+			{"name": "pikachu", "evolutionChain": {"species": {"name": "pichu"}}},
+			{"name": "raichu", "evolutionChain": {"species": {"name": "pichu"}}}
+		],
+		"again": {"name": "pikachu", "species": {"id": 25, "name": "pikachu"}}
+	}`, string(res.Data))
+}
+
+func TestComplexity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	// the list and the species of one pokemon fit in the budget, the fields of the others fail before fetching
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).Return(pikachuSpecies(), nil).Times(1)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	code, res := post(t, newRouter(t, service, graphqlapi.Options{MaxComplexity: 2}), `{
+		pokemons(names: ["pikachu", "raichu", "pichu"]) { species { name } }
+		__schema { queryType { name } }
+	}`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, res.Errors)
+	for _, err := range res.Errors {
+		assert.Equal(t, "query complexity exceeds the limit of 2", err.Message)
+	}
+	assert.Contains(t, string(res.Data), `"queryType":{"name":"Query"}`)
+}
+
+func TestRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "pikachu").Return(pikachuSpecies(), nil).AnyTimes()
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+	router := newRouter(t, service, graphqlapi.Options{})
+
+	tests := map[string]struct {
+		req        *http.Request
+		wantStatus int
+		wantData   string
+		wantError  string
+	}{
+		"get": {
+			req: httptest.NewRequest(http.MethodGet, graphqlapi.Path+"?"+url.Values{
+				"query":     {`query($name: String!) { pokemon(name: $name) { name } }`},
+				"variables": {`{"name": "pikachu"}`},
+			}.Encode(), nil),
+			wantStatus: http.StatusOK,
+			wantData:   `{"pokemon": {"name": "pikachu"}}`,
+		},
+		"operation name": {
+			req: httptest.NewRequest(http.MethodPost, graphqlapi.Path, strings.NewReader(`{
+				"query": "query A { pokemon(name: \"pikachu\") { id } } query B { pokemon(name: \"pikachu\") { name } }",
+				"operationName": "B"
+			}`)),
+			wantStatus: http.StatusOK,
+			wantData:   `{"pokemon": {"name": "pikachu"}}`,
+		},
+		"invalid query": {
+			req: httptest.NewRequest(http.MethodGet, graphqlapi.Path+"?"+url.Values{
+				"query": {`{ pokemon(name: "pikachu") }`},
+			}.Encode(), nil),
+			wantStatus: http.StatusOK,
+			wantData:   `null`,
+			wantError:  `Field "pokemon" of type "Pokemon" must have a selection of subfields`,
+		},
+		"missing query": {
+			req:        httptest.NewRequest(http.MethodGet, graphqlapi.Path, nil),
+			wantStatus: http.StatusBadRequest,
+			wantData:   `null`,
+			wantError:  "query must not be empty",
+		},
+		"invalid body": {
+			req:        httptest.NewRequest(http.MethodPost, graphqlapi.Path, strings.NewReader("{")),
+			wantStatus: http.StatusBadRequest,
+			wantData:   `null`,
+			wantError:  "decoding request",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, res := serve(t, router, tt.req)
+			assert.Equal(t, tt.wantStatus, code)
+			assert.JSONEq(t, tt.wantData, string(res.Data))
+			if tt.wantError == "" {
+				assert.Empty(t, res.Errors)
+			} else if assert.Len(t, res.Errors, 1) {
+				assert.Contains(t, res.Errors[0].Message, tt.wantError)
+			}
+		})
+	}
+}
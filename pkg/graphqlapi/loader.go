@@ -0,0 +1,105 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// batchWait is how long a loader collects keys before fetching them, the fields of a query resolved
+	// in parallel request theirs within it.
+	batchWait = time.Millisecond
+	// batchConcurrency is the number of keys of a batch fetched at once.
+	batchConcurrency = 8
+)
+
+// loader fetches the values of the keys requested within batchWait of each other in one batch, and
+// remembers them for the rest of the request, so a value is fetched once however many fields need it.
+// The pokeapi has no batch endpoints, the keys of a batch are fetched concurrently instead, which bounds
+// the upstream requests of a query to batchConcurrency at once.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, key K) (V, error)
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   []K
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, key K) (V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, results: make(map[K]*result[V])}
+}
+
+// load returns the value of key, waiting for the batch fetching it.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.batch = append(l.batch, key)
+		if len(l.batch) == 1 {
+			time.AfterFunc(batchWait, func() { l.dispatch(ctx) })
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// prime remembers the value of key unless it was requested already, e.g. a species loaded by dex number
+// under its name.
+func (l *loader[K, V]) prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.results[key]; !ok {
+		r := &result[V]{done: make(chan struct{}), value: value}
+		close(r.done)
+		l.results[key] = r
+	}
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.batch
+	l.batch = nil
+	pending := make([]*result[V], len(keys))
+	for i, key := range keys {
+		pending[i] = l.results[key]
+	}
+	l.mu.Unlock()
+
+	sem := make(chan struct{}, batchConcurrency)
+	for i, key := range keys {
+		sem <- struct{}{}
+		go func(r *result[V], key K) {
+			completed := false
+			defer func() {
+				// a fetch that panics, or exits its goroutine, fails its key rather than the process, and its
+				// loads still return
+				if !completed {
+					r.err = fmt.Errorf("loading %v: %v", key, recover())
+				}
+				close(r.done)
+				<-sem
+			}()
+
+			r.value, r.err = l.fetch(ctx, key)
+			completed = true
+		}(pending[i], key)
+	}
+}
@@ -0,0 +1,448 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/pokemon"
+	"strconv"
+)
+
+// ErrTranslationLimited is the error of the translations a client requests beyond its translation rate limit.
+var ErrTranslationLimited = errors.New("translation rate limit exceeded")
+
+// loaders are the loaders of a request, shared by the resolvers of all its fields.
+type loaders struct {
+	species    *loader[string, *api.PokemonSpecies]
+	pokemon    *loader[string, *pokemon.Pokemon]
+	translated *loader[string, *pokemon.Pokemon]
+	types      *loader[int, []string]
+	chains     *loader[int, *api.EvolutionChain]
+}
+
+// newLoaders returns the loaders of a request, allowTranslation is called once for every species it
+// requests translated.
+func newLoaders(service *pokemon.Service, allowTranslation func() bool) *loaders {
+	return &loaders{
+		species: newLoader(service.FetchSpecies),
+		pokemon: newLoader(service.FetchPokemon),
+		translated: newLoader(func(ctx context.Context, name string) (*pokemon.Pokemon, error) {
+			if !allowTranslation() {
+				return nil, ErrTranslationLimited
+			}
+
+			return service.FetchTranslated(ctx, name)
+		}),
+		types: newLoader(func(ctx context.Context, id int) ([]string, error) {
+			return service.FetchTypes(ctx, &pokemon.Pokemon{ID: id}), nil
+		}),
+		chains: newLoader(service.FetchEvolutionChain),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+// resolver is the root resolver, serving the fields of Query.
+type resolver struct{}
+
+type nameArgs struct {
+	Name string
+}
+
+func (*resolver) Pokemon(ctx context.Context, args nameArgs) (*pokemonResolver, error) {
+	ident, err := pokemon.ParseIdentifier(args.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pokemonResolver{l: loadersFrom(ctx), key: ident.String()}, nil
+}
+
+type namesArgs struct {
+	Names []string
+}
+
+// Pokemons returns a pokemon resolver for every name, the pokemon that can't be fetched are nulled by the
+// errors of their fields, so the others are served.
+func (*resolver) Pokemons(ctx context.Context, args namesArgs) ([]*pokemonResolver, error) {
+	if len(args.Names) > MaxBatchSize {
+		return nil, fmt.Errorf("at most %d names may be requested at once", MaxBatchSize)
+	}
+
+	res := make([]*pokemonResolver, len(args.Names))
+	for i, name := range args.Names {
+		ident, err := pokemon.ParseIdentifier(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		res[i] = &pokemonResolver{l: loadersFrom(ctx), key: ident.String()}
+	}
+
+	return res, nil
+}
+
+func (*resolver) Species(ctx context.Context, args nameArgs) (*speciesResolver, error) {
+	ident, err := pokemon.ParseIdentifier(args.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &speciesResolver{l: loadersFrom(ctx), name: ident.Name, id: ident.ID}
+	s, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.name, r.id = s.Name, s.ID
+
+	return r, nil
+}
+
+// pokemonResolver loads its pokemon when one of its fields is resolved, the errors of a pokemon that can't
+// be fetched are those of its fields.
+type pokemonResolver struct {
+	l   *loaders
+	key string
+}
+
+func (r *pokemonResolver) load(ctx context.Context) (*pokemon.Pokemon, error) {
+	return r.l.pokemon.load(ctx, r.key)
+}
+
+func (r *pokemonResolver) ID(ctx context.Context) (int32, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(p.ID), nil
+}
+
+func (r *pokemonResolver) Name(ctx context.Context) (string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Name, nil
+}
+
+func (r *pokemonResolver) Description(ctx context.Context) (string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Description, nil
+}
+
+func (r *pokemonResolver) Habitat(ctx context.Context) (*string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return optional(p.Habitat), nil
+}
+
+func (r *pokemonResolver) IsLegendary(ctx context.Context) (bool, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return p.IsLegendary, nil
+}
+
+func (r *pokemonResolver) Types(ctx context.Context) ([]string, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.l.types.load(ctx, p.ID)
+}
+
+func (r *pokemonResolver) Translation(ctx context.Context) (*translationResolver, error) {
+	p, err := r.l.translated.load(ctx, r.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &translationResolver{p: p}, nil
+}
+
+func (r *pokemonResolver) Species(ctx context.Context) (*speciesResolver, error) {
+	p, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &speciesResolver{l: r.l, name: p.Name, id: p.ID}, nil
+}
+
+func (r *pokemonResolver) EvolutionChain(ctx context.Context) (*evolutionResolver, error) {
+	species, err := r.Species(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return species.EvolutionChain(ctx)
+}
+
+type translationResolver struct {
+	p *pokemon.Pokemon
+}
+
+func (r *translationResolver) Type() string {
+	if r.p.Translation == nil {
+		return ""
+	}
+
+	return r.p.Translation.Type
+}
+
+func (r *translationResolver) Translated() bool {
+	return r.p.Translation != nil && r.p.Translation.Translated
+}
+
+func (r *translationResolver) Description() string {
+	return r.p.Description
+}
+
+// speciesResolver serves its name and id as they are known, the species is only loaded for its other fields.
+type speciesResolver struct {
+	l *loaders
+	// name is empty, or id zero, when the species was referenced by the other one only.
+	name string
+	id   int
+}
+
+func (r *speciesResolver) load(ctx context.Context) (*api.PokemonSpecies, error) {
+	key := r.name
+	if key == "" {
+		key = strconv.Itoa(r.id)
+	}
+
+	s, err := r.l.species.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	// the species loaded by dex number serves the later loads by name too
+	r.l.species.prime(s.Name, s)
+
+	return s, nil
+}
+
+func (r *speciesResolver) ID(ctx context.Context) (int32, error) {
+	if r.id > 0 {
+		return int32(r.id), nil
+	}
+
+	s, err := r.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(s.ID), nil
+}
+
+func (r *speciesResolver) Name(ctx context.Context) (string, error) {
+	if r.name != "" {
+		return r.name, nil
+	}
+
+	s, err := r.load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Name, nil
+}
+
+func (r *speciesResolver) Generation(ctx context.Context) (string, error) {
+	s, err := r.load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Generation.Name, nil
+}
+
+func (r *speciesResolver) Habitat(ctx context.Context) (*string, error) {
+	s, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return optional(s.Habitat.Name), nil
+}
+
+func (r *speciesResolver) IsLegendary(ctx context.Context) (bool, error) {
+	s, err := r.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return s.IsLegendary, nil
+}
+
+type flavorTextsArgs struct {
+	Language *string
+	Version  *string
+	Limit    *int32
+}
+
+func (r *speciesResolver) FlavorTexts(ctx context.Context, args flavorTextsArgs) ([]*flavorTextResolver, error) {
+	s, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*flavorTextResolver, 0, len(s.FlavorTextEntries))
+	for i := range s.FlavorTextEntries {
+		entry := &s.FlavorTextEntries[i]
+		if args.Limit != nil && len(res) >= int(*args.Limit) {
+			break
+		}
+		if (args.Language != nil && entry.Language.Name != *args.Language) ||
+			(args.Version != nil && entry.Version.Name != *args.Version) {
+			continue
+		}
+		res = append(res, &flavorTextResolver{entry: entry})
+	}
+
+	return res, nil
+}
+
+func (r *speciesResolver) EvolvesFrom(ctx context.Context) (*speciesResolver, error) {
+	s, err := r.load(ctx)
+	if err != nil || s.EvolvesFromSpecies == nil {
+		return nil, err
+	}
+
+	return newSpeciesResolver(r.l, *s.EvolvesFromSpecies), nil
+}
+
+func (r *speciesResolver) EvolutionChain(ctx context.Context) (*evolutionResolver, error) {
+	s, err := r.load(ctx)
+	if err != nil || s.EvolutionChain.URL == "" {
+		return nil, err
+	}
+
+	id, err := s.EvolutionChain.ID()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected evolution chain url %s: %w", s.EvolutionChain.URL, err)
+	}
+
+	chain, err := r.l.chains.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evolutionResolver{l: r.l, link: &chain.Chain}, nil
+}
+
+func (r *speciesResolver) Pokemon(ctx context.Context) (*pokemonResolver, error) {
+	name, err := r.Name(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pokemonResolver{l: r.l, key: name}, nil
+}
+
+// newSpeciesResolver resolves the species referenced by ref, whose id is the last segment of its URL.
+func newSpeciesResolver(l *loaders, ref api.NamedAPIResource) *speciesResolver {
+	// the species is loaded by name when the id can't be read
+	id, _ := ref.ID()
+
+	return &speciesResolver{l: l, name: ref.Name, id: id}
+}
+
+type flavorTextResolver struct {
+	entry *api.FlavorText
+}
+
+func (r *flavorTextResolver) Text() string {
+	return r.entry.FlavorText
+}
+
+func (r *flavorTextResolver) Language() string {
+	return r.entry.Language.Name
+}
+
+func (r *flavorTextResolver) Version() string {
+	return r.entry.Version.Name
+}
+
+type evolutionResolver struct {
+	l    *loaders
+	link *api.ChainLink
+}
+
+func (r *evolutionResolver) Species() *speciesResolver {
+	return newSpeciesResolver(r.l, r.link.Species)
+}
+
+func (r *evolutionResolver) Details() []*evolutionDetailResolver {
+	res := make([]*evolutionDetailResolver, len(r.link.EvolutionDetails))
+	for i := range r.link.EvolutionDetails {
+		res[i] = &evolutionDetailResolver{detail: &r.link.EvolutionDetails[i]}
+	}
+
+	return res
+}
+
+func (r *evolutionResolver) EvolvesTo() []*evolutionResolver {
+	res := make([]*evolutionResolver, len(r.link.EvolvesTo))
+	for i := range r.link.EvolvesTo {
+		res[i] = &evolutionResolver{l: r.l, link: &r.link.EvolvesTo[i]}
+	}
+
+	return res
+}
+
+type evolutionDetailResolver struct {
+	detail *api.EvolutionDetail
+}
+
+func (r *evolutionDetailResolver) Trigger() string {
+	return r.detail.Trigger.Name
+}
+
+func (r *evolutionDetailResolver) MinLevel() *int32 {
+	if r.detail.MinLevel == nil {
+		return nil
+	}
+	level := int32(*r.detail.MinLevel)
+
+	return &level
+}
+
+func (r *evolutionDetailResolver) Item() *string {
+	if r.detail.Item == nil {
+		return nil
+	}
+
+	return optional(r.detail.Item.Name)
+}
+
+// optional returns nil for empty strings, which stand for missing values such as the habitat of the
+// species pokeapi assigns none.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
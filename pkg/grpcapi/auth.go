@@ -0,0 +1,71 @@
+package grpcapi
+
+import (
+	"context"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/grpcapi/pokedexpb"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey carries the API key of a request, the lowercase apikey.Header.
+const MetadataKey = "x-api-key"
+
+// authenticator checks the API key of the requests to the Pokedex service, as apikey.Keyring.Authenticate
+// does for the HTTP requests. Health checks and reflection are left open, as the HTTP probes are.
+type authenticator struct {
+	keyring *apikey.Keyring
+}
+
+func (a *authenticator) unary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := a.authenticate(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	if err := a.authenticate(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) error {
+	if !strings.HasPrefix(method, "/"+pokedexpb.Pokedex_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	secret := metadataKey(ctx)
+	if secret == "" {
+		if a.keyring.Required() {
+			return status.Error(codes.Unauthenticated, "missing api key, set the "+MetadataKey+" metadata")
+		}
+		return nil
+	}
+
+	if _, ok := a.keyring.Lookup(secret); !ok {
+		return status.Error(codes.Unauthenticated, "invalid api key")
+	}
+
+	return nil
+}
+
+// metadataKey returns the API key of the incoming request, if any.
+func metadataKey(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataKey); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
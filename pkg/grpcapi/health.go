@@ -0,0 +1,32 @@
+package grpcapi
+
+import (
+	"context"
+	"pokedex-clone/pkg/grpcapi/pokedexpb"
+	"pokedex-clone/pkg/health"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthServer reports the readiness of the service to gRPC health checks, for the whole server and the
+// Pokedex service alike.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	health *health.Health
+}
+
+func (h *healthServer) Check(
+	ctx context.Context, req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	if service := req.GetService(); service != "" && service != pokedexpb.Pokedex_ServiceDesc.ServiceName {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", service)
+	}
+
+	if h.health != nil && h.health.Readiness(ctx).Status != health.StatusOK {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
@@ -0,0 +1,105 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/grpcapi/pokedexpb"
+	"pokedex-clone/pkg/ratelimit"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// errRateLimited is the status of the calls beyond a rate limit, as the HTTP 429 responses.
+var errRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded")
+
+type clientKey struct{}
+
+// limiter applies the limits of the HTTP routes to the calls to the Pokedex service, overall and per client.
+// Clients are their API key, or their IP address without one, as they are over HTTP. Health checks and
+// reflection are left unlimited, as the HTTP probes are.
+type limiter struct {
+	keyring *apikey.Keyring
+	overall *ratelimit.Limiter
+	keyed   *ratelimit.Keyed
+}
+
+func (l *limiter) unary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := l.limit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (l *limiter) stream(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx, err := l.limit(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// limit takes a token for the call to method, and returns its context along with the key of its client.
+func (l *limiter) limit(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+pokedexpb.Pokedex_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	if l.overall != nil && !l.overall.Allow() {
+		return nil, errRateLimited
+	}
+
+	key := l.clientKey(ctx)
+	if l.keyed != nil && !l.keyed.AllowKey(key) {
+		return nil, errRateLimited
+	}
+
+	return context.WithValue(ctx, clientKey{}, key), nil
+}
+
+// clientKey returns the id of the API key of the call, or its peer IP address prefixed with "ip:" without one.
+func (l *limiter) clientKey(ctx context.Context) string {
+	if secret := metadataKey(ctx); secret != "" && l.keyring != nil {
+		if key, ok := l.keyring.Lookup(secret); ok {
+			return key.ID
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	return "ip:" + host
+}
+
+// clientFromContext returns the key of the client of a call, set by the limiter.
+func clientFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(clientKey{}).(string)
+	return key
+}
+
+// contextStream is a grpc.ServerStream whose handler sees ctx.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,859 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: pokedexpb/pokedex.proto
+
+package pokedexpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetPokemonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name is a species name or national dex number, e.g. mewtwo, Mr. Mime or 150.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *GetPokemonRequest) Reset() {
+	*x = GetPokemonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPokemonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPokemonRequest) ProtoMessage() {}
+
+func (x *GetPokemonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPokemonRequest.ProtoReflect.Descriptor instead.
+func (*GetPokemonRequest) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetPokemonRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Pokemon struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Habitat     string `protobuf:"bytes,4,opt,name=habitat,proto3" json:"habitat,omitempty"`
+	IsLegendary bool   `protobuf:"varint,5,opt,name=is_legendary,json=isLegendary,proto3" json:"is_legendary,omitempty"`
+	// types are ordered by slot, they are empty when they couldn't be fetched.
+	Types []string `protobuf:"bytes,6,rep,name=types,proto3" json:"types,omitempty"`
+	// translation is only set on translated pokemon.
+	Translation *Translation `protobuf:"bytes,7,opt,name=translation,proto3" json:"translation,omitempty"`
+}
+
+func (x *Pokemon) Reset() {
+	*x = Pokemon{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pokemon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pokemon) ProtoMessage() {}
+
+func (x *Pokemon) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pokemon.ProtoReflect.Descriptor instead.
+func (*Pokemon) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Pokemon) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Pokemon) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Pokemon) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Pokemon) GetHabitat() string {
+	if x != nil {
+		return x.Habitat
+	}
+	return ""
+}
+
+func (x *Pokemon) GetIsLegendary() bool {
+	if x != nil {
+		return x.IsLegendary
+	}
+	return false
+}
+
+func (x *Pokemon) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *Pokemon) GetTranslation() *Translation {
+	if x != nil {
+		return x.Translation
+	}
+	return nil
+}
+
+type Translation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// type is yoda or shakespeare, empty for descriptions that aren't in english.
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// translated is false when the description was served untranslated.
+	Translated bool `protobuf:"varint,2,opt,name=translated,proto3" json:"translated,omitempty"`
+}
+
+func (x *Translation) Reset() {
+	*x = Translation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Translation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Translation) ProtoMessage() {}
+
+func (x *Translation) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Translation.ProtoReflect.Descriptor instead.
+func (*Translation) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Translation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Translation) GetTranslated() bool {
+	if x != nil {
+		return x.Translated
+	}
+	return false
+}
+
+type BatchGetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Names []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	// translated translates the descriptions, as GetTranslatedPokemon does.
+	Translated bool `protobuf:"varint,2,opt,name=translated,proto3" json:"translated,omitempty"`
+}
+
+func (x *BatchGetRequest) Reset() {
+	*x = BatchGetRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRequest) ProtoMessage() {}
+
+func (x *BatchGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRequest) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetRequest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *BatchGetRequest) GetTranslated() bool {
+	if x != nil {
+		return x.Translated
+	}
+	return false
+}
+
+type BatchGetResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results are in the order of the requested names.
+	Results []*BatchGetResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *BatchGetResponse) Reset() {
+	*x = BatchGetResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResponse) ProtoMessage() {}
+
+func (x *BatchGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetResponse) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchGetResponse) GetResults() []*BatchGetResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchGetResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are assignable to Result:
+	//	*BatchGetResult_Pokemon
+	//	*BatchGetResult_Error
+	Result isBatchGetResult_Result `protobuf_oneof:"result"`
+}
+
+func (x *BatchGetResult) Reset() {
+	*x = BatchGetResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResult) ProtoMessage() {}
+
+func (x *BatchGetResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResult.ProtoReflect.Descriptor instead.
+func (*BatchGetResult) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (m *BatchGetResult) GetResult() isBatchGetResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *BatchGetResult) GetPokemon() *Pokemon {
+	if x, ok := x.GetResult().(*BatchGetResult_Pokemon); ok {
+		return x.Pokemon
+	}
+	return nil
+}
+
+func (x *BatchGetResult) GetError() *Error {
+	if x, ok := x.GetResult().(*BatchGetResult_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isBatchGetResult_Result interface {
+	isBatchGetResult_Result()
+}
+
+type BatchGetResult_Pokemon struct {
+	Pokemon *Pokemon `protobuf:"bytes,2,opt,name=pokemon,proto3,oneof"`
+}
+
+type BatchGetResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchGetResult_Pokemon) isBatchGetResult_Result() {}
+
+func (*BatchGetResult_Error) isBatchGetResult_Result() {}
+
+// Error is the status a single request for the species would have failed with.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// code is a google.rpc.Code.
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Error) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListPokemonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// generation is a generation name or id, e.g. generation-i.
+	Generation string `protobuf:"bytes,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	// habitat is a habitat name or id, e.g. cave.
+	Habitat   string `protobuf:"bytes,2,opt,name=habitat,proto3" json:"habitat,omitempty"`
+	Legendary *bool  `protobuf:"varint,3,opt,name=legendary,proto3,oneof" json:"legendary,omitempty"`
+}
+
+func (x *ListPokemonRequest) Reset() {
+	*x = ListPokemonRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPokemonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPokemonRequest) ProtoMessage() {}
+
+func (x *ListPokemonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPokemonRequest.ProtoReflect.Descriptor instead.
+func (*ListPokemonRequest) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListPokemonRequest) GetGeneration() string {
+	if x != nil {
+		return x.Generation
+	}
+	return ""
+}
+
+func (x *ListPokemonRequest) GetHabitat() string {
+	if x != nil {
+		return x.Habitat
+	}
+	return ""
+}
+
+func (x *ListPokemonRequest) GetLegendary() bool {
+	if x != nil && x.Legendary != nil {
+		return *x.Legendary
+	}
+	return false
+}
+
+type PokemonListItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *PokemonListItem) Reset() {
+	*x = PokemonListItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pokedexpb_pokedex_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PokemonListItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PokemonListItem) ProtoMessage() {}
+
+func (x *PokemonListItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pokedexpb_pokedex_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PokemonListItem.ProtoReflect.Descriptor instead.
+func (*PokemonListItem) Descriptor() ([]byte, []int) {
+	return file_pokedexpb_pokedex_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PokemonListItem) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PokemonListItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+var File_pokedexpb_pokedex_proto protoreflect.FileDescriptor
+
+var file_pokedexpb_pokedex_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x70, 0x62, 0x2f, 0x70, 0x6f, 0x6b, 0x65,
+	0x64, 0x65, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x70, 0x6f, 0x6b, 0x65, 0x64,
+	0x65, 0x78, 0x2e, 0x76, 0x31, 0x22, 0x27, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xdd,
+	0x01, 0x0a, 0x07, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x68, 0x61, 0x62, 0x69, 0x74, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x68, 0x61, 0x62, 0x69, 0x74, 0x61, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x73,
+	0x5f, 0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0b, 0x69, 0x73, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64,
+	0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x41,
+	0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65,
+	0x64, 0x22, 0x47, 0x0a, 0x0f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x22, 0x48, 0x0a, 0x10, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34,
+	0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x22, 0x8a, 0x01, 0x0a, 0x0e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2f, 0x0a, 0x07, 0x70,
+	0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70,
+	0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f,
+	0x6e, 0x48, 0x00, 0x52, 0x07, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x6f,
+	0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x22, 0x35, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x7f, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e,
+	0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18,
+	0x0a, 0x07, 0x68, 0x61, 0x62, 0x69, 0x74, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x68, 0x61, 0x62, 0x69, 0x74, 0x61, 0x74, 0x12, 0x21, 0x0a, 0x09, 0x6c, 0x65, 0x67, 0x65,
+	0x6e, 0x64, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x6c,
+	0x65, 0x67, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x22, 0x35, 0x0a, 0x0f, 0x50, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x32, 0xac, 0x02, 0x0a, 0x07, 0x50, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x12, 0x40, 0x0a, 0x0a,
+	0x47, 0x65, 0x74, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x6b,
+	0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6b, 0x65, 0x6d,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x6f, 0x6b, 0x65,
+	0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x4a,
+	0x0a, 0x14, 0x47, 0x65, 0x74, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x50,
+	0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x45, 0x0a, 0x08, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x12, 0x1b, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4c, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
+	0x12, 0x1e, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1b, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x30, 0x01, 0x42,
+	0x25, 0x5a, 0x23, 0x70, 0x6f, 0x6b, 0x65, 0x64, 0x65, 0x78, 0x2d, 0x63, 0x6c, 0x6f, 0x6e, 0x65,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x6f, 0x6b,
+	0x65, 0x64, 0x65, 0x78, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pokedexpb_pokedex_proto_rawDescOnce sync.Once
+	file_pokedexpb_pokedex_proto_rawDescData = file_pokedexpb_pokedex_proto_rawDesc
+)
+
+func file_pokedexpb_pokedex_proto_rawDescGZIP() []byte {
+	file_pokedexpb_pokedex_proto_rawDescOnce.Do(func() {
+		file_pokedexpb_pokedex_proto_rawDescData = protoimpl.X.CompressGZIP(file_pokedexpb_pokedex_proto_rawDescData)
+	})
+	return file_pokedexpb_pokedex_proto_rawDescData
+}
+
+var file_pokedexpb_pokedex_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_pokedexpb_pokedex_proto_goTypes = []interface{}{
+	(*GetPokemonRequest)(nil),  // 0: pokedex.v1.GetPokemonRequest
+	(*Pokemon)(nil),            // 1: pokedex.v1.Pokemon
+	(*Translation)(nil),        // 2: pokedex.v1.Translation
+	(*BatchGetRequest)(nil),    // 3: pokedex.v1.BatchGetRequest
+	(*BatchGetResponse)(nil),   // 4: pokedex.v1.BatchGetResponse
+	(*BatchGetResult)(nil),     // 5: pokedex.v1.BatchGetResult
+	(*Error)(nil),              // 6: pokedex.v1.Error
+	(*ListPokemonRequest)(nil), // 7: pokedex.v1.ListPokemonRequest
+	(*PokemonListItem)(nil),    // 8: pokedex.v1.PokemonListItem
+}
+var file_pokedexpb_pokedex_proto_depIdxs = []int32{
+	2, // 0: pokedex.v1.Pokemon.translation:type_name -> pokedex.v1.Translation
+	5, // 1: pokedex.v1.BatchGetResponse.results:type_name -> pokedex.v1.BatchGetResult
+	1, // 2: pokedex.v1.BatchGetResult.pokemon:type_name -> pokedex.v1.Pokemon
+	6, // 3: pokedex.v1.BatchGetResult.error:type_name -> pokedex.v1.Error
+	0, // 4: pokedex.v1.Pokedex.GetPokemon:input_type -> pokedex.v1.GetPokemonRequest
+	0, // 5: pokedex.v1.Pokedex.GetTranslatedPokemon:input_type -> pokedex.v1.GetPokemonRequest
+	3, // 6: pokedex.v1.Pokedex.BatchGet:input_type -> pokedex.v1.BatchGetRequest
+	7, // 7: pokedex.v1.Pokedex.ListPokemon:input_type -> pokedex.v1.ListPokemonRequest
+	1, // 8: pokedex.v1.Pokedex.GetPokemon:output_type -> pokedex.v1.Pokemon
+	1, // 9: pokedex.v1.Pokedex.GetTranslatedPokemon:output_type -> pokedex.v1.Pokemon
+	4, // 10: pokedex.v1.Pokedex.BatchGet:output_type -> pokedex.v1.BatchGetResponse
+	8, // 11: pokedex.v1.Pokedex.ListPokemon:output_type -> pokedex.v1.PokemonListItem
+	8, // [8:12] is the sub-list for method output_type
+	4, // [4:8] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_pokedexpb_pokedex_proto_init() }
+func file_pokedexpb_pokedex_proto_init() {
+	if File_pokedexpb_pokedex_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pokedexpb_pokedex_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPokemonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Pokemon); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Translation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPokemonRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pokedexpb_pokedex_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PokemonListItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pokedexpb_pokedex_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*BatchGetResult_Pokemon)(nil),
+		(*BatchGetResult_Error)(nil),
+	}
+	file_pokedexpb_pokedex_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pokedexpb_pokedex_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pokedexpb_pokedex_proto_goTypes,
+		DependencyIndexes: file_pokedexpb_pokedex_proto_depIdxs,
+		MessageInfos:      file_pokedexpb_pokedex_proto_msgTypes,
+	}.Build()
+	File_pokedexpb_pokedex_proto = out.File
+	file_pokedexpb_pokedex_proto_rawDesc = nil
+	file_pokedexpb_pokedex_proto_goTypes = nil
+	file_pokedexpb_pokedex_proto_depIdxs = nil
+}
@@ -0,0 +1,260 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: pokedexpb/pokedex.proto
+
+package pokedexpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Pokedex_GetPokemon_FullMethodName           = "/pokedex.v1.Pokedex/GetPokemon"
+	Pokedex_GetTranslatedPokemon_FullMethodName = "/pokedex.v1.Pokedex/GetTranslatedPokemon"
+	Pokedex_BatchGet_FullMethodName             = "/pokedex.v1.Pokedex/BatchGet"
+	Pokedex_ListPokemon_FullMethodName          = "/pokedex.v1.Pokedex/ListPokemon"
+)
+
+// PokedexClient is the client API for Pokedex service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PokedexClient interface {
+	// GetPokemon returns a species with its first english description.
+	GetPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error)
+	// GetTranslatedPokemon returns a species with its description translated to yoda for cave dwellers and
+	// legendary species, to shakespeare for the others, or untranslated when the translation fails.
+	GetTranslatedPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error)
+	// BatchGet returns up to 100 species at once, each failing on its own.
+	BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error)
+	// ListPokemon streams every species in dex order, optionally filtered by generation, habitat and
+	// legendary status.
+	ListPokemon(ctx context.Context, in *ListPokemonRequest, opts ...grpc.CallOption) (Pokedex_ListPokemonClient, error)
+}
+
+type pokedexClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPokedexClient(cc grpc.ClientConnInterface) PokedexClient {
+	return &pokedexClient{cc}
+}
+
+func (c *pokedexClient) GetPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error) {
+	out := new(Pokemon)
+	err := c.cc.Invoke(ctx, Pokedex_GetPokemon_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokedexClient) GetTranslatedPokemon(ctx context.Context, in *GetPokemonRequest, opts ...grpc.CallOption) (*Pokemon, error) {
+	out := new(Pokemon)
+	err := c.cc.Invoke(ctx, Pokedex_GetTranslatedPokemon_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokedexClient) BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error) {
+	out := new(BatchGetResponse)
+	err := c.cc.Invoke(ctx, Pokedex_BatchGet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokedexClient) ListPokemon(ctx context.Context, in *ListPokemonRequest, opts ...grpc.CallOption) (Pokedex_ListPokemonClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Pokedex_ServiceDesc.Streams[0], Pokedex_ListPokemon_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pokedexListPokemonClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Pokedex_ListPokemonClient interface {
+	Recv() (*PokemonListItem, error)
+	grpc.ClientStream
+}
+
+type pokedexListPokemonClient struct {
+	grpc.ClientStream
+}
+
+func (x *pokedexListPokemonClient) Recv() (*PokemonListItem, error) {
+	m := new(PokemonListItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PokedexServer is the server API for Pokedex service.
+// All implementations must embed UnimplementedPokedexServer
+// for forward compatibility
+type PokedexServer interface {
+	// GetPokemon returns a species with its first english description.
+	GetPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error)
+	// GetTranslatedPokemon returns a species with its description translated to yoda for cave dwellers and
+	// legendary species, to shakespeare for the others, or untranslated when the translation fails.
+	GetTranslatedPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error)
+	// BatchGet returns up to 100 species at once, each failing on its own.
+	BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error)
+	// ListPokemon streams every species in dex order, optionally filtered by generation, habitat and
+	// legendary status.
+	ListPokemon(*ListPokemonRequest, Pokedex_ListPokemonServer) error
+	mustEmbedUnimplementedPokedexServer()
+}
+
+// UnimplementedPokedexServer must be embedded to have forward compatible implementations.
+type UnimplementedPokedexServer struct {
+}
+
+func (UnimplementedPokedexServer) GetPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPokemon not implemented")
+}
+func (UnimplementedPokedexServer) GetTranslatedPokemon(context.Context, *GetPokemonRequest) (*Pokemon, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTranslatedPokemon not implemented")
+}
+func (UnimplementedPokedexServer) BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedPokedexServer) ListPokemon(*ListPokemonRequest, Pokedex_ListPokemonServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListPokemon not implemented")
+}
+func (UnimplementedPokedexServer) mustEmbedUnimplementedPokedexServer() {}
+
+// UnsafePokedexServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PokedexServer will
+// result in compilation errors.
+type UnsafePokedexServer interface {
+	mustEmbedUnimplementedPokedexServer()
+}
+
+func RegisterPokedexServer(s grpc.ServiceRegistrar, srv PokedexServer) {
+	s.RegisterService(&Pokedex_ServiceDesc, srv)
+}
+
+func _Pokedex_GetPokemon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPokemonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokedexServer).GetPokemon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pokedex_GetPokemon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokedexServer).GetPokemon(ctx, req.(*GetPokemonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pokedex_GetTranslatedPokemon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPokemonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokedexServer).GetTranslatedPokemon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pokedex_GetTranslatedPokemon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokedexServer).GetTranslatedPokemon(ctx, req.(*GetPokemonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pokedex_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokedexServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pokedex_BatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokedexServer).BatchGet(ctx, req.(*BatchGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pokedex_ListPokemon_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPokemonRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PokedexServer).ListPokemon(m, &pokedexListPokemonServer{stream})
+}
+
+type Pokedex_ListPokemonServer interface {
+	Send(*PokemonListItem) error
+	grpc.ServerStream
+}
+
+type pokedexListPokemonServer struct {
+	grpc.ServerStream
+}
+
+func (x *pokedexListPokemonServer) Send(m *PokemonListItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Pokedex_ServiceDesc is the grpc.ServiceDesc for Pokedex service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Pokedex_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pokedex.v1.Pokedex",
+	HandlerType: (*PokedexServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPokemon",
+			Handler:    _Pokedex_GetPokemon_Handler,
+		},
+		{
+			MethodName: "GetTranslatedPokemon",
+			Handler:    _Pokedex_GetTranslatedPokemon_Handler,
+		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _Pokedex_BatchGet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListPokemon",
+			Handler:       _Pokedex_ListPokemon_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pokedexpb/pokedex.proto",
+}
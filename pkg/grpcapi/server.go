@@ -0,0 +1,216 @@
+// Grpcapi package provides the gRPC API of the service, serving the pokemon of the HTTP API through the same
+// pokemon.Service and cache, along with the gRPC health checking and reflection services.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pokedexpb/pokedex.proto
+
+import (
+	"context"
+	"errors"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/grpcapi/pokedexpb"
+	"pokedex-clone/pkg/health"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/ratelimit"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MaxBatchSize is the number of names a BatchGet request may hold.
+	MaxBatchSize = 100
+
+	batchConcurrency = 8
+	// listPageSize is the number of species ListPokemon fetches at once while streaming them.
+	listPageSize = 100
+)
+
+// Options configure the server returned by New.
+type Options struct {
+	// Keyring authenticates the API keys of the requests, every request is accepted when nil.
+	Keyring *apikey.Keyring
+	// Health backs the health checking service, which always reports serving when nil.
+	Health *health.Health
+	// Reflection registers the reflection service, listing the services to tools such as grpcurl.
+	Reflection bool
+	// Limiter, KeyLimiter and TranslatedLimiter apply the limits of the HTTP routes, sharing their buckets:
+	// overall, per client, and per client again for every pokemon translated. The nil ones limit nothing.
+	Limiter           *ratelimit.Limiter
+	KeyLimiter        *ratelimit.Keyed
+	TranslatedLimiter *ratelimit.Keyed
+}
+
+// New returns a gRPC server with the Pokedex, health checking and optionally reflection services registered.
+// The interceptors of serverOpts, e.g. tracing and metrics, see the calls before they are authenticated and
+// limited.
+func New(service *pokemon.Service, opts Options, serverOpts ...grpc.ServerOption) *grpc.Server {
+	if opts.Keyring != nil {
+		auth := &authenticator{keyring: opts.Keyring}
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(auth.unary),
+			grpc.ChainStreamInterceptor(auth.stream),
+		)
+	}
+	limits := &limiter{keyring: opts.Keyring, overall: opts.Limiter, keyed: opts.KeyLimiter}
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(limits.unary),
+		grpc.ChainStreamInterceptor(limits.stream),
+	)
+
+	server := grpc.NewServer(serverOpts...)
+	pokedexpb.RegisterPokedexServer(server, &Server{service: service, translated: opts.TranslatedLimiter})
+	healthpb.RegisterHealthServer(server, &healthServer{health: opts.Health})
+	if opts.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
+
+// Server implements the Pokedex service.
+type Server struct {
+	pokedexpb.UnimplementedPokedexServer
+	service *pokemon.Service
+	// translated takes a token from the client for every pokemon it requests translated, when not nil.
+	translated *ratelimit.Keyed
+}
+
+func (s *Server) GetPokemon(ctx context.Context, req *pokedexpb.GetPokemonRequest) (*pokedexpb.Pokemon, error) {
+	p, err := s.service.FetchPokemon(ctx, req.GetName())
+	if err != nil {
+		return nil, statusError(err, codes.Unavailable)
+	}
+
+	return s.newPokemon(ctx, p, false), nil
+}
+
+func (s *Server) GetTranslatedPokemon(
+	ctx context.Context, req *pokedexpb.GetPokemonRequest,
+) (*pokedexpb.Pokemon, error) {
+	p, err := s.fetchTranslated(ctx, req.GetName())
+	if err != nil {
+		return nil, statusError(err, codes.Unavailable)
+	}
+
+	return s.newPokemon(ctx, p, true), nil
+}
+
+func (s *Server) BatchGet(ctx context.Context, req *pokedexpb.BatchGetRequest) (*pokedexpb.BatchGetResponse, error) {
+	names := req.GetNames()
+	if len(names) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d names may be requested at once", MaxBatchSize)
+	}
+
+	fetch := s.service.FetchPokemon
+	if req.GetTranslated() {
+		// every name takes a translation token, the names beyond the limit fail on their own
+		fetch = s.fetchTranslated
+	}
+
+	results := make([]*pokedexpb.BatchGetResult, len(names))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := &pokedexpb.BatchGetResult{Name: name}
+			if p, err := fetch(ctx, name); err != nil {
+				st := status.Convert(statusError(err, codes.Unavailable))
+				result.Result = &pokedexpb.BatchGetResult_Error{
+					Error: &pokedexpb.Error{Code: int32(st.Code()), Message: st.Message()},
+				}
+			} else {
+				result.Result = &pokedexpb.BatchGetResult_Pokemon{Pokemon: s.newPokemon(ctx, p, req.GetTranslated())}
+			}
+			results[i] = result
+		}(i, name)
+	}
+	wg.Wait()
+
+	return &pokedexpb.BatchGetResponse{Results: results}, nil
+}
+
+func (s *Server) ListPokemon(req *pokedexpb.ListPokemonRequest, stream pokedexpb.Pokedex_ListPokemonServer) error {
+	query := pokemon.ListQuery{
+		Limit:      listPageSize,
+		Generation: req.GetGeneration(),
+		Habitat:    req.GetHabitat(),
+	}
+	if req.Legendary != nil {
+		legendary := req.GetLegendary()
+		query.Legendary = &legendary
+	}
+
+	for {
+		page, hasNext, err := s.service.ListPokemon(stream.Context(), query)
+		if err != nil {
+			return statusError(err, codes.Unavailable)
+		}
+
+		for _, item := range page.Results {
+			if err = stream.Send(&pokedexpb.PokemonListItem{Id: int32(item.ID), Name: item.Name}); err != nil {
+				return err
+			}
+		}
+
+		if !hasNext || len(page.Results) == 0 {
+			return nil
+		}
+		query.Offset += query.Limit
+	}
+}
+
+// fetchTranslated is pokemon.Service.FetchTranslated within the translation limit of the client of ctx.
+func (s *Server) fetchTranslated(ctx context.Context, name string) (*pokemon.Pokemon, error) {
+	if s.translated != nil && !s.translated.AllowKey(clientFromContext(ctx)) {
+		return nil, errRateLimited
+	}
+
+	return s.service.FetchTranslated(ctx, name)
+}
+
+// newPokemon converts p, fetching its types as the v2 HTTP routes do.
+func (s *Server) newPokemon(ctx context.Context, p *pokemon.Pokemon, translated bool) *pokedexpb.Pokemon {
+	res := &pokedexpb.Pokemon{
+		Id:          int32(p.ID),
+		Name:        p.Name,
+		Description: p.Description,
+		Habitat:     p.Habitat,
+		IsLegendary: p.IsLegendary,
+		Types:       s.service.FetchTypes(ctx, p),
+	}
+	if translated && p.Translation != nil {
+		res.Translation = &pokedexpb.Translation{Type: p.Translation.Type, Translated: p.Translation.Translated}
+	}
+
+	return res
+}
+
+// statusError converts the errors of the service into the status codes matching the HTTP responses, other
+// errors are reported with code. As over HTTP, only the species the pokeapi doesn't have are reported not found.
+func statusError(err error, code codes.Code) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, errRateLimited):
+		return err
+	case errors.Is(err, pokemon.ErrInvalidIdentifier):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, api.ErrNotFound), errors.Is(err, pokemon.ErrUnknownFilter):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(code, err.Error())
+	}
+}
@@ -0,0 +1,346 @@
+package grpcapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/grpcapi"
+	"pokedex-clone/pkg/grpcapi/pokedexpb"
+	"pokedex-clone/pkg/health"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/ratelimit"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const testKey = "s3cret"
+
+var mewtwoSpecies = &api.PokemonSpecies{ //nolint:gochecknoglobals // shared fixture
+	ID:          150,
+	Name:        "mewtwo",
+	IsLegendary: true,
+	Habitat:     api.NamedAPIResource{Name: "rare"},
+	FlavorTextEntries: []api.FlavorText{
+		{FlavorText: "It was created by a scientist.", Language: api.NamedAPIResource{Name: "en"}},
+	},
+}
+
+// dial serves a server created with opts on an in-memory listener, and returns a connection to it.
+func dial(t *testing.T, service *pokemon.Service, opts grpcapi.Options) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpcapi.New(service, opts)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func withKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcapi.MetadataKey, key)
+}
+
+func TestGetPokemon(t *testing.T) {
+	mewtwoTypes := &api.Pokemon{
+		ID:    150,
+		Name:  "mewtwo",
+		Types: []api.PokemonType{{Slot: 1, Type: api.NamedAPIResource{Name: "psychic"}}},
+	}
+
+	tests := map[string]struct {
+		name        string
+		translated  bool
+		want        *pokedexpb.Pokemon
+		wantCode    codes.Code
+		expectCalls func(p *mocks.MockPokeAPI, tr *mocks.MockTranslationsAPI)
+	}{
+		"pokemon": {
+			name: "Mewtwo",
+			want: &pokedexpb.Pokemon{
+				Id:          150,
+				Name:        "mewtwo",
+				Description: "It was created by a scientist.",
+				Habitat:     "rare",
+				IsLegendary: true,
+				Types:       []string{"psychic"},
+			},
+			expectCalls: func(p *mocks.MockPokeAPI, _ *mocks.MockTranslationsAPI) {
+				p.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(mewtwoSpecies, nil)
+				p.EXPECT().GetPokemon(gomock.Any(), "150").Return(mewtwoTypes, nil)
+			},
+		},
+		"translated pokemon": {
+			name:       "150",
+			translated: true,
+			want: &pokedexpb.Pokemon{
+				Id:          150,
+				Name:        "mewtwo",
+				Description: "Created by a scientist, it was.",
+				Habitat:     "rare",
+				IsLegendary: true,
+				Types:       []string{"psychic"},
+				Translation: &pokedexpb.Translation{Type: "yoda", Translated: true},
+			},
+			expectCalls: func(p *mocks.MockPokeAPI, tr *mocks.MockTranslationsAPI) {
+				p.EXPECT().GetSpecies(gomock.Any(), "150").Return(mewtwoSpecies, nil)
+				p.EXPECT().GetPokemon(gomock.Any(), "150").Return(mewtwoTypes, nil)
+				tr.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), api.TTypeYoda).Return(
+					&api.TranslateAPIResponse{
+						Success:  api.Success{Total: 1},
+						Contents: api.Contents{Translated: "Created by a scientist, it was."},
+					}, nil)
+			},
+		},
+		"unknown pokemon": {
+			name:     "missingno",
+			wantCode: codes.NotFound,
+			expectCalls: func(p *mocks.MockPokeAPI, _ *mocks.MockTranslationsAPI) {
+				p.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound)
+			},
+		},
+		"invalid name": {
+			name:     "mew two!",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			if tt.expectCalls != nil {
+				tt.expectCalls(mockPokeAPI, mockTranslationsAPI)
+			}
+			service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+			client := pokedexpb.NewPokedexClient(dial(t, service, grpcapi.Options{}))
+
+			get := client.GetPokemon
+			if tt.translated {
+				get = client.GetTranslatedPokemon
+			}
+			got, err := get(context.Background(), &pokedexpb.GetPokemonRequest{Name: tt.name})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.want != nil {
+				assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			}
+		})
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(mewtwoSpecies, nil)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound)
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(nil, errors.New("unavailable"))
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+	client := pokedexpb.NewPokedexClient(dial(t, service, grpcapi.Options{}))
+
+	res, err := client.BatchGet(context.Background(), &pokedexpb.BatchGetRequest{
+		Names: []string{"mewtwo", "missingno", "mew two!"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, res.GetResults(), 3)
+
+	assert.Equal(t, "mewtwo", res.GetResults()[0].GetName())
+	assert.Equal(t, "mewtwo", res.GetResults()[0].GetPokemon().GetName())
+	assert.Empty(t, res.GetResults()[0].GetPokemon().GetTypes())
+	assert.Equal(t, int32(codes.NotFound), res.GetResults()[1].GetError().GetCode())
+	assert.Equal(t, int32(codes.InvalidArgument), res.GetResults()[2].GetError().GetCode())
+
+	names := make([]string, grpcapi.MaxBatchSize+1)
+	_, err = client.BatchGet(context.Background(), &pokedexpb.BatchGetRequest{Names: names})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListPokemon(t *testing.T) {
+	// two pages of species are streamed, the species list being fetched a page at a time
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	for _, offset := range []int{0, 100} {
+		results := make([]api.NamedAPIResource, 0, 100)
+		for id := offset + 1; id <= offset+100 && id <= 150; id++ {
+			results = append(results, api.NamedAPIResource{
+				Name: fmt.Sprintf("species-%d", id),
+				URL:  fmt.Sprintf("https://pokeapi.co/api/v2/pokemon-species/%d/", id),
+			})
+		}
+		mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), offset, 100).Return(
+			&api.NamedAPIResourceList{Count: 150, Results: results}, nil)
+	}
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+	client := pokedexpb.NewPokedexClient(dial(t, service, grpcapi.Options{}))
+
+	stream, err := client.ListPokemon(context.Background(), &pokedexpb.ListPokemonRequest{})
+	assert.Nil(t, err)
+
+	var items []*pokedexpb.PokemonListItem
+	for {
+		item, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		if !assert.Nil(t, recvErr) {
+			return
+		}
+		items = append(items, item)
+	}
+
+	assert.Len(t, items, 150)
+	assert.Equal(t, int32(1), items[0].GetId())
+	assert.Equal(t, "species-150", items[149].GetName())
+}
+
+func TestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(mewtwoSpecies, nil).AnyTimes()
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(&api.Pokemon{}, nil).AnyTimes()
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	keyring := apikey.NewKeyring(storage.NewStore())
+	keyring.SetRequired(true)
+	assert.Nil(t, keyring.SetConfigured([]string{"tests:" + testKey}))
+	conn := dial(t, service, grpcapi.Options{Keyring: keyring})
+	client := pokedexpb.NewPokedexClient(conn)
+
+	tests := map[string]struct {
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		"valid key":   {ctx: withKey(context.Background(), testKey), wantCode: codes.OK},
+		"missing key": {ctx: context.Background(), wantCode: codes.Unauthenticated},
+		"invalid key": {ctx: withKey(context.Background(), "wrong"), wantCode: codes.Unauthenticated},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetPokemon(tt.ctx, &pokedexpb.GetPokemonRequest{Name: "mewtwo"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.wantCode == codes.Unauthenticated {
+				stream, streamErr := client.ListPokemon(tt.ctx, &pokedexpb.ListPokemonRequest{})
+				if streamErr == nil {
+					_, streamErr = stream.Recv()
+				}
+				assert.Equal(t, tt.wantCode, status.Code(streamErr))
+			}
+		})
+	}
+
+	// health checks don't need a key
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+}
+
+func TestHealth(t *testing.T) {
+	probes := health.New(time.Second)
+	conn := dial(t, pokemon.NewService(storage.NewStore(), nil, nil), grpcapi.Options{Health: probes})
+	client := healthpb.NewHealthClient(conn)
+
+	for _, service := range []string{"", "pokedex.v1.Pokedex"} {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		assert.Nil(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus(), service)
+	}
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	probes.Drain()
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+}
+
+func TestReflection(t *testing.T) {
+	conn := dial(t, pokemon.NewService(storage.NewStore(), nil, nil), grpcapi.Options{Reflection: true})
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	res, err := stream.Recv()
+	assert.Nil(t, err)
+
+	var services []string
+	for _, service := range res.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "pokedex.v1.Pokedex")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestRateLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).Return(mewtwoSpecies, nil).AnyTimes()
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(&api.Pokemon{}, nil).AnyTimes()
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), gomock.Any()).Return(
+		&api.TranslateAPIResponse{}, nil).AnyTimes()
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"tests:" + testKey}))
+	keyID := func(*gin.Context) string { return "" }
+	client := pokedexpb.NewPokedexClient(dial(t, service, grpcapi.Options{
+		Keyring:           keyring,
+		KeyLimiter:        ratelimit.NewKeyed(0.001, 2, keyID),
+		TranslatedLimiter: ratelimit.NewKeyed(0.001, 1, keyID),
+	}))
+
+	// a batch takes a translation token for every name, the names beyond the limit fail on their own
+	res, err := client.BatchGet(withKey(context.Background(), testKey), &pokedexpb.BatchGetRequest{
+		Names:      []string{"mewtwo", "mewtwo"},
+		Translated: true,
+	})
+	assert.Nil(t, err)
+	codesByResult := []int32{res.GetResults()[0].GetError().GetCode(), res.GetResults()[1].GetError().GetCode()}
+	assert.ElementsMatch(t, []int32{int32(codes.OK), int32(codes.ResourceExhausted)}, codesByResult)
+
+	_, err = client.GetTranslatedPokemon(withKey(context.Background(), testKey), &pokedexpb.GetPokemonRequest{
+		Name: "mewtwo",
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the key is out of tokens, anonymous clients have a bucket of their own
+	_, err = client.GetPokemon(withKey(context.Background(), testKey), &pokedexpb.GetPokemonRequest{Name: "mewtwo"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = client.GetPokemon(context.Background(), &pokedexpb.GetPokemonRequest{Name: "mewtwo"})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,133 @@
+// Health package provides the liveness and readiness probes of the service. Readiness runs the
+// registered dependency checks, such as the storage backend or the upstream circuit breakers, and
+// fails as soon as the server starts shutting down so load balancers stop sending requests before
+// in-flight ones are drained.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check reports whether a dependency can serve requests.
+type Check func(ctx context.Context) error
+
+// Status is the body of the probe responses, Checks maps every check name to "ok" or its error.
+type Status struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+const (
+	StatusOK           = "ok"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting down"
+)
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health holds the readiness checks, which must be added before the probes are served.
+type Health struct {
+	// Timeout bounds the time every readiness check gets.
+	Timeout time.Duration
+
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// New returns a Health without checks, ready until Drain is called.
+func New(timeout time.Duration) *Health {
+	return &Health{Timeout: timeout}
+}
+
+// AddCheck registers check under name for the readiness probe.
+func (h *Health) AddCheck(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the readiness probe fail from now on, it's called when the server starts shutting down.
+func (h *Health) Drain() {
+	h.shuttingDown.Store(true)
+}
+
+// Live is the liveness probe, it responds 200 as long as the server serves requests.
+func (h *Health) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, Status{Status: StatusOK})
+}
+
+// Ready is the readiness probe, it responds 200 when every check passes and 503 otherwise, or
+// once Drain was called.
+func (h *Health) Ready(c *gin.Context) {
+	status := h.Readiness(c.Request.Context())
+
+	code := http.StatusOK
+	if status.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, status)
+}
+
+// Readiness runs the checks, it reports StatusOK when every check passes, StatusUnavailable otherwise
+// and StatusShuttingDown without running them once Drain was called.
+func (h *Health) Readiness(ctx context.Context) Status {
+	if h.shuttingDown.Load() {
+		return Status{Status: StatusShuttingDown}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	status := Status{Status: StatusOK, Checks: make(map[string]string, len(h.checks))}
+	results := make([]error, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, nc := range h.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, nc.check)
+	}
+	wg.Wait()
+
+	for i, nc := range h.checks {
+		status.Checks[nc.name] = StatusOK
+		if results[i] != nil {
+			status.Status = StatusUnavailable
+			status.Checks[nc.name] = results[i].Error()
+		}
+	}
+
+	return status
+}
+
+// Cached returns a check running check at most once every ttl, reporting the last result in between.
+// It keeps expensive checks, such as upstream probes, from running on every readiness probe.
+func Cached(check Check, ttl time.Duration) Check {
+	var (
+		mu      sync.Mutex
+		lastRun time.Time
+		lastErr error
+	)
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !lastRun.IsZero() && time.Since(lastRun) < ttl {
+			return lastErr
+		}
+		lastErr = check(ctx)
+		lastRun = time.Now()
+
+		return lastErr
+	}
+}
@@ -0,0 +1,152 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/health"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(t *testing.T, h *health.Health, path string) (int, health.Status) {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	assert.Nil(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var status health.Status
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &status))
+
+	return rr.Code, status
+}
+
+func TestReady(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := map[string]struct {
+		checks     map[string]health.Check
+		drain      bool
+		wantStatus int
+		want       health.Status
+	}{
+		"ready without checks": {
+			wantStatus: http.StatusOK,
+			want:       health.Status{Status: health.StatusOK},
+		},
+		"ready when every check passes": {
+			checks:     map[string]health.Check{"storage": ok, "pokeapi": ok},
+			wantStatus: http.StatusOK,
+			want:       health.Status{Status: health.StatusOK, Checks: map[string]string{"storage": "ok", "pokeapi": "ok"}},
+		},
+		"unavailable when a check fails": {
+			checks:     map[string]health.Check{"storage": ok, "pokeapi": failing},
+			wantStatus: http.StatusServiceUnavailable,
+			want: health.Status{
+				Status: health.StatusUnavailable,
+				Checks: map[string]string{"storage": "ok", "pokeapi": "connection refused"},
+			},
+		},
+		"unavailable when a check times out": {
+			checks:     map[string]health.Check{"pokeapi": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			want: health.Status{
+				Status: health.StatusUnavailable,
+				Checks: map[string]string{"pokeapi": context.DeadlineExceeded.Error()},
+			},
+		},
+		"shutting down once drained, even when checks pass": {
+			checks:     map[string]health.Check{"storage": ok},
+			drain:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			want:       health.Status{Status: health.StatusShuttingDown},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := health.New(10 * time.Millisecond)
+			for name, check := range tt.checks {
+				h.AddCheck(name, check)
+			}
+			if tt.drain {
+				h.Drain()
+			}
+
+			code, status := probe(t, h, "/readyz")
+			assert.Equal(t, tt.wantStatus, code)
+			assert.Equal(t, tt.want, status)
+
+			// liveness doesn't depend on the checks nor on draining
+			code, status = probe(t, h, "/healthz")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, health.Status{Status: health.StatusOK}, status)
+		})
+	}
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := health.Cached(func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, 20*time.Millisecond)
+
+	assert.NotNil(t, check(context.Background()))
+	assert.NotNil(t, check(context.Background()), "the failure is reused within the ttl")
+	assert.Equal(t, 1, calls)
+
+	time.Sleep(25 * time.Millisecond)
+	assert.Nil(t, check(context.Background()))
+	assert.Equal(t, 2, calls)
+}
+
+func TestBreakerCheck(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	client := api.NewClient(upstream.URL+"/", time.Second)
+	client.Name = "pokeapi"
+	client.Breaker = api.NewBreaker(2, 20*time.Millisecond)
+	h := health.New(time.Second)
+	h.AddCheck("pokeapi_breaker", client.Breaker.Check)
+
+	for i := 0; i < 2; i++ {
+		code, _ := probe(t, h, "/readyz")
+		assert.Equal(t, http.StatusOK, code, "the breaker is closed until the second failure")
+		_, err := api.Poke{Client: client}.GetSpecies(context.Background(), "mewtwo")
+		assert.NotNil(t, err)
+	}
+
+	code, status := probe(t, h, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, api.ErrBreakerOpen.Error(), status.Checks["pokeapi_breaker"])
+
+	// once the cooldown is over the breaker lets a call through, and the service is ready to send it
+	time.Sleep(25 * time.Millisecond)
+	code, _ = probe(t, h, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, api.BreakerHalfOpen, client.Breaker.State())
+}
@@ -0,0 +1,188 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"pokedex-clone/pkg/pokemon"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// EventResult is the event of every result of a stream, which ends with the event named after the state
+	// the job finished in.
+	EventResult = "result"
+	// LastEventIDHeader carries the ID of the last event a reconnecting stream received.
+	LastEventIDHeader = "Last-Event-ID"
+)
+
+// Request is the body of a job creation, the names are species names or national dex numbers, up to MaxNames.
+type Request struct {
+	Names []string `json:"names" binding:"required,min=1,max=1000"`
+}
+
+// Register adds the job routes to routes:
+//
+//	POST   /jobs/translate   create a job translating a list of pokemon
+//	GET    /jobs/:id         get a job with its results so far
+//	DELETE /jobs/:id         cancel a job
+//	GET    /jobs/:id/events  stream the results of a job as Server-Sent Events
+func (m *Manager) Register(routes gin.IRoutes) {
+	routes.POST(Path+"/translate", m.Create)
+	routes.GET(Path+"/:id", m.Get)
+	routes.DELETE(Path+"/:id", m.Delete)
+	routes.GET(Path+"/:id/events", m.Events)
+}
+
+// Create starts a job translating the names of the request, answering 202 Accepted with the job and its location,
+// or 429 Too Many Requests when the client runs too many jobs already.
+func (m *Manager) Create(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i, name := range req.Names {
+		if _, err := pokemon.ParseIdentifier(name); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("names[%d]: %v", i, err)})
+			return
+		}
+	}
+
+	job, err := m.Submit(m.client(c), req.Names)
+	if errors.Is(err, ErrTooManyJobs) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", Path+"/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// Get returns a job with the results fetched so far.
+func (m *Manager) Get(c *gin.Context) {
+	job, ok := m.Lookup(c.Param("id"), m.client(c))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Delete cancels a job, returning it once cancelled. Finished jobs are answered with 409 Conflict.
+func (m *Manager) Delete(c *gin.Context) {
+	job, err := m.Cancel(c.Param("id"), m.client(c))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%v: %s", err, job.State)})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+type connKey struct{}
+
+// ConnContext is the http.Server ConnContext hook letting the event streams outlive the write timeout of the
+// server, which they lift for their own connection.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// Events streams the results of a job as they are fetched, each as a result event whose ID is the number of
+// results sent so far, and ends with the final state of the job and its Progress. Streams stay open until the
+// job finishes, the server shuts down or the client leaves, reconnecting with the Last-Event-ID header, or the
+// last_event_id query parameter, resumes them after the results already received.
+func (m *Manager) Events(c *gin.Context) {
+	id, client := c.Param("id"), m.client(c)
+	job, ok := m.Lookup(id, client)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound.Error()})
+		return
+	}
+
+	next, err := lastEventID(c, len(job.Names))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the write timeout bounds the writes of the whole response, which a stream spreads over the job
+	if conn, isConn := c.Request.Context().Value(connKey{}).(net.Conn); isConn {
+		if err = conn.SetWriteDeadline(time.Time{}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	sse.Event{}.WriteContentType(c.Writer)
+	c.Status(http.StatusOK)
+	for {
+		// watching before loading, so the changes made in between aren't missed
+		changed := m.watch(id)
+		if job, ok = m.Lookup(id, client); !ok {
+			return
+		}
+
+		for ; next < len(job.Results); next++ {
+			c.Render(-1, sse.Event{Id: strconv.Itoa(next + 1), Event: EventResult, Data: job.Results[next]})
+		}
+		if job.State.Finished() {
+			c.Render(-1, sse.Event{Event: string(job.State), Data: progress(job)})
+			return
+		}
+		c.Writer.Flush()
+
+		select {
+		case <-changed:
+		case <-m.closing:
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// lastEventID returns the number of results the client already received, out of total.
+func lastEventID(c *gin.Context, total int) (int, error) {
+	raw := c.GetHeader(LastEventIDHeader)
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 || n > total {
+		return 0, fmt.Errorf("last event id must be a number between 0 and %d", total)
+	}
+
+	return n, nil
+}
+
+func progress(job Job) Progress {
+	return Progress{ID: job.ID, State: job.State, Total: len(job.Names), Done: len(job.Results)}
+}
+
+func (m *Manager) client(c *gin.Context) string {
+	if m.opts.Limiter == nil {
+		return ""
+	}
+
+	return m.opts.Limiter.Key(c)
+}
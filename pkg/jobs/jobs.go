@@ -0,0 +1,363 @@
+// Jobs package provides the batch translation jobs, which translate a list of pokemon in the background at the
+// translation rate of the client that created them. Their progress is streamed as Server-Sent Events, and their
+// state is kept in a storage backend, so streams and jobs interrupted midway resume where they stopped.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/random"
+	"pokedex-clone/pkg/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Path is the route prefix of the jobs.
+	Path = "/jobs"
+	// MaxNames is the number of pokemon a job may translate.
+	MaxNames = 1000
+
+	storePrefix = "job/"
+)
+
+// State is where a job is at, only running jobs change.
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateCancelled State = "cancelled"
+)
+
+// Finished tells whether a job in state s is done changing.
+func (s State) Finished() bool {
+	return s != StateRunning
+}
+
+// ErrNotFound is returned for the jobs that don't exist, have expired, or belong to another client.
+var ErrNotFound = errors.New("job not found")
+
+// ErrFinished is returned when cancelling a job that already finished.
+var ErrFinished = errors.New("job already finished")
+
+// ErrTooManyJobs is returned when submitting a job for a client running Options.MaxRunning jobs already.
+var ErrTooManyJobs = errors.New("too many running jobs, wait for one to finish or cancel it")
+
+// Job translates the pokemon of Names in order, Results holding those translated so far.
+type Job struct {
+	ID        string    `json:"id"`
+	State     State     `json:"state"`
+	Names     []string  `json:"names"`
+	Results   []Result  `json:"results"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// client is the key of the client that created the job, see Limiter.
+	client string
+}
+
+// Result is the outcome of translating the pokemon at Index of the names of a job, either Pokemon or Error is set.
+type Result struct {
+	Index   int         `json:"index"`
+	Name    string      `json:"name"`
+	Pokemon *Translated `json:"pokemon,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Translated is a pokemon with its description translated, as served by the v2 translated route.
+type Translated struct {
+	ID          int                      `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Translation *pokemon.TranslationInfo `json:"translation"`
+}
+
+// Progress summarizes a job without its results.
+type Progress struct {
+	ID    string `json:"id"`
+	State State  `json:"state"`
+	Total int    `json:"total"`
+	Done  int    `json:"done"`
+}
+
+// Limiter paces the translations of the jobs of every client.
+type Limiter interface {
+	// Key identifies the client of c, clients with an empty key aren't limited.
+	Key(c *gin.Context) string
+	// Wait blocks until the client identified by key may fetch one more translation, or ctx is done.
+	Wait(ctx context.Context, key string) error
+}
+
+// Options configure the manager returned by New.
+type Options struct {
+	// TTL is how long finished jobs are kept for, they are kept until removed from the store when zero.
+	TTL time.Duration
+	// Limiter paces the translations, and only lets clients see their own jobs. Jobs aren't limited when nil.
+	Limiter Limiter
+	// MaxRunning is how many running jobs a client may have, any number when zero. The clients share the limit
+	// without a Limiter.
+	MaxRunning int
+}
+
+// Manager runs the jobs and serves their routes.
+type Manager struct {
+	service *pokemon.Service
+	store   *storage.Store
+	opts    Options
+	ctx     context.Context
+	// closing is closed by CloseStreams
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// submitting serializes the submissions, so that no client goes over Options.MaxRunning
+	submitting sync.Mutex
+
+	mu   sync.Mutex
+	runs map[string]*run
+}
+
+// run is a job being translated, changed is closed and replaced whenever the job is saved.
+type run struct {
+	client  string
+	cancel  context.CancelFunc
+	changed chan struct{}
+	done    chan struct{}
+}
+
+// New returns a manager translating with service and keeping the jobs in store, apart from the cache whose
+// evictions would drop unfinished jobs. Jobs run until ctx is done, the unfinished jobs already in store resume
+// from their last result.
+func New(ctx context.Context, service *pokemon.Service, store *storage.Store, opts Options) *Manager {
+	m := &Manager{
+		service: service,
+		store:   store,
+		opts:    opts,
+		ctx:     ctx,
+		closing: make(chan struct{}),
+		runs:    make(map[string]*run),
+	}
+
+	for _, meta := range store.List(storePrefix) {
+		if job, ok := m.load(strings.TrimPrefix(meta.Key, storePrefix)); ok && !job.State.Finished() {
+			m.start(job)
+		}
+	}
+
+	return m
+}
+
+// Submit creates a job translating names on behalf of the client identified by client, and starts it. It fails
+// with ErrTooManyJobs when the client runs Options.MaxRunning jobs already.
+func (m *Manager) Submit(client string, names []string) (Job, error) {
+	m.submitting.Lock()
+	defer m.submitting.Unlock()
+	if m.opts.MaxRunning > 0 && m.running(client) >= m.opts.MaxRunning {
+		return Job{}, ErrTooManyJobs
+	}
+
+	id, err := random.Hex(16)
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now()
+	job := Job{
+		ID:        id,
+		State:     StateRunning,
+		Names:     names,
+		Results:   []Result{},
+		CreatedAt: now,
+		UpdatedAt: now,
+		client:    client,
+	}
+	if err = m.save(job); err != nil {
+		return Job{}, err
+	}
+	m.start(job)
+
+	return job, nil
+}
+
+// Lookup returns the job id of the client identified by client.
+func (m *Manager) Lookup(id, client string) (Job, bool) {
+	job, ok := m.load(id)
+	if !ok || job.client != client {
+		return Job{}, false
+	}
+
+	return job, true
+}
+
+// Cancel stops the job id of the client identified by client, returning it once cancelled. The results fetched
+// so far are kept.
+func (m *Manager) Cancel(id, client string) (Job, error) {
+	job, ok := m.Lookup(id, client)
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if job.State.Finished() {
+		return job, ErrFinished
+	}
+
+	m.mu.Lock()
+	r := m.runs[id]
+	m.mu.Unlock()
+
+	if r == nil {
+		// the manager was stopped, nothing translates the job anymore
+		job.State, job.UpdatedAt = StateCancelled, time.Now()
+		return job, m.save(job)
+	}
+
+	r.cancel()
+	<-r.done
+	if job, ok = m.Lookup(id, client); !ok {
+		return Job{}, ErrNotFound
+	}
+	if job.State != StateCancelled {
+		return job, ErrFinished
+	}
+
+	return job, nil
+}
+
+// CloseStreams ends the event streams open and those opened from now on, e.g. for the server to shut down,
+// their clients reconnect to follow the jobs further. The jobs keep running.
+func (m *Manager) CloseStreams() {
+	m.closeOnce.Do(func() { close(m.closing) })
+}
+
+func (m *Manager) start(job Job) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	r := &run{client: job.client, cancel: cancel, changed: make(chan struct{}), done: make(chan struct{})}
+
+	m.mu.Lock()
+	m.runs[job.ID] = r
+	m.mu.Unlock()
+
+	go m.run(ctx, r, job)
+}
+
+// run translates the names of job left, saving it after every result. Jobs stopped along with the manager are
+// left running, for the next manager of the store to resume.
+func (m *Manager) run(ctx context.Context, r *run, job Job) {
+	defer func() {
+		r.cancel()
+		m.mu.Lock()
+		delete(m.runs, job.ID)
+		m.mu.Unlock()
+		close(r.done)
+	}()
+
+	for i := len(job.Results); i < len(job.Names); i++ {
+		var p *pokemon.Pokemon
+		err := m.wait(ctx, job.client)
+		if err == nil {
+			p, err = m.service.FetchTranslated(ctx, job.Names[i])
+		}
+		// what was fetched while cancelling is dropped, resumed jobs fetch it again
+		if ctx.Err() != nil {
+			break
+		}
+
+		job.Results = append(job.Results, newResult(i, job.Names[i], p, err))
+		job.UpdatedAt = time.Now()
+		m.update(r, job)
+	}
+
+	switch {
+	case m.ctx.Err() != nil:
+		return
+	case ctx.Err() != nil:
+		job.State = StateCancelled
+	default:
+		job.State = StateCompleted
+	}
+	job.UpdatedAt = time.Now()
+	m.update(r, job)
+}
+
+func (m *Manager) wait(ctx context.Context, client string) error {
+	if m.opts.Limiter == nil {
+		return ctx.Err()
+	}
+
+	return m.opts.Limiter.Wait(ctx, client)
+}
+
+func newResult(index int, name string, p *pokemon.Pokemon, err error) Result {
+	res := Result{Index: index, Name: name}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.Pokemon = &Translated{ID: p.ID, Name: p.Name, Description: p.Description, Translation: p.Translation}
+
+	return res
+}
+
+// update saves job and wakes up the streams following it.
+func (m *Manager) update(r *run, job Job) {
+	if err := m.save(job); err != nil {
+		// the streams are woken up by the next update, whose save stores these results too
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// running returns how many jobs of the client identified by client are running.
+func (m *Manager) running(client string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, r := range m.runs {
+		if r.client == client {
+			n++
+		}
+	}
+
+	return n
+}
+
+// watch returns a channel closed when the job id changes next, which is nil when it isn't running.
+func (m *Manager) watch(id string) <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.runs[id]; ok {
+		return r.changed
+	}
+
+	return nil
+}
+
+func (m *Manager) load(id string) (Job, bool) {
+	value, ok := m.store.Load(storePrefix + id)
+	if !ok {
+		return Job{}, false
+	}
+	job, ok := value.(Job)
+
+	return job, ok
+}
+
+// save stores a copy of job, so the runs keep appending to their results while streams read the saved ones.
+func (m *Manager) save(job Job) error {
+	job.Results = append(make([]Result, 0, len(job.Results)), job.Results...)
+
+	var ttl time.Duration
+	if job.State.Finished() {
+		ttl = m.opts.TTL
+	}
+
+	return m.store.SaveWithTTL(storePrefix+job.ID, job, ttl)
+}
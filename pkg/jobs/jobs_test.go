@@ -0,0 +1,383 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/jobs"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const clientHeader = "X-Client"
+
+// limiter keys the clients by a header and counts the translations they waited for.
+type limiter struct {
+	waits atomic.Int64
+}
+
+func (l *limiter) Key(c *gin.Context) string {
+	return c.GetHeader(clientHeader)
+}
+
+func (l *limiter) Wait(ctx context.Context, _ string) error {
+	l.waits.Add(1)
+	return ctx.Err()
+}
+
+type event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func newService(t *testing.T) *pokemon.Service {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "pikachu").Return(pikachuSpecies(), nil).AnyTimes()
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "missingno").Return(nil, api.ErrNotFound).AnyTimes()
+	// mew is never fetched, so its jobs run until cancelled
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mew").DoAndReturn(
+		func(ctx context.Context, _ string) (*api.PokemonSpecies, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).AnyTimes()
+
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "pikachu", gomock.Any(), api.TTypeShakespeare).Return(
+		&api.TranslateAPIResponse{
+			Success:  api.Success{Total: 1},
+			Contents: api.Contents{Translated: "Thee electricity couldst buildeth."},
+		}, nil).AnyTimes()
+
+	return pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+}
+
+func pikachuSpecies() *api.PokemonSpecies {
+	return &api.PokemonSpecies{
+		ID:   25,
+		Name: "pikachu",
+		FlavorTextEntries: []api.FlavorText{{
+			FlavorText: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+			Language:   api.NamedAPIResource{Name: "en"},
+			Version:    api.NamedAPIResource{Name: "red"},
+		}},
+		Habitat: api.NamedAPIResource{Name: "forest"},
+	}
+}
+
+func newManager(t *testing.T, store *storage.Store, opts jobs.Options) (*jobs.Manager, *gin.Engine) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	manager := jobs.New(ctx, newService(t), store, opts)
+
+	router := gin.New()
+	manager.Register(router)
+
+	return manager, router
+}
+
+func serve(router *gin.Engine, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func create(t *testing.T, router *gin.Engine, names ...string) jobs.Job {
+	t.Helper()
+
+	body, err := json.Marshal(jobs.Request{Names: names})
+	assert.Nil(t, err)
+	rr := serve(router, http.MethodPost, jobs.Path+"/translate", string(body), nil)
+	assert.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+
+	var job jobs.Job
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &job))
+	assert.Equal(t, jobs.Path+"/"+job.ID, rr.Header().Get("Location"))
+
+	return job
+}
+
+func parseEvents(t *testing.T, body string) []event {
+	t.Helper()
+
+	var events []event
+	for _, block := range strings.Split(body, "\n\n") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		var e event
+		for _, line := range strings.Split(block, "\n") {
+			field, value, _ := strings.Cut(line, ":")
+			switch field {
+			case "id":
+				e.ID = value
+			case "event":
+				e.Event = value
+			case "data":
+				e.Data = value
+			}
+		}
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func TestEvents(t *testing.T) {
+	results := []event{
+		{ID: "1", Event: jobs.EventResult, Data: `{"index": 0, "name": "Pikachu", "pokemon": {
+			"id": 25, "name": "pikachu", "description": "Thee electricity couldst buildeth.",
+			"translation": {"type": "shakespeare", "translated": true}
+		}}`},
+		{ID: "2", Event: jobs.EventResult, Data: `{"index": 1, "name": "missingno", "error": "not found"}`},
+	}
+
+	tests := map[string]struct {
+		header     http.Header
+		query      string
+		wantStatus int
+		wantEvents []event
+	}{
+		"every result": {
+			wantStatus: http.StatusOK,
+			wantEvents: results,
+		},
+		"resumed after the last event id": {
+			header:     http.Header{jobs.LastEventIDHeader: {"1"}},
+			wantStatus: http.StatusOK,
+			wantEvents: results[1:],
+		},
+		"resumed with the query parameter": {
+			query:      "?last_event_id=2",
+			wantStatus: http.StatusOK,
+		},
+		"last event id out of range": {
+			header:     http.Header{jobs.LastEventIDHeader: {"3"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		"job of another client": {
+			header:     http.Header{clientHeader: {"misty"}},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	l := &limiter{}
+	_, router := newManager(t, storage.NewStore(), jobs.Options{Limiter: l})
+	job := create(t, router, "Pikachu", "missingno")
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(router, http.MethodGet, jobs.Path+"/"+job.ID+"/events"+tc.query, "", tc.header)
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			events := parseEvents(t, rr.Body.String())
+			if assert.Len(t, events, len(tc.wantEvents)+1) {
+				for i, want := range tc.wantEvents {
+					assert.Equal(t, want.ID, events[i].ID)
+					assert.Equal(t, want.Event, events[i].Event)
+					assert.JSONEq(t, want.Data, events[i].Data)
+				}
+
+				last := events[len(events)-1]
+				assert.Equal(t, string(jobs.StateCompleted), last.Event)
+				assert.JSONEq(t, `{"id": "`+job.ID+`", "state": "completed", "total": 2, "done": 2}`, last.Data)
+			}
+		})
+	}
+
+	assert.EqualValues(t, 2, l.waits.Load())
+}
+
+func TestCreate(t *testing.T) {
+	tests := map[string]struct {
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		"job": {
+			body:       `{"names": ["pikachu"]}`,
+			wantStatus: http.StatusAccepted,
+		},
+		"no names": {
+			body:       `{"names": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid name": {
+			body:       `{"names": ["pikachu", "pika chu!"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "names[1]: " + pokemon.ErrInvalidIdentifier.Error(),
+		},
+		"malformed body": {
+			body:       `{"names": "pikachu"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	_, router := newManager(t, storage.NewStore(), jobs.Options{})
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(router, http.MethodPost, jobs.Path+"/translate", tc.body, nil)
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantError != "" {
+				assert.JSONEq(t, `{"error": "`+tc.wantError+`"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMaxRunning(t *testing.T) {
+	manager, router := newManager(t, storage.NewStore(), jobs.Options{Limiter: &limiter{}, MaxRunning: 2})
+	ash := http.Header{clientHeader: {"ash"}}
+	// mew is never fetched, its jobs keep running
+	var running []jobs.Job
+	for i := 0; i < 2; i++ {
+		rr := serve(router, http.MethodPost, jobs.Path+"/translate", `{"names": ["mew"]}`, ash)
+		assert.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+		var job jobs.Job
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &job))
+		running = append(running, job)
+	}
+
+	rr := serve(router, http.MethodPost, jobs.Path+"/translate", `{"names": ["pikachu"]}`, ash)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.JSONEq(t, `{"error": "`+jobs.ErrTooManyJobs.Error()+`"}`, rr.Body.String())
+	// every client has a limit of its own
+	rr = serve(router, http.MethodPost, jobs.Path+"/translate", `{"names": ["pikachu"]}`,
+		http.Header{clientHeader: {"misty"}})
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	// a cancelled job makes room for another
+	_, err := manager.Cancel(running[0].ID, "ash")
+	assert.Nil(t, err)
+	rr = serve(router, http.MethodPost, jobs.Path+"/translate", `{"names": ["pikachu"]}`, ash)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+}
+
+func TestCancel(t *testing.T) {
+	manager, router := newManager(t, storage.NewStore(), jobs.Options{})
+	job := create(t, router, "pikachu", "mew")
+	target := jobs.Path + "/" + job.ID
+
+	// mew is being fetched once pikachu is
+	assert.Eventually(t, func() bool {
+		job, _ = manager.Lookup(job.ID, "")
+		return len(job.Results) == 1
+	}, time.Second, time.Millisecond)
+
+	rr := serve(router, http.MethodDelete, target, "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var cancelled jobs.Job
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &cancelled))
+	assert.Equal(t, jobs.StateCancelled, cancelled.State)
+	assert.Len(t, cancelled.Results, 1)
+
+	rr = serve(router, http.MethodDelete, target, "", nil)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.JSONEq(t, `{"error": "job already finished: cancelled"}`, rr.Body.String())
+
+	events := parseEvents(t, serve(router, http.MethodGet, target+"/events?last_event_id=1", "", nil).Body.String())
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, string(jobs.StateCancelled), events[0].Event)
+	}
+
+	rr = serve(router, http.MethodDelete, jobs.Path+"/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestStreamOutlivesWriteTimeout(t *testing.T) {
+	manager, router := newManager(t, storage.NewStore(), jobs.Options{})
+	job := create(t, router, "mew")
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 20 * time.Millisecond
+	server.Config.ConnContext = jobs.ConnContext
+	server.Start()
+	defer server.Close()
+
+	time.AfterFunc(100*time.Millisecond, func() { _, _ = manager.Cancel(job.ID, "") })
+	res, err := http.Get(server.URL + jobs.Path + "/" + job.ID + "/events")
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	assert.Nil(t, err)
+
+	events := parseEvents(t, string(body))
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, string(jobs.StateCancelled), events[0].Event)
+	}
+}
+
+func TestCloseStreams(t *testing.T) {
+	manager, router := newManager(t, storage.NewStore(), jobs.Options{})
+	job := create(t, router, "mew")
+
+	time.AfterFunc(20*time.Millisecond, manager.CloseStreams)
+	rr := serve(router, http.MethodGet, jobs.Path+"/"+job.ID+"/events", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Empty(t, parseEvents(t, rr.Body.String()))
+
+	rr = serve(router, http.MethodGet, jobs.Path+"/"+job.ID, "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"state":"running"`)
+}
+
+func TestResume(t *testing.T) {
+	store := storage.NewStore()
+
+	// the first manager stops while fetching mew
+	ctx, stop := context.WithCancel(context.Background())
+	stopped := jobs.New(ctx, newService(t), store, jobs.Options{})
+	job, err := stopped.Submit("", []string{"pikachu", "mew"})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		job, _ = stopped.Lookup(job.ID, "")
+		return len(job.Results) == 1
+	}, time.Second, time.Millisecond)
+	stop()
+
+	// the next one fetches mew again, and only mew
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mew").Return(nil, errors.New("not found")).Times(1)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+	resumed := jobs.New(context.Background(), service, store, jobs.Options{})
+
+	assert.Eventually(t, func() bool {
+		job, _ = resumed.Lookup(job.ID, "")
+		return job.State == jobs.StateCompleted
+	}, time.Second, time.Millisecond)
+	if assert.Len(t, job.Results, 2) {
+		assert.NotNil(t, job.Results[0].Pokemon)
+		assert.Equal(t, "not found", job.Results[1].Error)
+	}
+}
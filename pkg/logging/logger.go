@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// Formats of the lines New writes.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Redacted replaces the value of redacted attributes.
+const Redacted = "<redacted>"
+
+// New returns a logger writing lines in format to w, at the level set with SetLevel. Attributes whose
+// key is in redact, ignoring case, are logged as Redacted. Lines logged with a context carrying a
+// request ID get a request_id attribute.
+func New(w io.Writer, format string, redact []string) (*slog.Logger, error) {
+	redacted := make(map[string]bool, len(redact))
+	for _, key := range redact {
+		redacted[strings.ToLower(key)] = true
+	}
+
+	opts := slog.HandlerOptions{
+		Level: processLevel{},
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if redacted[strings.ToLower(a.Key)] {
+				return slog.String(a.Key, Redacted)
+			}
+			return a
+		},
+	}
+
+	var handler slog.Handler
+	switch format {
+	case FormatText:
+		handler = opts.NewTextHandler(w)
+	case FormatJSON:
+		handler = opts.NewJSONHandler(w)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return slog.New(requestIDHandler{handler}), nil
+}
+
+// requestIDHandler adds the request ID of the context to every record.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,98 @@
+// Logging package provides structured, leveled logging built on slog, along with the request IDs
+// tying log lines to the request they were written for. The level can be changed while the service
+// is running, messages below it are dropped.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/exp/slog"
+)
+
+// Level orders messages by severity, the zero Level is Info.
+type Level int32
+
+const (
+	Debug Level = iota - 1
+	Info
+	Warn
+	Error
+)
+
+// level is process wide, shared by every logger New returns and the package functions.
+var level atomic.Int32 //nolint:gochecknoglobals // shared by every logger of the process
+
+// ParseLevel returns the level named debug, info, warn or error.
+func ParseLevel(name string) (Level, error) {
+	for l := Debug; l <= Error; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+
+	return Info, fmt.Errorf("unknown log level %q", name)
+}
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int32(l))
+	}
+}
+
+// slog returns the slog level of l, slog levels are four apart.
+func (l Level) slog() slog.Level {
+	return slog.Level(l * 4)
+}
+
+// processLevel is the slog.Leveler of the level SetLevel changes.
+type processLevel struct{}
+
+func (processLevel) Level() slog.Level {
+	return Level(level.Load()).slog()
+}
+
+// SetLevel drops messages below l from now on.
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+// Enabled reports whether messages at l are logged.
+func Enabled(l Level) bool {
+	return int32(l) >= level.Load()
+}
+
+// Debugf, Infof, Warnf and Errorf log a formatted message with the default slog logger, for code
+// that isn't given a logger of its own.
+func Debugf(format string, args ...interface{}) {
+	output(Debug, format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	output(Info, format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	output(Warn, format, args...)
+}
+
+func Errorf(format string, args ...interface{}) {
+	output(Error, format, args...)
+}
+
+func output(l Level, format string, args ...interface{}) {
+	if Enabled(l) {
+		slog.Default().Log(context.Background(), l.slog(), fmt.Sprintf(format, args...))
+	}
+}
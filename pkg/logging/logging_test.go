@@ -0,0 +1,152 @@
+package logging_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/logging"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// lines decodes the JSON log lines written to buf.
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var out []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		out = append(out, line)
+	}
+
+	return out
+}
+
+func TestNew(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+
+	tests := map[string]struct {
+		format string
+		want   []string
+	}{
+		"text lines carry the request ID and redact secrets": {
+			format: logging.FormatText,
+			want:   []string{`msg="upstream call"`, `Authorization=<redacted>`, `api=pokeapi`, `request_id=req-1`},
+		},
+		"json lines carry the request ID and redact secrets": {
+			format: logging.FormatJSON,
+			want:   []string{`"msg":"upstream call"`, `"Authorization":"<redacted>"`, `"api":"pokeapi"`, `"request_id":"req-1"`},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := logging.New(&buf, tt.format, []string{"authorization"})
+			assert.Nil(t, err)
+
+			logger.InfoCtx(ctx, "upstream call", "api", "pokeapi", "Authorization", "Bearer s3cret")
+			logger.DebugCtx(ctx, "dropped below the level")
+
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+			assert.NotContains(t, buf.String(), "s3cret")
+			assert.NotContains(t, buf.String(), "dropped")
+		})
+	}
+
+	_, err := logging.New(&bytes.Buffer{}, "xml", nil)
+	assert.NotNil(t, err)
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	logging.SetLevel(logging.Debug)
+	t.Cleanup(func() { logging.SetLevel(logging.Info) })
+
+	var upstreamID string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamID = r.Header.Get(logging.RequestIDHeader)
+		_, _ = w.Write([]byte(`{"name": "mewtwo"}`))
+	}))
+	defer upstream.Close()
+
+	tests := map[string]struct {
+		header  string
+		wantNew bool
+	}{
+		"the X-Request-ID header is kept": {
+			header: "3f2a-client.id:1",
+		},
+		"a request ID is generated when there is none": {
+			wantNew: true,
+		},
+		"a request ID unsafe to log is replaced": {
+			header:  "forged\nlevel=ERROR",
+			wantNew: true,
+		},
+		"a request ID longer than 128 characters is replaced": {
+			header:  strings.Repeat("a", 129),
+			wantNew: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := logging.New(&buf, logging.FormatJSON, nil)
+			assert.Nil(t, err)
+
+			client := api.NewClient(upstream.URL+"/", time.Second)
+			client.Name = "pokeapi"
+			client.Logger = logger
+
+			router := gin.New()
+			router.Use(logging.Middleware(logger))
+			router.GET("/pokemon/:name", func(c *gin.Context) {
+				_, err := api.Poke{Client: client}.GetSpecies(c.Request.Context(), c.Param("name"))
+				assert.Nil(t, err)
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/pokemon/mewtwo", nil)
+			assert.Nil(t, err)
+			if tt.header != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			id := rr.Header().Get(logging.RequestIDHeader)
+			if tt.wantNew {
+				assert.Len(t, id, 32)
+				assert.NotEqual(t, tt.header, id)
+			} else {
+				assert.Equal(t, tt.header, id)
+			}
+			assert.Equal(t, id, upstreamID)
+
+			logged := lines(t, &buf)
+			if assert.Len(t, logged, 2) {
+				assert.Equal(t, "upstream call", logged[0]["msg"])
+				assert.Equal(t, "DEBUG", logged[0]["level"])
+				assert.Equal(t, "request", logged[1]["msg"])
+				assert.Equal(t, "/pokemon/:name", logged[1]["route"])
+				assert.Equal(t, float64(http.StatusOK), logged[1]["status"])
+				for _, line := range logged {
+					assert.Equal(t, id, line[logging.RequestIDKey])
+				}
+			}
+		})
+	}
+}
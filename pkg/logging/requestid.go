@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// RequestIDHeader carries the request ID, both in requests and in responses.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the attribute key of the request ID in log lines.
+const RequestIDKey = "request_id"
+
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID of 32 hex digits.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports whether id is short and made of characters safe to log and echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Middleware gives every request a request ID, taken from its X-Request-ID header or generated, and
+// logs the request once served. The ID is set in the request context and echoed in the response.
+func Middleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		lvl := Info
+		if status >= http.StatusInternalServerError {
+			lvl = Error
+		}
+
+		logger.LogAttrs(c.Request.Context(), lvl.slog(), "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(started)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+		for _, err := range c.Errors {
+			logger.ErrorCtx(c.Request.Context(), "request failed", "error", err.Err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Metrics package provides the Prometheus metrics of the service: a gin middleware and gRPC
+// interceptors for the served requests, an api.Client hook for upstream calls and collectors for
+// the cache and the upstream circuit breakers.
+package metrics
+
+import (
+	"context"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/storage"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "pokedex"
+
+// QuotaHeader is the header upstream APIs report their remaining quota in.
+const QuotaHeader = "X-RateLimit-Remaining"
+
+// Metrics holds the collectors of the service in their own registry.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	calls            *prometheus.CounterVec
+	callDuration     *prometheus.HistogramVec
+	upstreamDuration *prometheus.HistogramVec
+	upstreamErrors   *prometheus.CounterVec
+	upstreamQuota    *prometheus.GaugeVec
+}
+
+// New registers the service collectors, along with the Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests served, by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the requests served, by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "gRPC calls served, by method and status code.",
+		}, []string{"method", "code"}),
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of the gRPC calls served, by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Latency of upstream calls, by API and operation, e.g. pokemon-species or the translation type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"api", "operation"}),
+		upstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_errors_total",
+			Help:      "Failed upstream calls, by API and operation.",
+		}, []string{"api", "operation"}),
+		upstreamQuota: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "upstream_quota_remaining",
+			Help:      "Calls left in the upstream quota, as last reported by the API.",
+		}, []string{"api"}),
+	}
+
+	m.Registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.calls,
+		m.callDuration,
+		m.upstreamDuration,
+		m.upstreamErrors,
+		m.upstreamQuota,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handle is the gin middleware recording every request. Requests matching no route are
+// recorded under the "unmatched" route, to keep the number of series bounded.
+func (m *Metrics) Handle(c *gin.Context) {
+	started := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(c.Writer.Status())
+
+	m.requests.WithLabelValues(route, c.Request.Method, status).Inc()
+	m.requestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(started).Seconds())
+}
+
+// HandleUnary is the gRPC interceptor recording every unary call.
+func (m *Metrics) HandleUnary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	started := time.Now()
+	res, err := handler(ctx, req)
+	m.observeGRPC(info.FullMethod, err, started)
+
+	return res, err
+}
+
+// HandleStream is the gRPC interceptor recording every streaming call, once it ends.
+func (m *Metrics) HandleStream(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	started := time.Now()
+	err := handler(srv, ss)
+	m.observeGRPC(info.FullMethod, err, started)
+
+	return err
+}
+
+func (m *Metrics) observeGRPC(method string, err error, started time.Time) {
+	code := status.Code(err).String()
+	m.calls.WithLabelValues(method, code).Inc()
+	m.callDuration.WithLabelValues(method, code).Observe(time.Since(started).Seconds())
+}
+
+// ObserveCall is the api.Client hook recording upstream calls.
+func (m *Metrics) ObserveCall(call api.Call) {
+	m.upstreamDuration.WithLabelValues(call.API, call.Operation).Observe(call.Duration.Seconds())
+
+	if call.Err != nil {
+		m.upstreamErrors.WithLabelValues(call.API, call.Operation).Inc()
+	}
+
+	if remaining, err := strconv.ParseFloat(call.Header.Get(QuotaHeader), 64); err == nil {
+		m.upstreamQuota.WithLabelValues(call.API).Set(remaining)
+	}
+}
+
+// RegisterStore exposes the lookup, eviction and size counters of store.
+func (m *Metrics) RegisterStore(store *storage.Store) error {
+	counter := func(name, help string, value func(s storage.Stats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(value(store.Stats())) })
+	}
+
+	for _, c := range []prometheus.Collector{
+		counter("hits_total", "Cache lookups that found a value.",
+			func(s storage.Stats) uint64 { return s.Hits }),
+		counter("misses_total", "Cache lookups that found no value, or an expired one.",
+			func(s storage.Stats) uint64 { return s.Misses }),
+		counter("evictions_total", "Expired values removed from the cache.",
+			func(s storage.Stats) uint64 { return s.Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "entries",
+			Help:      "Values held by the cache, including expired ones not evicted yet.",
+		}, func() float64 { return float64(store.Stats().Entries) }),
+	} {
+		if err := m.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterBreaker exposes the state of the circuit breaker of the upstream API named name.
+func (m *Metrics) RegisterBreaker(name string, breaker *api.Breaker) error {
+	return m.Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "upstream_breaker_state",
+		Help:        "State of the upstream circuit breaker: 0 closed, 1 half-open, 2 open.",
+		ConstLabels: prometheus.Labels{"api": name},
+	}, func() float64 { return float64(breaker.State()) }))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,130 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/metrics"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrape(t *testing.T, router *gin.Engine) string {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	assert.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	return rr.Body.String()
+}
+
+func TestRequestAndCacheMetrics(t *testing.T) {
+	m := metrics.New()
+	store := storage.NewStore()
+	assert.Nil(t, m.RegisterStore(store))
+
+	assert.Nil(t, store.SaveWithTTL("pikachu", "cached", time.Nanosecond))
+	assert.Nil(t, store.Save("mewtwo", "cached"))
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, 1, store.EvictExpired())
+
+	router := gin.Default()
+	router.Use(m.Handle)
+	router.GET("/metrics", m.Handler())
+	router.GET("/pokemon/:name", func(c *gin.Context) {
+		if _, ok := store.Load(c.Param("name")); !ok {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/pokemon/mewtwo", "/pokemon/mewtwo", "/pokemon/pikachu", "/unknown"} {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	body := scrape(t, router)
+	for _, want := range []string{
+		`pokedex_http_requests_total{method="GET",route="/pokemon/:name",status="200"} 2`,
+		`pokedex_http_requests_total{method="GET",route="/pokemon/:name",status="404"} 1`,
+		`pokedex_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`pokedex_http_request_duration_seconds_count{method="GET",route="/pokemon/:name",status="200"} 2`,
+		`pokedex_cache_hits_total 2`,
+		`pokedex_cache_misses_total 1`,
+		`pokedex_cache_evictions_total 1`,
+		`pokedex_cache_entries 1`,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
+
+func TestUpstreamMetrics(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(metrics.QuotaHeader, "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Too Many Requests"}}`))
+	}))
+	defer upstream.Close()
+
+	m := metrics.New()
+	client := api.NewClient(upstream.URL+"/", time.Second)
+	client.Name = "funtranslations"
+	client.AddHook(m.ObserveCall)
+	client.Breaker = api.NewBreaker(1, time.Minute)
+	assert.Nil(t, m.RegisterBreaker(client.Name, client.Breaker))
+
+	_, err := api.Translations{Client: client}.GetTranslation(context.Background(), "mewtwo", "text", api.TTypeYoda)
+	assert.NotNil(t, err)
+	// the breaker opened, so the next call isn't sent
+	_, err = api.Translations{Client: client}.GetTranslation(context.Background(), "mewtwo", "text", api.TTypeYoda)
+	assert.ErrorIs(t, err, api.ErrBreakerOpen)
+
+	router := gin.Default()
+	router.GET("/metrics", m.Handler())
+
+	body := scrape(t, router)
+	for _, want := range []string{
+		`pokedex_upstream_request_duration_seconds_count{api="funtranslations",operation="yoda"} 2`,
+		`pokedex_upstream_errors_total{api="funtranslations",operation="yoda"} 2`,
+		`pokedex_upstream_quota_remaining{api="funtranslations"} 0`,
+		`pokedex_upstream_breaker_state{api="funtranslations"} 2`,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
+
+func TestGRPCMetrics(t *testing.T) {
+	m := metrics.New()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pokedex.v1.Pokedex/GetPokemon"}
+	for _, err := range []error{nil, status.Error(codes.NotFound, "not found")} {
+		_, _ = m.HandleUnary(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+
+	router := gin.Default()
+	router.GET("/metrics", m.Handler())
+
+	body := scrape(t, router)
+	for _, want := range []string{
+		`pokedex_grpc_requests_total{code="OK",method="/pokedex.v1.Pokedex/GetPokemon"} 1`,
+		`pokedex_grpc_requests_total{code="NotFound",method="/pokedex.v1.Pokedex/GetPokemon"} 1`,
+		`pokedex_grpc_request_duration_seconds_count{code="OK",method="/pokedex.v1.Pokedex/GetPokemon"} 1`,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
@@ -0,0 +1,154 @@
+// Openapi package provides the OpenAPI 3 document of the service, served as JSON at /openapi.json, and the
+// Swagger UI browsing it at /docs.
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SpecPath is the route serving the document.
+	SpecPath = "/openapi.json"
+	// DocsPath is the route serving the Swagger UI.
+	DocsPath = "/docs"
+
+	// v1Prefix is the prefix of the paths the unversioned routes serve as well.
+	v1Prefix = "/v1"
+	// initializer is the Swagger UI script picking the document to browse, the UI serves its own petstore one.
+	initializer = "/swagger-initializer.js"
+)
+
+//go:embed openapi.yaml docs/swagger-initializer.js
+var files embed.FS //nolint:gochecknoglobals // embedded files must be package variables
+
+// Operation is a method and path of the document, the path parameters written as {name}.
+type Operation struct {
+	Method string
+	Path   string
+}
+
+// Docs serves the document and the Swagger UI.
+type Docs struct {
+	spec        map[string]interface{}
+	json        []byte
+	initializer []byte
+}
+
+// New loads the embedded document, adding the unversioned paths serving v1.
+func New() (*Docs, error) {
+	raw, err := files.ReadFile("openapi.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var spec map[string]interface{}
+	if err = yaml.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI document: %w", err)
+	}
+	paths, ok := spec["paths"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the OpenAPI document has no paths")
+	}
+	for path, item := range paths {
+		if strings.HasPrefix(path, v1Prefix+"/") {
+			paths[strings.TrimPrefix(path, v1Prefix)] = unversioned(item)
+		}
+	}
+
+	docs := &Docs{spec: spec}
+	if docs.json, err = json.Marshal(spec); err != nil {
+		return nil, fmt.Errorf("failed to marshal the OpenAPI document: %w", err)
+	}
+	if docs.initializer, err = files.ReadFile("docs" + initializer); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// unversioned copies the path item of a v1 path, the operation ids being unique they lose their V1 suffix.
+func unversioned(item interface{}) interface{} {
+	operations, ok := item.(map[string]interface{})
+	if !ok {
+		return item
+	}
+
+	res := make(map[string]interface{}, len(operations))
+	for method, operation := range operations {
+		fields, isOperation := operation.(map[string]interface{})
+		if !isOperation {
+			res[method] = operation
+			continue
+		}
+
+		copied := make(map[string]interface{}, len(fields))
+		for k, v := range fields {
+			copied[k] = v
+		}
+		if id, isString := copied["operationId"].(string); isString {
+			copied["operationId"] = strings.TrimSuffix(id, "V1")
+		}
+		res[method] = copied
+	}
+
+	return res
+}
+
+// Operations returns the operations of the document sorted by path and method.
+func (d *Docs) Operations() []Operation {
+	var res []Operation
+	paths, _ := d.spec["paths"].(map[string]interface{})
+	for path, item := range paths {
+		operations, _ := item.(map[string]interface{})
+		for method := range operations {
+			// path items hold their shared parameters next to the operations
+			if method == "parameters" {
+				continue
+			}
+			res = append(res, Operation{Method: strings.ToUpper(method), Path: path})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Method < res[j].Method
+	})
+
+	return res
+}
+
+// Register adds the routes serving the document and the Swagger UI.
+func (d *Docs) Register(routes gin.IRoutes) {
+	routes.GET(SpecPath, d.Spec)
+	routes.GET(DocsPath, func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, DocsPath+"/")
+	})
+	routes.GET(DocsPath+"/*file", d.UI)
+}
+
+// Spec serves the document as JSON.
+func (d *Docs) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", d.json)
+}
+
+// UI serves the files of the Swagger UI, its initializer pointing at the document.
+func (d *Docs) UI(c *gin.Context) {
+	file := c.Param("file")
+	if file == initializer {
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", d.initializer)
+		return
+	}
+
+	c.FileFromFS(file, swaggerFiles.HTTP)
+}
@@ -0,0 +1,210 @@
+package pokedex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/pokemon"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query filters the entries of GET /users/:id/pokedex, unset filters match every entry.
+type Query struct {
+	Seen     *bool `form:"seen"`
+	Caught   *bool `form:"caught"`
+	Favorite *bool `form:"favorite"`
+}
+
+// EntryRequest is the body of PUT /users/:id/pokedex/:name, caught species are seen too. Notes are up to MaxNotes
+// characters long.
+type EntryRequest struct {
+	Seen     bool   `json:"seen"`
+	Caught   bool   `json:"caught"`
+	Favorite bool   `json:"favorite"`
+	Notes    string `json:"notes"`
+}
+
+// Response is the response of GET /users/:id/pokedex, the completion counts every entry, filtered out or not.
+type Response struct {
+	Pokedex
+	Completion Completion `json:"completion"`
+}
+
+// Register adds the pokedex routes to routes, which require an API key, every key has users of its own:
+//
+//	GET    /users/:id/pokedex         get the pokedex of a user with its completion, filtered by state
+//	GET    /users/:id/pokedex/export  export the pokedex of a user as JSON
+//	POST   /users/:id/pokedex/import  replace the pokedex of a user with an export
+//	GET    /users/:id/pokedex/:name   get the entry of a species
+//	PUT    /users/:id/pokedex/:name   set the entry of a species
+//	DELETE /users/:id/pokedex/:name   remove the entry of a species
+func (t *Tracker) Register(routes gin.IRoutes) {
+	routes.GET(Path+"/:id/pokedex", authorize, t.Get)
+	routes.GET(Path+"/:id/pokedex/export", authorize, t.Download)
+	routes.POST(Path+"/:id/pokedex/import", authorize, t.Upload)
+	routes.GET(Path+"/:id/pokedex/:name", authorize, t.GetEntry)
+	routes.PUT(Path+"/:id/pokedex/:name", authorize, t.PutEntry)
+	routes.DELETE(Path+"/:id/pokedex/:name", authorize, t.DeleteEntry)
+}
+
+// Get returns the pokedex of a user, the entries matching the query, and its completion.
+func (t *Tracker) Get(c *gin.Context) {
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dex, err := t.Lookup(t.client(c), c.Param("id"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	completion, err := t.Completion(c.Request.Context(), dex)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	entries := make([]Entry, 0, len(dex.Entries))
+	for _, entry := range dex.Entries {
+		if query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	dex.Entries = entries
+
+	c.JSON(http.StatusOK, Response{Pokedex: dex, Completion: completion})
+}
+
+// GetEntry returns the entry of a species.
+func (t *Tracker) GetEntry(c *gin.Context) {
+	dex, err := t.Lookup(t.client(c), c.Param("id"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+	species, err := t.species(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	for _, entry := range dex.Entries {
+		if entry.ID == species.ID {
+			c.JSON(http.StatusOK, entry)
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "entry " + ErrNotFound.Error()})
+}
+
+// PutEntry sets the entry of a species, replacing the previous one.
+func (t *Tracker) PutEntry(c *gin.Context) {
+	var req EntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len([]rune(req.Notes)) > MaxNotes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("notes are longer than %d characters", MaxNotes)})
+		return
+	}
+
+	entry, err := t.Set(c.Request.Context(), t.client(c), c.Param("id"), c.Param("name"), Entry{
+		Seen:     req.Seen,
+		Caught:   req.Caught,
+		Favorite: req.Favorite,
+		Notes:    req.Notes,
+	})
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteEntry removes the entry of a species.
+func (t *Tracker) DeleteEntry(c *gin.Context) {
+	if err := t.Remove(c.Request.Context(), t.client(c), c.Param("id"), c.Param("name")); err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Download returns the pokedex of a user as an Export, as a file to download.
+func (t *Tracker) Download(c *gin.Context) {
+	export, err := t.Export(t.client(c), c.Param("id"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="pokedex-`+export.User+`.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// Upload replaces the pokedex of a user with the Export of the body, which may be the export of another
+// user, returning the imported pokedex.
+func (t *Tracker) Upload(c *gin.Context) {
+	var export Export
+	if err := c.ShouldBindJSON(&export); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dex, err := t.Import(c.Request.Context(), t.client(c), c.Param("id"), export)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dex)
+}
+
+// authorize is the middleware rejecting the requests without an API key, whose ID keeps the users of every key
+// apart.
+func authorize(c *gin.Context) {
+	if _, ok := apikey.FromContext(c); !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "missing api key, set the " + apikey.Header + " header",
+		})
+		return
+	}
+
+	c.Next()
+}
+
+// client returns the ID of the API key of c, the pokedexes are kept apart per key.
+func (t *Tracker) client(c *gin.Context) string {
+	key, _ := apikey.FromContext(c)
+
+	return key.ID
+}
+
+func (q Query) matches(entry Entry) bool {
+	return (q.Seen == nil || *q.Seen == entry.Seen) &&
+		(q.Caught == nil || *q.Caught == entry.Caught) &&
+		(q.Favorite == nil || *q.Favorite == entry.Favorite)
+}
+
+// abort answers with the status of err.
+func abort(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidUser), errors.Is(err, ErrInvalidImport), errors.Is(err, pokemon.ErrInvalidIdentifier):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrUnknownSpecies):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrIndexUnavailable):
+		status = http.StatusBadGateway
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
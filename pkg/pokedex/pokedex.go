@@ -0,0 +1,359 @@
+// Pokedex package provides the personal pokedex of the users of the service: which species they have seen, caught
+// or marked as favorite, their notes, and how complete their pokedex is per generation and region. Species are
+// validated against the species index, pokedexes are kept in a storage backend and exported or imported as JSON.
+package pokedex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Path is the route prefix of the users.
+	Path = "/users"
+	// ExportVersion is the version of the Export format.
+	ExportVersion = 1
+	// MaxNotes is the length limit of the notes of an entry.
+	MaxNotes = 1000
+
+	storePrefix = "pokedex/"
+)
+
+var (
+	// ErrNotFound is returned for the entries a pokedex doesn't hold.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidUser is returned for user IDs which aren't 1 to 64 letters, digits, dots, dashes or underscores.
+	ErrInvalidUser = errors.New("user id must be 1 to 64 letters, digits, '.', '-' or '_'")
+	// ErrUnknownSpecies is returned for valid identifiers the species index doesn't hold.
+	ErrUnknownSpecies = errors.New("unknown species")
+	// ErrInvalidImport is returned when an imported document or one of its entries is invalid.
+	ErrInvalidImport = errors.New("invalid import")
+	// ErrIndexUnavailable is returned when the species index is empty and can't be populated.
+	ErrIndexUnavailable = errors.New("species index unavailable")
+
+	userPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+// Entry is the state of a species in a pokedex, caught species are seen too.
+type Entry struct {
+	// ID is the national dex number of the species.
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Seen      bool      `json:"seen"`
+	Caught    bool      `json:"caught"`
+	Favorite  bool      `json:"favorite"`
+	Notes     string    `json:"notes,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Pokedex is the pokedex of a user, its entries ordered by national dex number. UpdatedAt is zero until an entry is
+// recorded.
+type Pokedex struct {
+	User      string    `json:"user"`
+	Entries   []Entry   `json:"entries"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Progress counts the species of a generation, a region or the whole index, and how many of them were seen or
+// caught. Percentages are rounded to one decimal.
+type Progress struct {
+	Name          string  `json:"name"`
+	Species       int     `json:"species"`
+	Seen          int     `json:"seen"`
+	Caught        int     `json:"caught"`
+	SeenPercent   float64 `json:"seen_percent"`
+	CaughtPercent float64 `json:"caught_percent"`
+}
+
+// Completion is how complete a pokedex is, per generation and per region, in the order of the national dex. Species
+// of no known generation count in the total only.
+type Completion struct {
+	Total       Progress   `json:"total"`
+	Generations []Progress `json:"generations"`
+	Regions     []Progress `json:"regions"`
+}
+
+// Export is the JSON document a pokedex is exported as and imported from.
+type Export struct {
+	Version    int       `json:"version"`
+	User       string    `json:"user"`
+	ExportedAt time.Time `json:"exported_at"`
+	Entries    []Entry   `json:"entries"`
+}
+
+// Tracker keeps the pokedexes of the users of every client apart: a user ID names a different pokedex for every
+// API key.
+type Tracker struct {
+	index *pokemon.Index
+	store *storage.Store
+
+	// mu serializes the updates, which load a pokedex and save it changed.
+	mu sync.Mutex
+}
+
+// New returns a tracker validating the species with index and keeping the pokedexes in store, which shouldn't be
+// shared with the cache.
+func New(index *pokemon.Index, store *storage.Store) *Tracker {
+	return &Tracker{index: index, store: store}
+}
+
+// Lookup returns the pokedex of user, empty when nothing was recorded yet.
+func (t *Tracker) Lookup(client, user string) (Pokedex, error) {
+	if !userPattern.MatchString(user) {
+		return Pokedex{}, ErrInvalidUser
+	}
+
+	return t.load(client, user), nil
+}
+
+// Set replaces the entry of a species in the pokedex of user, raw is a species name or national dex number.
+func (t *Tracker) Set(ctx context.Context, client, user, raw string, entry Entry) (Entry, error) {
+	if !userPattern.MatchString(user) {
+		return Entry{}, ErrInvalidUser
+	}
+	species, err := t.species(ctx, raw)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.ID, entry.Name = species.ID, species.Name
+	entry.Seen = entry.Seen || entry.Caught
+	entry.UpdatedAt = time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dex := t.load(client, user)
+	dex.Entries = append(remove(dex.Entries, entry.ID), entry)
+
+	return entry, t.save(client, dex)
+}
+
+// Remove removes the entry of a species from the pokedex of user.
+func (t *Tracker) Remove(ctx context.Context, client, user, raw string) error {
+	if !userPattern.MatchString(user) {
+		return ErrInvalidUser
+	}
+	species, err := t.species(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dex := t.load(client, user)
+	entries := remove(dex.Entries, species.ID)
+	if len(entries) == len(dex.Entries) {
+		return fmt.Errorf("entry %w", ErrNotFound)
+	}
+	dex.Entries = entries
+
+	return t.save(client, dex)
+}
+
+// Completion counts the species of the index seen and caught in dex.
+func (t *Tracker) Completion(ctx context.Context, dex Pokedex) (Completion, error) {
+	species, err := t.index.Entries(ctx)
+	if err != nil {
+		return Completion{}, fmt.Errorf("%w: %v", ErrIndexUnavailable, err)
+	}
+
+	recorded := make(map[int]Entry, len(dex.Entries))
+	for _, entry := range dex.Entries {
+		recorded[entry.ID] = entry
+	}
+
+	completion := Completion{Total: Progress{Name: "national"}, Generations: []Progress{}, Regions: []Progress{}}
+	generations, regions := make(map[string]int), make(map[string]int)
+	for _, s := range species {
+		entry := recorded[s.ID]
+		completion.Total.count(entry)
+		if s.Generation != "" {
+			completion.Generations = progressOf(completion.Generations, generations, s.Generation, entry)
+		}
+		if s.Region != "" {
+			completion.Regions = progressOf(completion.Regions, regions, s.Region, entry)
+		}
+	}
+
+	completion.Total.percent()
+	for i := range completion.Generations {
+		completion.Generations[i].percent()
+	}
+	for i := range completion.Regions {
+		completion.Regions[i].percent()
+	}
+
+	return completion, nil
+}
+
+// Import replaces the pokedex of user with the entries of export, every entry is validated before any is saved.
+// Entries may name their species by name or national dex number, when both are set they must match.
+func (t *Tracker) Import(ctx context.Context, client, user string, export Export) (Pokedex, error) {
+	if !userPattern.MatchString(user) {
+		return Pokedex{}, ErrInvalidUser
+	}
+	if export.Version != ExportVersion {
+		return Pokedex{}, fmt.Errorf("%w: version must be %d", ErrInvalidImport, ExportVersion)
+	}
+
+	now := time.Now()
+	dex := Pokedex{User: user, Entries: make([]Entry, 0, len(export.Entries))}
+	imported := make(map[int]int, len(export.Entries))
+	for i, entry := range export.Entries {
+		species, err := t.importedSpecies(ctx, entry)
+		if errors.Is(err, ErrIndexUnavailable) {
+			return Pokedex{}, err
+		}
+		if err != nil {
+			return Pokedex{}, fmt.Errorf("%w: entries[%d]: %v", ErrInvalidImport, i, err)
+		}
+		if first, ok := imported[species.ID]; ok {
+			return Pokedex{}, fmt.Errorf("%w: entries[%d]: %s is entries[%d] already", ErrInvalidImport, i,
+				species.Name, first)
+		}
+		if len([]rune(entry.Notes)) > MaxNotes {
+			return Pokedex{}, fmt.Errorf("%w: entries[%d]: notes are longer than %d characters", ErrInvalidImport, i,
+				MaxNotes)
+		}
+		imported[species.ID] = i
+
+		entry.ID, entry.Name = species.ID, species.Name
+		entry.Seen = entry.Seen || entry.Caught
+		if entry.UpdatedAt.IsZero() {
+			entry.UpdatedAt = now
+		}
+		dex.Entries = append(dex.Entries, entry)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.save(client, dex); err != nil {
+		return Pokedex{}, err
+	}
+
+	return t.load(client, user), nil
+}
+
+// Export returns the pokedex of user as an Export.
+func (t *Tracker) Export(client, user string) (Export, error) {
+	dex, err := t.Lookup(client, user)
+	if err != nil {
+		return Export{}, err
+	}
+
+	return Export{Version: ExportVersion, User: user, ExportedAt: time.Now(), Entries: dex.Entries}, nil
+}
+
+// species resolves raw against the species index, populating the index first if it is empty.
+func (t *Tracker) species(ctx context.Context, raw string) (pokemon.IndexEntry, error) {
+	ident, err := pokemon.ParseIdentifier(raw)
+	if err != nil {
+		return pokemon.IndexEntry{}, err
+	}
+	if _, err = t.index.Entries(ctx); err != nil {
+		return pokemon.IndexEntry{}, fmt.Errorf("%w: %v", ErrIndexUnavailable, err)
+	}
+
+	var species pokemon.IndexEntry
+	var ok bool
+	if ident.Name != "" {
+		species, ok = t.index.Lookup(ident.Name)
+	} else {
+		species, ok = t.index.LookupID(ident.ID)
+	}
+	if !ok {
+		return pokemon.IndexEntry{}, fmt.Errorf("%w %q", ErrUnknownSpecies, raw)
+	}
+
+	return species, nil
+}
+
+// importedSpecies resolves the species of an imported entry, named by its Name, its ID, or both.
+func (t *Tracker) importedSpecies(ctx context.Context, entry Entry) (pokemon.IndexEntry, error) {
+	switch {
+	case entry.Name != "":
+		species, err := t.species(ctx, entry.Name)
+		if err == nil && entry.ID != 0 && entry.ID != species.ID {
+			return pokemon.IndexEntry{}, fmt.Errorf("%s is #%d, not #%d", species.Name, species.ID, entry.ID)
+		}
+		return species, err
+	case entry.ID != 0:
+		return t.species(ctx, strconv.Itoa(entry.ID))
+	default:
+		return pokemon.IndexEntry{}, errors.New("name or id is required")
+	}
+}
+
+func (t *Tracker) load(client, user string) Pokedex {
+	value, ok := t.store.Load(key(client, user))
+	if dex, isPokedex := value.(Pokedex); ok && isPokedex {
+		dex.Entries = append([]Entry(nil), dex.Entries...)
+		return dex
+	}
+
+	return Pokedex{User: user, Entries: []Entry{}}
+}
+
+func (t *Tracker) save(client string, dex Pokedex) error {
+	sort.Slice(dex.Entries, func(i, j int) bool { return dex.Entries[i].ID < dex.Entries[j].ID })
+	dex.UpdatedAt = time.Now()
+
+	return t.store.Save(key(client, dex.User), dex)
+}
+
+func key(client, user string) string {
+	return storePrefix + client + "/" + user
+}
+
+// remove returns entries without the entry of the species id.
+func remove(entries []Entry, id int) []Entry {
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.ID != id {
+			kept = append(kept, entry)
+		}
+	}
+
+	return kept
+}
+
+// progressOf counts entry in the progress named name, appended to progress the first time, positions holds the
+// index of every name in progress.
+func progressOf(progress []Progress, positions map[string]int, name string, entry Entry) []Progress {
+	i, ok := positions[name]
+	if !ok {
+		i = len(progress)
+		positions[name] = i
+		progress = append(progress, Progress{Name: name})
+	}
+	progress[i].count(entry)
+
+	return progress
+}
+
+func (p *Progress) count(entry Entry) {
+	p.Species++
+	if entry.Seen {
+		p.Seen++
+	}
+	if entry.Caught {
+		p.Caught++
+	}
+}
+
+func (p *Progress) percent() {
+	if p.Species == 0 {
+		return
+	}
+	p.SeenPercent = math.Round(float64(p.Seen)*1000/float64(p.Species)) / 10
+	p.CaughtPercent = math.Round(float64(p.Caught)*1000/float64(p.Species)) / 10
+}
@@ -0,0 +1,318 @@
+package pokedex_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/pokedex"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	resource := func(id int, name string) api.NamedAPIResource {
+		return api.NamedAPIResource{Name: name, URL: "https://pokeapi.co/api/v2/pokemon-species/" + strconv.Itoa(id) + "/"}
+	}
+	kanto := []api.NamedAPIResource{resource(1, "bulbasaur"), resource(25, "pikachu"), resource(150, "mewtwo")}
+	johto := []api.NamedAPIResource{resource(152, "chikorita")}
+	// futuremon is of no generation yet
+	species := append(append(append([]api.NamedAPIResource{}, kanto...), johto...), resource(1026, "futuremon"))
+
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).
+		Return(&api.NamedAPIResourceList{Count: len(species), Results: species}, nil).Times(1)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), "1").Return(&api.Generation{
+		ID: 1, Name: "generation-i", MainRegion: api.NamedAPIResource{Name: "kanto"}, PokemonSpecies: kanto,
+	}, nil)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), "2").Return(&api.Generation{
+		ID: 2, Name: "generation-ii", MainRegion: api.NamedAPIResource{Name: "johto"}, PokemonSpecies: johto,
+	}, nil)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), "3").Return(nil, api.ErrNotFound)
+
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"pallet:" + secret("pallet"), "viridian:" + secret("viridian")}))
+
+	tracker := pokedex.New(pokemon.NewIndex(mockPokeAPI), storage.NewStore())
+	router := gin.New()
+	router.Use(keyring.Authenticate)
+	tracker.Register(router)
+
+	return router
+}
+
+func secret(app string) string {
+	return "s3cret-" + app
+}
+
+// serve sends a request with the API key of app, or none when app is empty.
+func serve(router *gin.Engine, method, target, body, app string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if app != "" {
+		req.Header.Set(apikey.Header, secret(app))
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestEntries(t *testing.T) {
+	tests := map[string]struct {
+		target     string
+		body       string
+		wantStatus int
+		wantEntry  string
+		wantError  string
+	}{
+		"caught species": {
+			target:     "/users/ash/pokedex/Pikachu",
+			body:       `{"caught": true, "favorite": true, "notes": "Refuses its ball."}`,
+			wantStatus: http.StatusOK,
+			wantEntry: `{"id": 25, "name": "pikachu", "seen": true, "caught": true, "favorite": true,
+				"notes": "Refuses its ball."}`,
+		},
+		"by dex number": {
+			target:     "/users/ash/pokedex/150",
+			body:       `{"seen": true}`,
+			wantStatus: http.StatusOK,
+			wantEntry:  `{"id": 150, "name": "mewtwo", "seen": true, "caught": false, "favorite": false}`,
+		},
+		"unknown species": {
+			target:     "/users/ash/pokedex/missingno",
+			body:       `{"seen": true}`,
+			wantStatus: http.StatusNotFound,
+			wantError:  `unknown species \"missingno\"`,
+		},
+		"invalid identifier": {
+			target:     "/users/ash/pokedex/pika%20chu!",
+			body:       `{"seen": true}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  pokemon.ErrInvalidIdentifier.Error(),
+		},
+		"invalid user": {
+			target:     "/users/ash%20ketchum/pokedex/pikachu",
+			body:       `{"seen": true}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  pokedex.ErrInvalidUser.Error(),
+		},
+		"notes too long": {
+			target:     "/users/ash/pokedex/pikachu",
+			body:       `{"notes": "` + strings.Repeat("é", pokedex.MaxNotes+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "notes are longer than 1000 characters",
+		},
+		"longest notes": {
+			target:     "/users/ash/pokedex/bulbasaur",
+			body:       `{"notes": "` + strings.Repeat("é", pokedex.MaxNotes) + `"}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	router := newRouter(t)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(router, http.MethodPut, tc.target, tc.body, "pallet")
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantError != "" {
+				assert.JSONEq(t, `{"error": "`+tc.wantError+`"}`, rr.Body.String())
+			}
+			if tc.wantEntry == "" {
+				return
+			}
+
+			var entry map[string]interface{}
+			assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &entry))
+			assert.NotEmpty(t, entry["updated_at"])
+			delete(entry, "updated_at")
+			got, _ := json.Marshal(entry)
+			assert.JSONEq(t, tc.wantEntry, string(got))
+
+			rr = serve(router, http.MethodGet, tc.target, "", "pallet")
+			assert.Equal(t, http.StatusOK, rr.Code)
+		})
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/users/ash/pokedex/25", "", "pallet").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/users/ash/pokedex/25", "", "pallet").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/users/ash/pokedex/pikachu", "", "pallet").Code)
+}
+
+func TestCompletion(t *testing.T) {
+	router := newRouter(t)
+	for target, body := range map[string]string{
+		"bulbasaur": `{"caught": true}`,
+		"pikachu":   `{"seen": true, "favorite": true}`,
+		"chikorita": `{"caught": true, "favorite": true}`,
+		"futuremon": `{"caught": true}`,
+	} {
+		rr := serve(router, http.MethodPut, "/users/ash/pokedex/"+target, body, "pallet")
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rr := serve(router, http.MethodGet, "/users/ash/pokedex?favorite=true", "", "pallet")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var res pokedex.Response
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &res))
+
+	assert.Equal(t, "ash", res.User)
+	var names []string
+	for _, entry := range res.Entries {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{"pikachu", "chikorita"}, names)
+
+	// the completion counts the entries filtered out too
+	assert.Equal(t, pokedex.Progress{
+		Name: "national", Species: 5, Seen: 4, Caught: 3, SeenPercent: 80, CaughtPercent: 60,
+	}, res.Completion.Total)
+	// futuremon is of no generation, it counts in the total only
+	assert.Equal(t, []pokedex.Progress{
+		{Name: "generation-i", Species: 3, Seen: 2, Caught: 1, SeenPercent: 66.7, CaughtPercent: 33.3},
+		{Name: "generation-ii", Species: 1, Seen: 1, Caught: 1, SeenPercent: 100, CaughtPercent: 100},
+	}, res.Completion.Generations)
+	assert.Equal(t, []pokedex.Progress{
+		{Name: "kanto", Species: 3, Seen: 2, Caught: 1, SeenPercent: 66.7, CaughtPercent: 33.3},
+		{Name: "johto", Species: 1, Seen: 1, Caught: 1, SeenPercent: 100, CaughtPercent: 100},
+	}, res.Completion.Regions)
+
+	rr = serve(router, http.MethodGet, "/users/ash/pokedex?seen=maybe", "", "pallet")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestExportImport(t *testing.T) {
+	router := newRouter(t)
+	for target, body := range map[string]string{
+		"mewtwo":  `{"caught": true, "notes": "Cerulean Cave"}`,
+		"pikachu": `{"seen": true}`,
+	} {
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodPut, "/users/ash/pokedex/"+target, body, "pallet").Code)
+	}
+
+	rr := serve(router, http.MethodGet, "/users/ash/pokedex/export", "", "pallet")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename="pokedex-ash.json"`, rr.Header().Get("Content-Disposition"))
+	var export pokedex.Export
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &export))
+	assert.Equal(t, pokedex.ExportVersion, export.Version)
+	assert.Len(t, export.Entries, 2)
+
+	rr = serve(router, http.MethodPost, "/users/gary/pokedex/import", rr.Body.String(), "pallet")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var imported pokedex.Pokedex
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &imported))
+	assert.Equal(t, "gary", imported.User)
+	assert.Equal(t, export.Entries, imported.Entries)
+
+	tests := map[string]struct {
+		body      string
+		wantError string
+	}{
+		"unsupported version": {
+			body:      `{"version": 2, "entries": []}`,
+			wantError: "invalid import: version must be 1",
+		},
+		"unknown species": {
+			body:      `{"version": 1, "entries": [{"name": "pikachu"}, {"name": "missingno"}]}`,
+			wantError: `invalid import: entries[1]: unknown species \"missingno\"`,
+		},
+		"duplicate species": {
+			body:      `{"version": 1, "entries": [{"name": "Pikachu"}, {"id": 25}]}`,
+			wantError: "invalid import: entries[1]: pikachu is entries[0] already",
+		},
+		"mismatched dex number": {
+			body:      `{"version": 1, "entries": [{"id": 26, "name": "pikachu"}]}`,
+			wantError: "invalid import: entries[0]: pikachu is #25, not #26",
+		},
+		"unnamed species": {
+			body:      `{"version": 1, "entries": [{"caught": true}]}`,
+			wantError: "invalid import: entries[0]: name or id is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(router, http.MethodPost, "/users/gary/pokedex/import", tc.body, "pallet")
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.JSONEq(t, `{"error": "`+tc.wantError+`"}`, rr.Body.String())
+		})
+	}
+
+	// the failed imports left the pokedex as it was
+	rr = serve(router, http.MethodGet, "/users/gary/pokedex", "", "pallet")
+	assert.Contains(t, rr.Body.String(), `"notes":"Cerulean Cave"`)
+}
+
+func TestClients(t *testing.T) {
+	router := newRouter(t)
+	// an app keeps many users apart
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodPut, "/users/ash/pokedex/pikachu", `{"seen": true}`,
+		"pallet").Code)
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodPut, "/users/misty/pokedex/mewtwo", `{"caught": true}`,
+		"pallet").Code)
+
+	tests := map[string]struct {
+		method     string
+		target     string
+		app        string
+		wantStatus int
+		wantBody   string
+	}{
+		"user entry": {
+			method: http.MethodGet, target: "/users/ash/pokedex/pikachu", app: "pallet", wantStatus: http.StatusOK,
+			wantBody: `"name":"pikachu"`,
+		},
+		"other user entry": {
+			method: http.MethodGet, target: "/users/misty/pokedex/pikachu", app: "pallet",
+			wantStatus: http.StatusNotFound,
+		},
+		"other user": {
+			method: http.MethodGet, target: "/users/misty/pokedex", app: "pallet", wantStatus: http.StatusOK,
+			wantBody: `"entries":[{"id":150,"name":"mewtwo"`,
+		},
+		// users of other apps are other users
+		"other app": {
+			method: http.MethodGet, target: "/users/ash/pokedex", app: "viridian", wantStatus: http.StatusOK,
+			wantBody: `"entries":[]`,
+		},
+		"other app entry": {
+			method: http.MethodGet, target: "/users/ash/pokedex/pikachu", app: "viridian",
+			wantStatus: http.StatusNotFound,
+		},
+		"other app export": {
+			method: http.MethodGet, target: "/users/ash/pokedex/export", app: "viridian", wantStatus: http.StatusOK,
+			wantBody: `"entries":[]`,
+		},
+		"other app delete": {
+			method: http.MethodDelete, target: "/users/ash/pokedex/pikachu", app: "viridian",
+			wantStatus: http.StatusNotFound,
+		},
+		"anonymous": {
+			method: http.MethodGet, target: "/users/ash/pokedex", wantStatus: http.StatusUnauthorized,
+			wantBody: `{"error":"missing api key, set the ` + apikey.Header + ` header"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(router, tc.method, tc.target, "", tc.app)
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+
+	// the other app deleted nothing
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/users/ash/pokedex/pikachu", "", "pallet").Code)
+}
@@ -0,0 +1,54 @@
+package pokemon
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sources of the cached values, reported by the admin cache API.
+const (
+	// SourcePokeAPI is species data, from the PokeAPI or the local dataset.
+	SourcePokeAPI = "pokeapi"
+	// SourceTranslations is a description translated by the translations API.
+	SourceTranslations = "funtranslations"
+	// SourceUntranslated is a description served untranslated, as the translation failed or is disabled.
+	SourceUntranslated = "untranslated"
+)
+
+// cacheLoad returns the cached value for key, traced as a storage.Load span.
+func (s *Service) cacheLoad(ctx context.Context, key string) (interface{}, bool) {
+	_, span := s.Tracer.Start(ctx, "storage.Load", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+
+	value, ok := s.StorageAPI.Load(key)
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+
+	return value, ok
+}
+
+// cacheSave caches value from source under key for ttl, traced as a storage.Save span.
+func (s *Service) cacheSave(
+	ctx context.Context,
+	key string,
+	value interface{},
+	ttl time.Duration,
+	source string,
+) error {
+	_, span := s.Tracer.Start(ctx, "storage.Save", trace.WithAttributes(
+		attribute.String("cache.key", key),
+		attribute.String("cache.source", source),
+	))
+	defer span.End()
+
+	err := s.StorageAPI.SaveWithSource(key, value, ttl, source)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package pokemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"pokedex-clone/pkg/format"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCacheAge caps the Cache-Control max-age of responses, including those of entries that never expire.
+const maxCacheAge = 24 * time.Hour
+
+// respond writes v in f with a strong ETag of that representation, along with the Last-Modified time and
+// the Cache-Control max-age of the cache entry under key. Requests whose If-None-Match or If-Modified-Since
+// validators still match get a 304 Not Modified without a body instead.
+func (s *Service) respond(c *gin.Context, f format.Format, v interface{}, key string) {
+	body, ok := marshal(c, f, v)
+	if !ok {
+		return
+	}
+
+	header := c.Writer.Header()
+	etag := strongETag(body)
+	header.Set("ETag", etag)
+
+	var modified time.Time
+	if e, ok := s.StorageAPI.Inspect(key); ok {
+		modified = e.CreatedAt.UTC().Truncate(time.Second)
+		header.Set("Last-Modified", modified.Format(http.TimeFormat))
+		header.Set("Cache-Control", cacheControl(e.ExpiresAt, time.Now()))
+	} else {
+		// responses that weren't cached, e.g. descriptions without an english text, are revalidated
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	if notModified(c.Request, etag, modified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, f.ContentType(), body)
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// cacheControl returns the Cache-Control of an entry expiring at expiresAt, zero meaning never.
+func cacheControl(expiresAt, now time.Time) string {
+	age := maxCacheAge
+	if !expiresAt.IsZero() && expiresAt.Sub(now) < age {
+		age = expiresAt.Sub(now)
+	}
+	if age < 0 {
+		age = 0
+	}
+
+	return "public, max-age=" + strconv.Itoa(int(age/time.Second))
+}
+
+// notModified reports whether the validators of req match, If-Modified-Since being ignored when
+// If-None-Match is present as RFC 9110 requires.
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match uses the weak comparison, a W/ prefix doesn't prevent a match
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !modified.After(since)
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package pokemon_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalGet(t *testing.T) {
+	species := &api.PokemonSpecies{
+		Name: "mewtwo",
+		FlavorTextEntries: []api.FlavorText{
+			{FlavorText: "some text here", Language: api.NamedAPIResource{Name: "en"}},
+		},
+	}
+
+	storageAPI := storage.NewStore()
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	service := pokemon.NewService(storageAPI, mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+	settings := service.Settings()
+	settings.SpeciesTTL = time.Hour
+	service.SetSettings(settings)
+
+	router := gin.New()
+	router.GET("/pokemon/:name", service.Get)
+
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(species, nil).Times(1)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/pokemon/mewtwo", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	first := get(nil)
+	assert.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag, "the ETag is strong")
+	assert.NotEmpty(t, lastModified)
+	assert.Regexp(t, `^public, max-age=(3600|3599)$`, first.Header().Get("Cache-Control"))
+
+	later := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	earlier := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := map[string]struct {
+		header     http.Header
+		wantStatus int
+	}{
+		"no validators": {
+			wantStatus: http.StatusOK,
+		},
+		"matching etag": {
+			header:     http.Header{"If-None-Match": {etag}},
+			wantStatus: http.StatusNotModified,
+		},
+		"matching etag in a list, weakly compared": {
+			header:     http.Header{"If-None-Match": {`"other", W/` + etag}},
+			wantStatus: http.StatusNotModified,
+		},
+		"any etag": {
+			header:     http.Header{"If-None-Match": {"*"}},
+			wantStatus: http.StatusNotModified,
+		},
+		"stale etag": {
+			header:     http.Header{"If-None-Match": {`"other"`}},
+			wantStatus: http.StatusOK,
+		},
+		"stale etag takes precedence over if-modified-since": {
+			header:     http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {later}},
+			wantStatus: http.StatusOK,
+		},
+		"unmodified since last-modified": {
+			header:     http.Header{"If-Modified-Since": {lastModified}},
+			wantStatus: http.StatusNotModified,
+		},
+		"modified since": {
+			header:     http.Header{"If-Modified-Since": {earlier}},
+			wantStatus: http.StatusOK,
+		},
+		"invalid if-modified-since": {
+			header:     http.Header{"If-Modified-Since": {"yesterday"}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := get(tt.header)
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			assert.Equal(t, lastModified, rr.Header().Get("Last-Modified"))
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			} else {
+				assert.Equal(t, first.Body.String(), rr.Body.String())
+			}
+		})
+	}
+}
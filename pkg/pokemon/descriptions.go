@@ -0,0 +1,60 @@
+package pokemon
+
+import (
+	"net/http"
+	"net/url"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchDescriptions ranks the pokemon whose cached descriptions match the query. Only species
+// the service has fetched before are searchable, the index grows as new species get cached.
+func (s *Service) SearchDescriptions(c *gin.Context) {
+	var req DescriptionQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultSearchLimit
+	}
+
+	matches := s.SearchIndex.Search(req.Q, req.Language, req.Limit)
+	results := make([]DescriptionMatch, 0, len(matches))
+	for _, m := range matches {
+		match := DescriptionMatch{
+			Name:     m.Species,
+			URL:      "/pokemon/" + url.PathEscape(m.Species),
+			Score:    m.Score,
+			Language: m.Language,
+			Version:  m.Version,
+			Snippet:  m.Snippet,
+		}
+		if entry, ok := s.Index.Lookup(m.Species); ok {
+			match.ID = entry.ID
+		}
+		results = append(results, match)
+	}
+
+	c.JSON(http.StatusOK, DescriptionResults{
+		Query:   req.Q,
+		Results: results,
+	})
+}
+
+// indexDescriptions adds every flavor text of the species to the full-text search index.
+func (s *Service) indexDescriptions(species *api.PokemonSpecies) {
+	entries := make([]search.Entry, 0, len(species.FlavorTextEntries))
+	for _, ft := range species.FlavorTextEntries {
+		entries = append(entries, search.Entry{
+			Text:     ft.FlavorText,
+			Language: ft.Language.Name,
+			Version:  ft.Version.Name,
+		})
+	}
+
+	s.SearchIndex.Add(species.Name, entries)
+}
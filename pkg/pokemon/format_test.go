@@ -0,0 +1,157 @@
+package pokemon_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// updateGoldenEnv rewrites the golden files of TestFormats with the current output when set to 1.
+const updateGoldenEnv = "UPDATE_GOLDEN"
+
+func TestFormats(t *testing.T) {
+	species := &api.PokemonSpecies{
+		Name:        "mewtwo",
+		IsLegendary: true,
+		Habitat:     api.NamedAPIResource{Name: "rare"},
+		FlavorTextEntries: []api.FlavorText{
+			{
+				FlavorText: "It was created by\na scientist, \"after\" years <of> experiments.",
+				Language:   api.NamedAPIResource{Name: "en"},
+			},
+		},
+	}
+	page := &api.NamedAPIResourceList{
+		Count:   1010,
+		Results: []api.NamedAPIResource{speciesResource(150, "mewtwo"), speciesResource(151, "mew")},
+	}
+
+	tests := map[string]struct {
+		target          string
+		accept          string
+		golden          string
+		wantContentType string
+	}{
+		"pokemon as json": {
+			target:          "/pokemon/mewtwo",
+			golden:          "pokemon.json",
+			wantContentType: "application/json; charset=utf-8",
+		},
+		"pokemon as yaml": {
+			target:          "/pokemon/mewtwo",
+			accept:          "application/yaml",
+			golden:          "pokemon.yaml",
+			wantContentType: "application/yaml; charset=utf-8",
+		},
+		"pokemon as xml": {
+			target:          "/pokemon/mewtwo",
+			accept:          "text/xml",
+			golden:          "pokemon.xml",
+			wantContentType: "application/xml; charset=utf-8",
+		},
+		"pokemon as csv": {
+			target:          "/pokemon/mewtwo?format=csv",
+			golden:          "pokemon.csv",
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		"pokemon as msgpack": {
+			target:          "/pokemon/mewtwo",
+			accept:          "application/msgpack",
+			golden:          "pokemon.msgpack",
+			wantContentType: "application/msgpack",
+		},
+		"listing as json": {
+			target:          "/pokemon?offset=149&limit=2",
+			golden:          "list.json",
+			wantContentType: "application/json; charset=utf-8",
+		},
+		"listing as yaml": {
+			target:          "/pokemon?offset=149&limit=2&format=yaml",
+			golden:          "list.yaml",
+			wantContentType: "application/yaml; charset=utf-8",
+		},
+		"listing as xml": {
+			target:          "/pokemon?offset=149&limit=2",
+			accept:          "application/xml",
+			golden:          "list.xml",
+			wantContentType: "application/xml; charset=utf-8",
+		},
+		"listing as csv": {
+			target:          "/pokemon?offset=149&limit=2",
+			accept:          "text/csv",
+			golden:          "list.csv",
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		"listing as msgpack": {
+			target:          "/pokemon?offset=149&limit=2",
+			accept:          "application/x-msgpack",
+			golden:          "list.msgpack",
+			wantContentType: "application/msgpack",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(species, nil).AnyTimes()
+			mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 149, 2).Return(page, nil).AnyTimes()
+			service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+			router := gin.New()
+			router.GET("/pokemon", service.List)
+			router.GET("/pokemon/:name", service.Get)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+
+			golden := filepath.Join("testdata", tt.golden)
+			if os.Getenv(updateGoldenEnv) == "1" {
+				assert.Nil(t, os.WriteFile(golden, rr.Body.Bytes(), 0o600))
+			}
+			want, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+			assert.Equal(t, string(want), rr.Body.String())
+		})
+	}
+}
+
+func TestFormatsNotAcceptable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	router := gin.New()
+	router.GET("/pokemon", service.List)
+	router.GET("/pokemon/:name", service.Get)
+	router.GET("/pokemon/translated/:name", service.GetTranslated)
+
+	for _, target := range []string{"/pokemon", "/pokemon/mewtwo", "/pokemon/translated/mewtwo"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", "text/html")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code, target)
+		assert.Contains(t, rr.Body.String(), "none of the accepted media types is available", target)
+	}
+}
@@ -0,0 +1,92 @@
+package pokemon
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxNationalDexNumber = 99999
+
+var (
+	// ErrInvalidIdentifier is returned for identifiers that can't be a species name or dex number.
+	ErrInvalidIdentifier = errors.New("pokemon identifier must be a name or a national dex number")
+
+	canonicalNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	dexNumberPattern     = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Identifier is a parsed species name or national dex number.
+type Identifier struct {
+	// Name is the canonical pokeapi name, empty when the identifier is a dex number.
+	Name string
+	// ID is the national dex number, zero when the identifier is a name.
+	ID int
+}
+
+// ParseIdentifier validates raw and returns it in canonical form, so "25", "Pikachu" and "pikachu"
+// all parse to identifiers resolving to the same species.
+func ParseIdentifier(raw string) (Identifier, error) {
+	raw = strings.TrimSpace(raw)
+
+	if dexNumberPattern.MatchString(raw) {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id < 1 || id > maxNationalDexNumber {
+			return Identifier{}, ErrInvalidIdentifier
+		}
+
+		return Identifier{ID: id}, nil
+	}
+
+	name := canonicalName(raw)
+	if !canonicalNamePattern.MatchString(name) {
+		return Identifier{}, ErrInvalidIdentifier
+	}
+
+	return Identifier{Name: name}, nil
+}
+
+// String returns the identifier in the form accepted by the pokeapi.
+func (i Identifier) String() string {
+	if i.Name != "" {
+		return i.Name
+	}
+
+	return strconv.Itoa(i.ID)
+}
+
+// canonicalName lowercases name, strips diacritics ("Flabébé" becomes "flabebe") and rewrites
+// punctuation into the hyphenated pokeapi form.
+func canonicalName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, name)
+	if err != nil {
+		return ""
+	}
+
+	// rewrite the characters used in display names into their pokeapi form, e.g. "Nidoran♀"
+	// becomes "nidoran-f", "Mr. Mime" becomes "mr-mime" and "Farfetch'd" becomes "farfetchd"
+	replacer := strings.NewReplacer(
+		"♀", "-f",
+		"♂", "-m",
+		" ", "-",
+		"_", "-",
+		".", "",
+		"'", "",
+		"’", "",
+		":", "",
+	)
+
+	stripped = replacer.Replace(strings.ToLower(stripped))
+	for strings.Contains(stripped, "--") {
+		stripped = strings.ReplaceAll(stripped, "--", "-")
+	}
+
+	return stripped
+}
@@ -0,0 +1,265 @@
+package pokemon
+
+import (
+	"context"
+	"errors"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/logging"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	indexPageSize = 500
+	// maxGenerations bounds the generations walked by Refresh, far above the number the pokeapi has.
+	maxGenerations = 100
+	// legendaryBatch is how many species are fetched at once to learn their legendary status.
+	legendaryBatch = 8
+)
+
+// IndexEntry is a single species known to the index, along with the generation which introduced it and the main
+// region of that generation, which are empty when unknown.
+type IndexEntry struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Generation string `json:"generation,omitempty"`
+	Region     string `json:"region,omitempty"`
+}
+
+// Index is a locally cached list of every species name and national dex id,
+// built from the pokeapi paginated species list.
+type Index struct {
+	sync.RWMutex
+	pokeAPI   api.PokeAPI
+	entries   []IndexEntry
+	byName    map[string]IndexEntry
+	byID      map[int]IndexEntry
+	updatedAt time.Time
+	// legendary is the legendary status of the species known so far, by national dex number. Refreshes keep it.
+	legendary map[int]bool
+
+	// LoadLegendary fetches the legendary status of entries, Run loads the statuses not known yet with it after
+	// every refresh. The statuses are only learned from the species fetched otherwise when nil.
+	LoadLegendary func(ctx context.Context, entries []IndexEntry) ([]bool, error)
+
+	// refreshing shares a refresh between its concurrent callers, e.g. the requests finding the index empty.
+	refreshing singleflight.Group
+}
+
+// NewIndex creates an empty Index, call Refresh or Run to populate it.
+func NewIndex(pokeAPI api.PokeAPI) *Index {
+	return &Index{
+		pokeAPI:   pokeAPI,
+		byName:    make(map[string]IndexEntry),
+		byID:      make(map[int]IndexEntry),
+		legendary: make(map[int]bool),
+	}
+}
+
+// Refresh walks the whole species list, and the generations introducing them, and replaces the cached entries. The
+// species keep the generations of the previous refresh when the generations can't be walked. Concurrent calls share
+// the same walk and its result.
+func (i *Index) Refresh(ctx context.Context) error {
+	_, err, _ := i.refreshing.Do("refresh", func() (interface{}, error) {
+		return nil, i.refresh(ctx)
+	})
+
+	return err
+}
+
+func (i *Index) refresh(ctx context.Context) error {
+	var entries []IndexEntry
+	for offset := 0; ; offset += indexPageSize {
+		page, err := i.pokeAPI.ListSpecies(ctx, offset, indexPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, res := range page.Results {
+			id, idErr := res.ID()
+			if idErr != nil {
+				logging.Debugf("skipping species %s with unexpected url %s", res.Name, res.URL)
+				continue
+			}
+			entries = append(entries, IndexEntry{ID: id, Name: res.Name})
+		}
+
+		if page.Next == nil || len(page.Results) == 0 {
+			break
+		}
+	}
+
+	sort.Slice(entries, func(a, b int) bool { return entries[a].ID < entries[b].ID })
+
+	generations, err := i.generations(ctx)
+	if err != nil {
+		logging.Warnf("failed to fetch the generations of the species, keeping the previous ones: [%v]", err)
+		i.RLock()
+		generations = i.byID
+		i.RUnlock()
+	}
+	for k, e := range entries {
+		if g, ok := generations[e.ID]; ok {
+			entries[k].Generation, entries[k].Region = g.Generation, g.Region
+		}
+	}
+
+	byName := make(map[string]IndexEntry, len(entries))
+	byID := make(map[int]IndexEntry, len(entries))
+	for _, e := range entries {
+		byName[e.Name] = e
+		byID[e.ID] = e
+	}
+
+	i.Lock()
+	defer i.Unlock()
+	i.entries = entries
+	i.byName = byName
+	i.byID = byID
+	i.updatedAt = time.Now()
+
+	return nil
+}
+
+// generations walks the generations numbered from 1 until the first one the pokeapi doesn't have, and returns the
+// generation and region of the species they introduced by national dex number.
+func (i *Index) generations(ctx context.Context) (map[int]IndexEntry, error) {
+	res := make(map[int]IndexEntry)
+	for n := 1; n <= maxGenerations; n++ {
+		generation, err := i.pokeAPI.GetGeneration(ctx, strconv.Itoa(n))
+		if errors.Is(err, api.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, species := range generation.PokemonSpecies {
+			if id, idErr := species.ID(); idErr == nil {
+				res[id] = IndexEntry{Generation: generation.Name, Region: generation.MainRegion.Name}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// Run refreshes the index immediately and then on every interval until ctx is done, loading the legendary status
+// of the new species after every refresh.
+func (i *Index) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.Refresh(ctx); err != nil {
+			logging.Errorf("failed to refresh species index: [%v]", err)
+		} else if err = i.loadLegendary(ctx); err != nil {
+			logging.Warnf("failed to load the legendary status of the species: [%v]", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Entries returns all indexed species ordered by id, populating the index first if it is empty, once for all the
+// concurrent callers.
+func (i *Index) Entries(ctx context.Context) ([]IndexEntry, error) {
+	if i.Len() == 0 {
+		if err := i.Refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	i.RLock()
+	defer i.RUnlock()
+	entries := make([]IndexEntry, len(i.entries))
+	copy(entries, i.entries)
+
+	return entries, nil
+}
+
+// Legendary returns the legendary status of the species id, and whether it is known yet.
+func (i *Index) Legendary(id int) (legendary, known bool) {
+	i.RLock()
+	defer i.RUnlock()
+	legendary, known = i.legendary[id]
+	return legendary, known
+}
+
+// SetLegendary records the legendary status of the species id, e.g. once it was fetched.
+func (i *Index) SetLegendary(id int, legendary bool) {
+	i.Lock()
+	defer i.Unlock()
+	i.legendary[id] = legendary
+}
+
+// loadLegendary loads the legendary status of the indexed species not known yet, a batch at a time.
+func (i *Index) loadLegendary(ctx context.Context) error {
+	if i.LoadLegendary == nil {
+		return nil
+	}
+
+	i.RLock()
+	var unknown []IndexEntry
+	for _, e := range i.entries {
+		if _, known := i.legendary[e.ID]; !known {
+			unknown = append(unknown, e)
+		}
+	}
+	i.RUnlock()
+
+	for start := 0; start < len(unknown); start += legendaryBatch {
+		batch := unknown[start:]
+		if len(batch) > legendaryBatch {
+			batch = batch[:legendaryBatch]
+		}
+
+		legendary, err := i.LoadLegendary(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for k, e := range batch {
+			i.SetLegendary(e.ID, legendary[k])
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the entry for the given species name.
+func (i *Index) Lookup(name string) (IndexEntry, bool) {
+	i.RLock()
+	defer i.RUnlock()
+	e, ok := i.byName[name]
+	return e, ok
+}
+
+// LookupID returns the entry for the given national dex number.
+func (i *Index) LookupID(id int) (IndexEntry, bool) {
+	i.RLock()
+	defer i.RUnlock()
+	e, ok := i.byID[id]
+	return e, ok
+}
+
+// Len returns the number of indexed species.
+func (i *Index) Len() int {
+	i.RLock()
+	defer i.RUnlock()
+	return len(i.entries)
+}
+
+// UpdatedAt returns when the index was last refreshed successfully.
+func (i *Index) UpdatedAt() time.Time {
+	i.RLock()
+	defer i.RUnlock()
+	return i.updatedAt
+}
@@ -0,0 +1,83 @@
+package pokemon_test
+
+import (
+	"context"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexEntriesSharesRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	// a single walk of the species and generations serves every caller finding the index empty
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).DoAndReturn(
+		func(context.Context, int, int) (*api.NamedAPIResourceList, error) {
+			time.Sleep(50 * time.Millisecond)
+			return &api.NamedAPIResourceList{Count: 1, Results: []api.NamedAPIResource{
+				{Name: "bulbasaur", URL: "https://pokeapi.co/api/v2/pokemon-species/1/"},
+			}}, nil
+		}).Times(1)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), "1").Return(nil, api.ErrNotFound).Times(1)
+
+	index := pokemon.NewIndex(mockPokeAPI)
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, err := index.Entries(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, []pokemon.IndexEntry{{ID: 1, Name: "bulbasaur"}}, entries)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIndexRunLoadsLegendary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	var species []api.NamedAPIResource
+	for id := 1; id <= 10; id++ {
+		species = append(species, api.NamedAPIResource{
+			Name: "species-" + strconv.Itoa(id),
+			URL:  "https://pokeapi.co/api/v2/pokemon-species/" + strconv.Itoa(id) + "/",
+		})
+	}
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).
+		Return(&api.NamedAPIResourceList{Count: len(species), Results: species}, nil).Times(2)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+
+	index := pokemon.NewIndex(mockPokeAPI)
+	index.SetLegendary(1, false)
+	var loaded []int
+	index.LoadLegendary = func(_ context.Context, entries []pokemon.IndexEntry) ([]bool, error) {
+		legendary := make([]bool, len(entries))
+		for i, e := range entries {
+			loaded = append(loaded, e.ID)
+			legendary[i] = e.ID == 10
+		}
+		return legendary, nil
+	}
+
+	// Run returns after its first refresh once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	index.Run(ctx, time.Hour)
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10}, loaded, "the known statuses aren't loaded again")
+	legendary, known := index.Legendary(10)
+	assert.True(t, known)
+	assert.True(t, legendary)
+
+	// the statuses outlive the refreshes
+	loaded = nil
+	index.Run(ctx, time.Hour)
+	assert.Empty(t, loaded)
+}
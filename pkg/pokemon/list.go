@@ -0,0 +1,412 @@
+package pokemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"pokedex-clone/pkg/api"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultListLimit   = 20
+	defaultSearchLimit = 10
+	maxSuggestions     = 5
+	// maxLegendaryFetches is how many species a listing filtered by legendary status may fetch to learn their
+	// status, the index loads the others in the background.
+	maxLegendaryFetches = 4 * legendaryBatch
+)
+
+var (
+	// ErrUnknownFilter matches the errors of listings whose generation or habitat filter doesn't exist.
+	ErrUnknownFilter = errors.New("unknown filter")
+	// ErrLegendaryUnavailable is returned by the listings filtered by legendary status which would fetch more than
+	// maxLegendaryFetches species, until the index knows the status of more species.
+	ErrLegendaryUnavailable = errors.New("the legendary status of the species is still loading, retry later")
+)
+
+// filterError is returned when a generation or habitat filter can't be resolved upstream.
+type filterError struct {
+	filter string
+	value  string
+	err    error
+}
+
+func (e filterError) Error() string {
+	return fmt.Sprintf("unknown %s %q: %v", e.filter, e.value, e.err)
+}
+
+func (e filterError) Unwrap() error {
+	return e.err
+}
+
+func (e filterError) Is(target error) bool {
+	return target == ErrUnknownFilter
+}
+
+// List returns a page of species, optionally filtered by generation, habitat and legendary status.
+// Unfiltered pages are served straight from the pokeapi species list, filtered ones from the
+// species index. The page is served in its v1 shape.
+func (s *Service) List(c *gin.Context) {
+	s.list(c, v1{})
+}
+
+func (s *Service) list(c *gin.Context, presenter Presenter) {
+	f, ok := negotiate(c)
+	if !ok {
+		return
+	}
+
+	var req ListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultListLimit
+	}
+
+	page, hasNext, err := s.ListPokemon(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, ErrUnknownFilter) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, ErrLegendaryUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	if hasNext {
+		page.Next = pageLink(c.Request.URL, req.Offset+req.Limit, req.Limit)
+	}
+
+	if req.Offset > 0 {
+		prevOffset := req.Offset - req.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		page.Previous = pageLink(c.Request.URL, prevOffset, req.Limit)
+	}
+
+	render(c, f, http.StatusOK, presenter.List(page))
+}
+
+// Search autocompletes species names from the species index, by prefix or by fuzzy matching.
+func (s *Service) Search(c *gin.Context) {
+	var req SearchQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = SearchModePrefix
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultSearchLimit
+	}
+
+	if _, err := s.Index.Entries(c.Request.Context()); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := canonicalName(req.Q)
+	c.JSON(http.StatusOK, SearchResults{
+		Query:   query,
+		Mode:    req.Mode,
+		Results: newPokemonListItems(s.Index.Search(query, req.Mode, req.Limit)),
+	})
+}
+
+// ListPokemon returns a page of species as served by List, reporting whether there is a next page. Its
+// limit must be set, and it fails with ErrUnknownFilter when a generation or habitat filter doesn't exist.
+func (s *Service) ListPokemon(ctx context.Context, req ListQuery) (*PokemonList, bool, error) {
+	if req.Generation == "" && req.Habitat == "" && req.Legendary == nil {
+		return s.listAll(ctx, req)
+	}
+
+	return s.listFiltered(ctx, req)
+}
+
+// abortFetch responds to a failed fetch of ident, with 404 when the species doesn't exist upstream and with 502
+// otherwise.
+func (s *Service) abortFetch(c *gin.Context, ident Identifier, err error) {
+	if errors.Is(err, api.ErrNotFound) {
+		s.abortNotFound(c, ident, err)
+		return
+	}
+
+	s.Logger.WarnCtx(c.Request.Context(), "failed to fetch species", "species", ident.String(), "error", err)
+	c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+}
+
+// abortNotFound responds with 404, suggesting similarly named species when ident is a name.
+func (s *Service) abortNotFound(c *gin.Context, ident Identifier, err error) {
+	s.Logger.DebugCtx(c.Request.Context(), "species not found", "species", ident.String(), "error", err)
+
+	body := NotFound{Error: err.Error()}
+	if ident.Name != "" {
+		body.Suggestions = newPokemonListItems(s.Index.Suggest(ident.Name, maxSuggestions))
+	}
+
+	c.AbortWithStatusJSON(http.StatusNotFound, body)
+}
+
+func (s *Service) listAll(ctx context.Context, req ListQuery) (*PokemonList, bool, error) {
+	species, err := s.PokeAPI.ListSpecies(ctx, req.Offset, req.Limit)
+	if err != nil {
+		return nil, false, err
+	}
+
+	results := make([]PokemonListItem, 0, len(species.Results))
+	for _, res := range species.Results {
+		id, idErr := res.ID()
+		if idErr != nil {
+			return nil, false, fmt.Errorf("unexpected species url %s: %w", res.URL, idErr)
+		}
+		results = append(results, newPokemonListItem(IndexEntry{ID: id, Name: res.Name}))
+	}
+
+	count := species.Count
+
+	return &PokemonList{
+		Count:   &count,
+		Results: results,
+	}, req.Offset+len(results) < count, nil
+}
+
+func (s *Service) listFiltered(ctx context.Context, req ListQuery) (*PokemonList, bool, error) {
+	entries, err := s.Index.Entries(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if req.Generation != "" {
+		members, mErr := s.generationMembers(ctx, req.Generation)
+		if mErr != nil {
+			return nil, false, filterError{filter: "generation", value: req.Generation, err: mErr}
+		}
+		entries = filterEntries(entries, members)
+	}
+
+	if req.Habitat != "" {
+		members, mErr := s.habitatMembers(ctx, req.Habitat)
+		if mErr != nil {
+			return nil, false, filterError{filter: "habitat", value: req.Habitat, err: mErr}
+		}
+		entries = filterEntries(entries, members)
+	}
+
+	if req.Legendary == nil {
+		count := len(entries)
+		results := make([]PokemonListItem, 0, req.Limit)
+		for i := req.Offset; i < count && i < req.Offset+req.Limit; i++ {
+			results = append(results, newPokemonListItem(entries[i]))
+		}
+
+		return &PokemonList{
+			Count:   &count,
+			Results: results,
+		}, req.Offset+req.Limit < count, nil
+	}
+
+	// legendary status is only known per species, so go through the candidates in order, a batch at a time,
+	// until the page is filled and one more match proves there is a next page. The index knows the status of
+	// most species, the others are fetched up to maxLegendaryFetches.
+	results := make([]PokemonListItem, 0, req.Limit)
+	matches, budget := 0, maxLegendaryFetches
+	for start := 0; start < len(entries); start += legendaryBatch {
+		batch := entries[start:]
+		if len(batch) > legendaryBatch {
+			batch = batch[:legendaryBatch]
+		}
+
+		legendary, fetched, fetchErr := s.legendaryOf(ctx, batch, budget)
+		if fetchErr != nil {
+			return nil, false, fetchErr
+		}
+		budget -= fetched
+
+		for i, entry := range batch {
+			if legendary[i] != *req.Legendary {
+				continue
+			}
+
+			if len(results) == req.Limit {
+				return &PokemonList{Results: results}, true, nil
+			}
+
+			if matches >= req.Offset {
+				results = append(results, newPokemonListItem(entry))
+			}
+			matches++
+		}
+	}
+
+	return &PokemonList{Results: results}, false, nil
+}
+
+// legendaryOf returns the legendary status of every species of entries, fetching those the index doesn't know,
+// and how many it fetched. It fails with ErrLegendaryUnavailable when there are more than budget to fetch.
+func (s *Service) legendaryOf(ctx context.Context, entries []IndexEntry, budget int) ([]bool, int, error) {
+	legendary := make([]bool, len(entries))
+	var unknown []IndexEntry
+	var positions []int
+	for i, entry := range entries {
+		var known bool
+		if legendary[i], known = s.Index.Legendary(entry.ID); !known {
+			unknown = append(unknown, entry)
+			positions = append(positions, i)
+		}
+	}
+	if len(unknown) == 0 {
+		return legendary, 0, nil
+	}
+	if len(unknown) > budget {
+		return nil, 0, ErrLegendaryUnavailable
+	}
+
+	fetched, err := s.fetchLegendary(ctx, unknown)
+	if err != nil {
+		return nil, 0, err
+	}
+	for k, i := range positions {
+		legendary[i] = fetched[k]
+	}
+
+	return legendary, len(unknown), nil
+}
+
+// fetchLegendary returns the legendary status of every species of entries, fetching them concurrently, and records
+// them in the index.
+func (s *Service) fetchLegendary(ctx context.Context, entries []IndexEntry) ([]bool, error) {
+	legendary := make([]bool, len(entries))
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func(i int, entry IndexEntry) {
+			defer wg.Done()
+
+			p, err := s.fetchPokemon(ctx, Identifier{Name: entry.Name}, false)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			legendary[i] = p.IsLegendary
+			s.Index.SetLegendary(entry.ID, p.IsLegendary)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return legendary, nil
+}
+
+// generationMembers returns the names of the species introduced in the given generation.
+func (s *Service) generationMembers(ctx context.Context, name string) (map[string]struct{}, error) {
+	key := "generation/" + name
+	if cached, ok := s.cacheLoad(ctx, key); ok {
+		if members, isSet := cached.(map[string]struct{}); isSet {
+			return members, nil
+		}
+	}
+
+	generation, err := s.PokeAPI.GetGeneration(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	members := resourceNames(generation.PokemonSpecies)
+	if cacheErr := s.cacheSave(ctx, key, members, s.Settings().SpeciesTTL, SourcePokeAPI); cacheErr != nil {
+		return nil, cacheErr
+	}
+
+	return members, nil
+}
+
+// habitatMembers returns the names of the species living in the given habitat.
+func (s *Service) habitatMembers(ctx context.Context, name string) (map[string]struct{}, error) {
+	key := "habitat/" + name
+	if cached, ok := s.cacheLoad(ctx, key); ok {
+		if members, isSet := cached.(map[string]struct{}); isSet {
+			return members, nil
+		}
+	}
+
+	habitat, err := s.PokeAPI.GetHabitat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	members := resourceNames(habitat.PokemonSpecies)
+	if cacheErr := s.cacheSave(ctx, key, members, s.Settings().SpeciesTTL, SourcePokeAPI); cacheErr != nil {
+		return nil, cacheErr
+	}
+
+	return members, nil
+}
+
+func resourceNames(resources []api.NamedAPIResource) map[string]struct{} {
+	names := make(map[string]struct{}, len(resources))
+	for _, res := range resources {
+		names[res.Name] = struct{}{}
+	}
+
+	return names
+}
+
+func filterEntries(entries []IndexEntry, members map[string]struct{}) []IndexEntry {
+	filtered := entries[:0:0]
+	for _, e := range entries {
+		if _, ok := members[e.Name]; ok {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+func newPokemonListItem(entry IndexEntry) PokemonListItem {
+	return PokemonListItem{
+		ID:   entry.ID,
+		Name: entry.Name,
+		URL:  "/pokemon/" + url.PathEscape(entry.Name),
+	}
+}
+
+func newPokemonListItems(entries []IndexEntry) []PokemonListItem {
+	items := make([]PokemonListItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, newPokemonListItem(e))
+	}
+
+	return items
+}
+
+// pageLink returns the request URL with the pagination parameters replaced, keeping any filters.
+func pageLink(reqURL *url.URL, offset, limit int) string {
+	query := reqURL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	return reqURL.Path + "?" + query.Encode()
+}
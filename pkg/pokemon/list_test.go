@@ -0,0 +1,177 @@
+package pokemon_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func speciesResource(id int, name string) api.NamedAPIResource {
+	return api.NamedAPIResource{
+		Name: name,
+		URL:  fmt.Sprintf("https://pokeapi.co/api/v2/pokemon-species/%d/", id),
+	}
+}
+
+func TestListPokemon(t *testing.T) {
+	allSpecies := &api.NamedAPIResourceList{
+		Count: 4,
+		Results: []api.NamedAPIResource{
+			speciesResource(1, "bulbasaur"),
+			speciesResource(144, "articuno"),
+			speciesResource(152, "chikorita"),
+			speciesResource(249, "lugia"),
+		},
+	}
+
+	generationI := &api.Generation{
+		ID:   1,
+		Name: "generation-i",
+		PokemonSpecies: []api.NamedAPIResource{
+			speciesResource(1, "bulbasaur"),
+			speciesResource(144, "articuno"),
+		},
+	}
+
+	// more species than a listing may fetch to learn their legendary status, none of them legendary
+	commonSpecies := &api.NamedAPIResourceList{Count: 40}
+	for id := 1; id <= commonSpecies.Count; id++ {
+		commonSpecies.Results = append(commonSpecies.Results, speciesResource(id, "species-"+strconv.Itoa(id)))
+	}
+
+	legendary := map[string]bool{
+		"bulbasaur": false,
+		"articuno":  true,
+		"chikorita": false,
+		"lugia":     true,
+	}
+
+	tests := map[string]struct {
+		query          string
+		wantStatus     int
+		wantNames      []string
+		wantCount      *int
+		wantNext       string
+		wantPrevious   string
+		expectMockCall func(m *mocks.MockPokeAPI)
+	}{
+		"unfiltered listing is served from the pokeapi page": {
+			query:        "?offset=1&limit=2",
+			wantStatus:   http.StatusOK,
+			wantNames:    []string{"articuno", "chikorita"},
+			wantCount:    intPtr(4),
+			wantNext:     "/pokemon?limit=2&offset=3",
+			wantPrevious: "/pokemon?limit=2&offset=0",
+			expectMockCall: func(m *mocks.MockPokeAPI) {
+				m.EXPECT().ListSpecies(gomock.Any(), 1, 2).Return(&api.NamedAPIResourceList{
+					Count:   4,
+					Results: allSpecies.Results[1:3],
+				}, nil)
+			},
+		},
+		"generation filter intersects the index": {
+			query:      "?generation=generation-i",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"bulbasaur", "articuno"},
+			wantCount:  intPtr(2),
+			expectMockCall: func(m *mocks.MockPokeAPI) {
+				m.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(allSpecies, nil)
+				m.EXPECT().GetGeneration(gomock.Any(), "generation-i").Return(generationI, nil)
+			},
+		},
+		"legendary filter pages through matching species": {
+			query:      "?legendary=true&limit=1",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"articuno"},
+			wantNext:   "/pokemon?legendary=true&limit=1&offset=1",
+			expectMockCall: func(m *mocks.MockPokeAPI) {
+				m.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(allSpecies, nil)
+				m.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, name string) (*api.PokemonSpecies, error) {
+						return &api.PokemonSpecies{Name: name, IsLegendary: legendary[name]}, nil
+					}).Times(4)
+			},
+		},
+		"legendary filter fetches a bounded number of species": {
+			query:      "?legendary=true",
+			wantStatus: http.StatusServiceUnavailable,
+			expectMockCall: func(m *mocks.MockPokeAPI) {
+				m.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(commonSpecies, nil)
+				m.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, name string) (*api.PokemonSpecies, error) {
+						return &api.PokemonSpecies{Name: name}, nil
+					}).Times(32)
+			},
+		},
+		"unknown habitat returns 404": {
+			query:      "?habitat=moon",
+			wantStatus: http.StatusNotFound,
+			expectMockCall: func(m *mocks.MockPokeAPI) {
+				m.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(allSpecies, nil)
+				m.EXPECT().GetHabitat(gomock.Any(), "moon").Return(nil, fmt.Errorf("not found"))
+			},
+		},
+		"limit above maximum returns 400": {
+			query:          "?limit=1000",
+			wantStatus:     http.StatusBadRequest,
+			expectMockCall: func(m *mocks.MockPokeAPI) {},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			storageAPI := storage.NewStore()
+
+			ctrl := gomock.NewController(t)
+
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			service := pokemon.NewService(storageAPI, mockPokeAPI, mockTranslationsAPI)
+
+			router := gin.Default()
+			router.GET("/pokemon", service.List)
+
+			req, err := http.NewRequest(http.MethodGet, "/pokemon"+tc.query, nil)
+			assert.Nil(t, err)
+
+			tc.expectMockCall(mockPokeAPI)
+			mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, tc.wantStatus, rr.Code)
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page pokemon.PokemonList
+			err = json.Unmarshal(rr.Body.Bytes(), &page)
+			assert.Nil(t, err)
+
+			names := make([]string, 0, len(page.Results))
+			for _, p := range page.Results {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tc.wantNames, names)
+			assert.Equal(t, tc.wantCount, page.Count)
+			assert.Equal(t, tc.wantNext, page.Next)
+			assert.Equal(t, tc.wantPrevious, page.Previous)
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,89 @@
+package pokemon
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/queue"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferAsync is the preference of the requests asking to be answered before their work is done, see RFC 7240.
+const preferAsync = "respond-async"
+
+// errQueued is returned by fetchTranslated when the translation was queued instead of fetched.
+var errQueued = errors.New("translation queued")
+
+// PrefersAsync tells whether the Prefer header of the request holds the respond-async preference. Translated
+// requests holding it are answered with 202 Accepted and the location of a task when their translation isn't
+// cached, once the service has a Queue.
+func PrefersAsync(c *gin.Context) bool {
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(token), preferAsync) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// UnlessQueued returns limit, the translation rate limit of the translated routes, skipping the requests with an
+// API key whose translation may be queued: the queue waits for the limit of their key instead of rejecting them.
+// The anonymous requests are limited either way, so that a client can't queue more than it may translate.
+func (s *Service) UnlessQueued(limit gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := apikey.FromContext(c); ok && s.Queue != nil && PrefersAsync(c) {
+			c.Next()
+			return
+		}
+
+		limit(c)
+	}
+}
+
+// Translate caches the translated description of the pokemon named raw, unless it is cached already. It is the
+// handler of the translation tasks: unlike FetchTranslated it fails when the translations API does, caching
+// nothing, so the task is retried.
+func (s *Service) Translate(ctx context.Context, raw string) error {
+	ident, err := ParseIdentifier(raw)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+
+	_, _, err = s.fetchTranslated(ctx, ident, translateOptions{strict: true})
+
+	return err
+}
+
+// queueTranslation queues the translation of the pokemon named name for the request, returning nil when it can't.
+// The task serves its result at the translated route of the request to its client only, tasks of clients with an
+// API key are picked before the anonymous ones.
+func (s *Service) queueTranslation(c *gin.Context, name string) *queue.Task {
+	client := s.Queue.Client(c)
+	priority := queue.PriorityNormal
+	if _, ok := apikey.FromContext(c); ok {
+		priority = queue.PriorityHigh
+	}
+
+	location := strings.Replace(c.FullPath(), ":name", url.PathEscape(name), 1)
+	task, err := s.Queue.Enqueue(queue.Spec{
+		Key:      location,
+		Payload:  name,
+		Location: location,
+		Priority: priority,
+		Client:   client,
+	})
+	if err != nil {
+		s.Logger.ErrorCtx(c.Request.Context(), "failed to queue translation, fetching it now", "name", name,
+			"error", err)
+		return nil
+	}
+
+	return &task
+}
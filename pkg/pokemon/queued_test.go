@@ -0,0 +1,155 @@
+package pokemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/apikey"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefersAsync(t *testing.T) {
+	tests := map[string]struct {
+		prefer []string
+		want   bool
+	}{
+		"respond-async":              {prefer: []string{"respond-async"}, want: true},
+		"among other preferences":    {prefer: []string{"return=minimal, Respond-Async; wait=10"}, want: true},
+		"in another header":          {prefer: []string{"return=minimal", "respond-async"}, want: true},
+		"other preferences":          {prefer: []string{"return=minimal, wait=10"}},
+		"without the Prefer header":  {},
+		"respond-async as parameter": {prefer: []string{"return=minimal; respond-async"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/pokemon/translated/mewtwo", nil)
+			for _, prefer := range tc.prefer {
+				c.Request.Header.Add("Prefer", prefer)
+			}
+
+			assert.Equal(t, tc.want, pokemon.PrefersAsync(c))
+		})
+	}
+}
+
+func TestQueuedTranslation(t *testing.T) {
+	mewtwo := &api.PokemonSpecies{
+		ID:   150,
+		Name: "mewtwo",
+		FlavorTextEntries: []api.FlavorText{{
+			FlavorText: "It was created by a scientist.",
+			Language:   api.NamedAPIResource{Name: "en"},
+		}},
+		Habitat:     api.NamedAPIResource{Name: "rare"},
+		IsLegendary: true,
+	}
+
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(mewtwo, nil).AnyTimes()
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), gomock.Any()).Return(nil, errors.New("not found")).AnyTimes()
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	// the first attempt is rate limited, the task is retried
+	gomock.InOrder(
+		mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), api.TTypeYoda).
+			Return(nil, errors.New("429 too many requests")),
+		mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), api.TTypeYoda).
+			Return(&api.TranslateAPIResponse{
+				Success:  api.Success{Total: 1},
+				Contents: api.Contents{Translated: "Created by a scientist, it was."},
+			}, nil),
+	)
+
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+	service.Queue = queue.New(storage.NewStore(), service.Translate, queue.Options{
+		Workers:     1,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	})
+	translated := make(chan pokemon.Pokemon, 1)
+	service.OnTranslated = func(p pokemon.Pokemon) {
+		translated <- p
+	}
+
+	const testKey = "s3cret"
+	keyring := apikey.NewKeyring(storage.NewStore())
+	assert.Nil(t, keyring.SetConfigured([]string{"ash:" + testKey}))
+	// the translation limit rejects every request it applies to
+	rejected := func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+	}
+	router := gin.New()
+	router.Use(keyring.Authenticate)
+	router.GET(pokemon.V2Prefix+"/pokemon/translated/:name", service.UnlessQueued(rejected), service.V2().GetTranslated)
+	service.Queue.Register(router)
+
+	serve := func(target string, async bool, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if async {
+			req.Header.Set("Prefer", "respond-async")
+		}
+		if key != "" {
+			req.Header.Set(apikey.Header, key)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("/v2/pokemon/translated/mewtwo", false, testKey)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	// anonymous requests are limited even when their translation would be queued
+	rr = serve("/v2/pokemon/translated/mewtwo", true, "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	rr = serve("/v2/pokemon/translated/mewtwo", true, testKey)
+	assert.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+	assert.Equal(t, "respond-async", rr.Header().Get("Preference-Applied"))
+	var task queue.Task
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &task))
+	assert.Equal(t, queue.Path+"/"+task.ID, rr.Header().Get("Location"))
+	assert.Equal(t, "/v2/pokemon/translated/mewtwo", task.Location)
+	assert.Equal(t, queue.PriorityHigh, task.Priority)
+
+	// requested again while queued, the translation is queued once
+	rr = serve("/v2/pokemon/translated/Mewtwo", true, testKey)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, queue.Path+"/"+task.ID, rr.Header().Get("Location"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Queue.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		return serve(queue.Path+"/"+task.ID, false, testKey).Code == http.StatusSeeOther
+	}, time.Second, time.Millisecond)
+	task, _ = service.Queue.Lookup(task.ID, "")
+	assert.Equal(t, 2, task.Attempts)
+	// only the attempt translating the description is reported
+	p := <-translated
+	assert.Equal(t, "Created by a scientist, it was.", p.Description)
+	assert.Empty(t, translated)
+
+	// the translation is cached, the request is answered with it
+	rr = serve(task.Location, true, testKey)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res pokemon.PokemonV2
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, "Created by a scientist, it was.", res.Description)
+	assert.True(t, res.Translation.Translated)
+}
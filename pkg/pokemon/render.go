@@ -0,0 +1,41 @@
+package pokemon
+
+import (
+	"net/http"
+	"pokedex-clone/pkg/format"
+
+	"github.com/gin-gonic/gin"
+)
+
+// negotiate returns the format of the response to c, or writes a 406 Not Acceptable when no format
+// is acceptable to the client.
+func negotiate(c *gin.Context) (format.Format, bool) {
+	f, err := format.FromRequest(c.Request)
+	if err != nil {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	return f, true
+}
+
+// marshal returns v in f, writing a 500 when it can't be marshalled.
+func marshal(c *gin.Context, f format.Format, v interface{}) ([]byte, bool) {
+	body, err := f.Marshal(v)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	// the representation depends on the Accept header, shared caches must key on it too
+	c.Header("Vary", "Accept")
+
+	return body, true
+}
+
+// render writes v in f with status.
+func render(c *gin.Context, f format.Format, status int, v interface{}) {
+	if body, ok := marshal(c, f, v); ok {
+		c.Data(status, f.ContentType(), body)
+	}
+}
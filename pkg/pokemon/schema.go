@@ -1,12 +1,122 @@
 package pokemon
 
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// NameURI holds a species name or national dex number, see ParseIdentifier.
 type NameURI struct {
-	Name string `uri:"name" binding:"required,alpha"`
+	Name string `uri:"name" binding:"required,max=64"`
 }
 
+// Pokemon is a species as served in every format, the field names are part of the API.
 type Pokemon struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Habitat     string `json:"habitat"`
-	IsLegendary bool   `json:"is_legendary"`
+	XMLName     xml.Name `json:"-" yaml:"-" xml:"pokemon"`
+	Name        string   `json:"name" yaml:"name" xml:"name"`
+	Description string   `json:"description" yaml:"description" xml:"description"`
+	Habitat     string   `json:"habitat" yaml:"habitat" xml:"habitat"`
+	IsLegendary bool     `json:"is_legendary" yaml:"is_legendary" xml:"is_legendary"`
+	// ID, Translation and Types are only part of the v2 responses, see PokemonV2.
+	ID          int              `json:"-" yaml:"-" xml:"-"`
+	Translation *TranslationInfo `json:"-" yaml:"-" xml:"-"`
+	// Types are those of the default pokemon of the species by slot, nil until they are fetched.
+	Types []string `json:"-" yaml:"-" xml:"-"`
+}
+
+// TranslationInfo tells how the description of a translated pokemon was produced.
+type TranslationInfo struct {
+	// Type is the translation applied to english descriptions, e.g. yoda, empty for the others.
+	Type       string `json:"type,omitempty" yaml:"type,omitempty" xml:"type,omitempty"`
+	Translated bool   `json:"translated" yaml:"translated" xml:"translated"`
+}
+
+func (p *Pokemon) CSVHeader() []string {
+	return []string{"name", "description", "habitat", "is_legendary"}
+}
+
+func (p *Pokemon) CSVRecords() [][]string {
+	return [][]string{{p.Name, p.Description, p.Habitat, strconv.FormatBool(p.IsLegendary)}}
+}
+
+// ListQuery holds the pagination and filter parameters of the species listing.
+type ListQuery struct {
+	Offset     int    `form:"offset" binding:"min=0"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Generation string `form:"generation" binding:"max=64"`
+	Habitat    string `form:"habitat" binding:"max=64"`
+	Legendary  *bool  `form:"legendary"`
+}
+
+// PokemonList is a single page of the species listing. Count is omitted when it can't be
+// known without fetching every species, which is the case when filtering by legendary status.
+// Written as CSV, a page is the header and a record per result, without the pagination links.
+type PokemonList struct {
+	XMLName  xml.Name          `json:"-" yaml:"-" xml:"pokemon_list"`
+	Count    *int              `json:"count,omitempty" yaml:"count,omitempty" xml:"count,omitempty"`
+	Next     string            `json:"next,omitempty" yaml:"next,omitempty" xml:"next,omitempty"`
+	Previous string            `json:"previous,omitempty" yaml:"previous,omitempty" xml:"previous,omitempty"`
+	Results  []PokemonListItem `json:"results" yaml:"results" xml:"results>pokemon"`
+}
+
+func (l *PokemonList) CSVHeader() []string {
+	return []string{"id", "name", "url"}
+}
+
+func (l *PokemonList) CSVRecords() [][]string {
+	records := make([][]string, 0, len(l.Results))
+	for _, item := range l.Results {
+		records = append(records, []string{strconv.Itoa(item.ID), item.Name, item.URL})
+	}
+
+	return records
+}
+
+type PokemonListItem struct {
+	ID   int    `json:"id" yaml:"id" xml:"id"`
+	Name string `json:"name" yaml:"name" xml:"name"`
+	URL  string `json:"url" yaml:"url" xml:"url"`
+}
+
+// SearchQuery holds the parameters of the name autocomplete endpoint.
+type SearchQuery struct {
+	Q     string `form:"q" binding:"required,max=64"`
+	Mode  string `form:"mode" binding:"omitempty,oneof=prefix fuzzy"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type SearchResults struct {
+	Query   string            `json:"query"`
+	Mode    string            `json:"mode"`
+	Results []PokemonListItem `json:"results"`
+}
+
+// NotFound is returned when a pokemon can't be found, with the closest indexed names ranked first.
+type NotFound struct {
+	Error       string            `json:"error"`
+	Suggestions []PokemonListItem `json:"suggestions,omitempty"`
+}
+
+// DescriptionQuery holds the parameters of the full-text description search.
+type DescriptionQuery struct {
+	Q        string `form:"q" binding:"required,max=128"`
+	Language string `form:"lang" binding:"max=16"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type DescriptionResults struct {
+	Query   string             `json:"query"`
+	Results []DescriptionMatch `json:"results"`
+}
+
+// DescriptionMatch is a pokemon whose description matched the query, Snippet is HTML escaped and has the
+// matched words wrapped in <em> tags.
+type DescriptionMatch struct {
+	ID       int     `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	URL      string  `json:"url"`
+	Score    float64 `json:"score"`
+	Language string  `json:"language"`
+	Version  string  `json:"version"`
+	Snippet  string  `json:"snippet"`
 }
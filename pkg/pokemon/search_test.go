@@ -0,0 +1,179 @@
+package pokemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIndexedService(t *testing.T) (*pokemon.Service, *mocks.MockPokeAPI) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(&api.NamedAPIResourceList{
+		Count: 7,
+		Results: []api.NamedAPIResource{
+			speciesResource(1, "bulbasaur"),
+			speciesResource(4, "charmander"),
+			speciesResource(5, "charmeleon"),
+			speciesResource(25, "pikachu"),
+			speciesResource(26, "raichu"),
+			speciesResource(122, "mr-mime"),
+			speciesResource(172, "pichu"),
+		},
+	}, nil)
+	assert.Nil(t, service.Index.Refresh(context.Background()))
+
+	return service, mockPokeAPI
+}
+
+func TestSearchPokemon(t *testing.T) {
+	tests := map[string]struct {
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		"prefix mode is the default": {
+			query:      "?q=char",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"charmander", "charmeleon"},
+		},
+		"prefix query is canonicalized": {
+			query:      "?q=Mr.%20M",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"mr-mime"},
+		},
+		"fuzzy mode ranks the closest name first": {
+			query:      "?q=pikachoo&mode=fuzzy",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"pikachu"},
+		},
+		"fuzzy mode tolerates a missing letter": {
+			query:      "?q=charmnder&mode=fuzzy&limit=1",
+			wantStatus: http.StatusOK,
+			wantNames:  []string{"charmander"},
+		},
+		"unknown mode returns 400": {
+			query:      "?q=pika&mode=regex",
+			wantStatus: http.StatusBadRequest,
+		},
+		"missing query returns 400": {
+			query:      "",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, _ := newIndexedService(t)
+
+			router := gin.Default()
+			router.GET("/pokemon/search", service.Search)
+
+			req, err := http.NewRequest(http.MethodGet, "/pokemon/search"+tc.query, nil)
+			assert.Nil(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, tc.wantStatus, rr.Code)
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var results pokemon.SearchResults
+			err = json.Unmarshal(rr.Body.Bytes(), &results)
+			assert.Nil(t, err)
+
+			names := make([]string, 0, len(results.Results))
+			for _, r := range results.Results {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, tc.wantNames, names)
+		})
+	}
+}
+
+func TestGetPokemonSuggestsOnMiss(t *testing.T) {
+	service, mockPokeAPI := newIndexedService(t)
+
+	router := gin.Default()
+	router.GET("/pokemon/:name", service.Get)
+
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "pikachoo").Return(nil, api.ErrNotFound)
+
+	req, err := http.NewRequest(http.MethodGet, "/pokemon/pikachoo", nil)
+	assert.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	var notFound pokemon.NotFound
+	err = json.Unmarshal(rr.Body.Bytes(), &notFound)
+	assert.Nil(t, err)
+
+	assert.NotEmpty(t, notFound.Suggestions)
+	assert.Equal(t, "pikachu", notFound.Suggestions[0].Name)
+}
+
+func TestSearchDescriptionsIndexesFetchedSpecies(t *testing.T) {
+	service, mockPokeAPI := newIndexedService(t)
+
+	router := gin.Default()
+	router.GET("/pokemon/:name", service.Get)
+	router.GET("/search", service.SearchDescriptions)
+
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "charmander").Return(&api.PokemonSpecies{
+		Name: "charmander",
+		FlavorTextEntries: []api.FlavorText{
+			{
+				FlavorText: "The flame at the tip of its tail makes a sound as it burns.",
+				Language:   api.NamedAPIResource{Name: "en"},
+				Version:    api.NamedAPIResource{Name: "x"},
+			},
+		},
+	}, nil)
+
+	search := func() pokemon.DescriptionResults {
+		req, err := http.NewRequest(http.MethodGet, "/search?q=fire+flames", nil)
+		assert.Nil(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var results pokemon.DescriptionResults
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &results))
+
+		return results
+	}
+
+	assert.Empty(t, search().Results)
+
+	req, err := http.NewRequest(http.MethodGet, "/pokemon/charmander", nil)
+	assert.Nil(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	results := search().Results
+	assert.Len(t, results, 1)
+	assert.Equal(t, "charmander", results[0].Name)
+	assert.Equal(t, 4, results[0].ID)
+	assert.Equal(t, "The <em>flame</em> at the tip of its tail makes a sound as it burns.", results[0].Snippet)
+}
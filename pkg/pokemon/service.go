@@ -6,12 +6,19 @@ package pokemon
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
 	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/search"
 	"pokedex-clone/pkg/storage"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
 )
 
 const (
@@ -22,34 +29,94 @@ type Service struct {
 	StorageAPI      *storage.Store
 	PokeAPI         api.PokeAPI
 	TranslationsAPI api.TranslationsAPI
+	Index           *Index
+	SearchIndex     *search.Index
+	// Queue takes the translations of the translated requests preferring to be answered asynchronously, the
+	// translations are always fetched while serving the requests when nil.
+	Queue *queue.Queue
+	// OnTranslated is called with every pokemon whose description the translations API translated, e.g. to notify
+	// the webhook subscriptions, when not nil.
+	OnTranslated func(p Pokemon)
+	// Tracer creates the spans of cache lookups, NewService sets one that records nothing.
+	Tracer trace.Tracer
+	// Logger logs the failures requests recover from, NewService sets the default logger.
+	Logger   *slog.Logger
+	settings atomic.Value
 }
 
 func NewService(storage *storage.Store, pokeAPI api.PokeAPI, translationsAPI api.TranslationsAPI) *Service {
-	return &Service{
+	s := &Service{
 		StorageAPI:      storage,
 		PokeAPI:         pokeAPI,
 		TranslationsAPI: translationsAPI,
+		Index:           NewIndex(pokeAPI),
+		SearchIndex:     search.NewIndex(),
+		Tracer:          trace.NewNoopTracerProvider().Tracer(""),
+		Logger:          slog.Default(),
 	}
+	s.SetSettings(DefaultSettings())
+	s.Index.LoadLegendary = s.fetchLegendary
+
+	return s
 }
 
+// Get serves a pokemon in its v1 shape.
 func (s *Service) Get(c *gin.Context) {
+	s.get(c, v1{})
+}
+
+func (s *Service) get(c *gin.Context, presenter Presenter) {
+	f, ok := negotiate(c)
+	if !ok {
+		return
+	}
+
 	var req NameURI
 	if err := c.ShouldBindUri(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	name := req.Name
+	ident, err := ParseIdentifier(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if cachedPokemon, ok := s.StorageAPI.Load(name); ok {
-		c.JSON(http.StatusOK, cachedPokemon)
+	pokemon, err := s.fetchPokemon(c.Request.Context(), ident, presenter.WithTypes())
+	if err != nil {
+		s.abortFetch(c, ident, err)
 		return
 	}
 
-	pokemonSpecies, err := s.PokeAPI.GetSpecies(context.Background(), name)
+	s.respond(c, f, presenter.Pokemon(c.Request.Context(), pokemon, false), pokemon.Name)
+}
+
+// fetchPokemon returns the cached pokemon for the given identifier, or fetches its species
+// from the pokeapi and caches the result under its canonical name. withTypes fetches the types
+// along with the species, which are cached together.
+func (s *Service) fetchPokemon(ctx context.Context, ident Identifier, withTypes bool) (*Pokemon, error) {
+	name, known := s.lookupName(ctx, ident)
+	if known {
+		if cachedPokemon, ok := s.cacheLoad(ctx, name); ok {
+			if p, isPokemon := cachedPokemon.(*Pokemon); isPokemon {
+				if withTypes && p.Types == nil {
+					return s.addTypes(ctx, name, p), nil
+				}
+				return p, nil
+			}
+		}
+	}
+
+	pokemonSpecies, types, err := s.fetchSpecies(ctx, ident, withTypes)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusNotFound, err)
+		return nil, err
 	}
 
+	if !known {
+		name = s.rememberName(ctx, ident, pokemonSpecies.Name)
+	}
+	s.indexDescriptions(pokemonSpecies)
+
 	descriptionText, _ := getFirstEnglishFlavorText(pokemonSpecies.FlavorTextEntries)
 
 	pokemon := Pokemon{
@@ -57,58 +124,161 @@ func (s *Service) Get(c *gin.Context) {
 		IsLegendary: pokemonSpecies.IsLegendary,
 		Habitat:     pokemonSpecies.Habitat.Name,
 		Name:        pokemonSpecies.Name,
+		ID:          pokemonSpecies.ID,
+		Types:       types,
+	}
+
+	if cacheErr := s.cacheSave(ctx, name, &pokemon, s.Settings().SpeciesTTL, SourcePokeAPI); cacheErr != nil {
+		s.Logger.ErrorCtx(ctx, "failed to save species in cache", "name", name, "error", cacheErr)
+	}
+
+	return &pokemon, nil
+}
+
+// FetchPokemon returns the pokemon named raw, a species name or national dex number, as served by Get.
+// It fails with ErrInvalidIdentifier when raw can't name a species.
+func (s *Service) FetchPokemon(ctx context.Context, raw string) (*Pokemon, error) {
+	ident, err := ParseIdentifier(raw)
+	if err != nil {
+		return nil, err
 	}
 
-	if cacheErr := s.StorageAPI.Save(name, &pokemon); cacheErr != nil {
-		log.Printf("failed to save %s in cache: [%v]", name, cacheErr.Error())
+	return s.fetchPokemon(ctx, ident, false)
+}
+
+// FetchTranslated returns the pokemon named raw with its description translated, as served by GetTranslated.
+// It fails with ErrInvalidIdentifier when raw can't name a species.
+func (s *Service) FetchTranslated(ctx context.Context, raw string) (*Pokemon, error) {
+	ident, err := ParseIdentifier(raw)
+	if err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, pokemon)
+	p, _, err := s.fetchTranslated(ctx, ident, translateOptions{})
+
+	return p, err
 }
 
+// GetTranslated serves a pokemon with its description translated, in its v1 shape.
 func (s *Service) GetTranslated(c *gin.Context) {
+	s.getTranslated(c, v1{})
+}
+
+func (s *Service) getTranslated(c *gin.Context, presenter Presenter) {
+	f, ok := negotiate(c)
+	if !ok {
+		return
+	}
+
 	var req NameURI
 	if err := c.ShouldBindUri(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	name := req.Name
+	ident, err := ParseIdentifier(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var task *queue.Task
+	opts := translateOptions{types: presenter.WithTypes()}
+	if s.Queue != nil && PrefersAsync(c) {
+		opts.queue = func(name string) bool {
+			task = s.queueTranslation(c, name)
+			return task != nil
+		}
+	}
+
+	p, _, err := s.fetchTranslated(c.Request.Context(), ident, opts)
+	if errors.Is(err, errQueued) {
+		c.Header("Preference-Applied", preferAsync)
+		c.Header("Location", queue.Path+"/"+task.ID)
+		c.JSON(http.StatusAccepted, task)
+		return
+	}
+	if err != nil {
+		s.abortFetch(c, ident, err)
+		return
+	}
+
+	translationType := s.Settings().Translation.For(p.Habitat, p.IsLegendary)
+	s.respond(c, f, presenter.Pokemon(c.Request.Context(), p, true), p.Name+string(translationType))
+}
 
+// translateOptions change how fetchTranslated handles the translations that aren't cached.
+type translateOptions struct {
+	// queue is offered the translation before fetching it, fetchTranslated returns errQueued when it takes it.
+	queue func(name string) bool
+	// strict fails when the translations API does, rather than serving and caching the description untranslated.
+	strict bool
+	// types fetches the types of the pokemon, which are cached along with its translation.
+	types bool
+}
+
+// fetchTranslated returns the pokemon for the given identifier with its description translated
+// according to its habitat and legendary status, reporting whether the translations API was called.
+func (s *Service) fetchTranslated(
+	ctx context.Context,
+	ident Identifier,
+	opts translateOptions,
+) (*Pokemon, bool, error) {
 	// we can potentially avoid this API call if Get was called before
-	pokemonSpec, err := s.PokeAPI.GetSpecies(context.Background(), name)
+	pokemonSpec, err := s.PokeAPI.GetSpecies(ctx, ident.String())
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusNotFound, err)
+		return nil, false, err
 	}
 
+	name, known := s.lookupName(ctx, ident)
+	if !known {
+		name = s.rememberName(ctx, ident, pokemonSpec.Name)
+	}
+	s.indexDescriptions(pokemonSpec)
+
 	// check description text and maybe skip API calls
 	descriptionText, languageCode := getFirstEnglishFlavorText(pokemonSpec.FlavorTextEntries)
 	if languageCode != ISO639ENGString {
-		c.JSON(http.StatusOK, Pokemon{
+		p := &Pokemon{
 			Description: descriptionText,
 			IsLegendary: pokemonSpec.IsLegendary,
 			Habitat:     pokemonSpec.Habitat.Name,
 			Name:        pokemonSpec.Name,
-		})
-		return
+			ID:          pokemonSpec.ID,
+			Translation: &TranslationInfo{},
+		}
+		if opts.types {
+			p.Types = s.FetchTypes(ctx, &Pokemon{Name: p.Name, ID: p.ID})
+		}
+		return p, false, nil
 	}
 
-	var translationType api.TranslationType
-	if pokemonSpec.Habitat.Name == "cave" || pokemonSpec.IsLegendary {
-		// use yoda translation
-		translationType = api.TTypeYoda
-	} else {
-		// use Shakespeare translation
-		translationType = api.TTypeShakespeare
+	settings := s.Settings()
+	translationType := settings.Translation.For(pokemonSpec.Habitat.Name, pokemonSpec.IsLegendary)
+
+	if cachedPokemonWithTrans, ok := s.cacheLoad(ctx, name+string(translationType)); ok {
+		if cached, isPokemon := cachedPokemonWithTrans.(*Pokemon); isPokemon {
+			if opts.types && cached.Types == nil {
+				return s.addTypes(ctx, name+string(translationType), cached), false, nil
+			}
+			return cached, false, nil
+		}
 	}
 
-	if cachedPokemonWithTrans, ok := s.StorageAPI.Load(name + string(translationType)); ok {
-		c.JSON(http.StatusOK, cachedPokemonWithTrans)
-		return
+	if opts.queue != nil && opts.queue(name) {
+		return nil, false, errQueued
 	}
 
-	response, tErr := s.TranslationsAPI.GetTranslation(context.Background(), name, descriptionText, translationType)
-	if tErr == nil && response.Success.Total > 0 {
+	source := SourceUntranslated
+	response, tErr := s.TranslationsAPI.GetTranslation(ctx, name, descriptionText, translationType)
+	if tErr != nil && opts.strict {
+		return nil, true, tErr
+	}
+	if tErr != nil {
+		s.Logger.WarnCtx(ctx, "failed to translate description, serving it untranslated",
+			"name", name, "translation", translationType, "error", tErr)
+	} else if response.Success.Total > 0 {
 		descriptionText = response.Contents.Translated
+		source = SourceTranslations
 	}
 
 	p := Pokemon{
@@ -116,13 +286,60 @@ func (s *Service) GetTranslated(c *gin.Context) {
 		IsLegendary: pokemonSpec.IsLegendary,
 		Habitat:     pokemonSpec.Habitat.Name,
 		Name:        pokemonSpec.Name,
+		ID:          pokemonSpec.ID,
+		Translation: &TranslationInfo{
+			Type:       strings.TrimSuffix(string(translationType), ".json"),
+			Translated: source == SourceTranslations,
+		},
+	}
+	if opts.types {
+		p.Types = s.fetchTypes(ctx, &p)
+	}
+
+	if cacheErr := s.cacheSave(ctx, name+string(translationType), &p, settings.TranslationTTL, source); cacheErr != nil {
+		s.Logger.ErrorCtx(ctx, "failed to save translation in cache", "name", name, "error", cacheErr)
+	}
+	if source == SourceTranslations && s.OnTranslated != nil {
+		s.OnTranslated(p)
+	}
+
+	return &p, true, nil
+}
+
+// lookupName returns the canonical name for ident when it can be resolved without the pokeapi,
+// either because ident is a name already or because its dex number was seen before.
+func (s *Service) lookupName(ctx context.Context, ident Identifier) (string, bool) {
+	if ident.Name != "" {
+		return ident.Name, true
 	}
 
-	if cacheErr := s.StorageAPI.Save(name+string(translationType), &p); cacheErr != nil {
-		log.Printf("failed to save %s in cache: [%v]", name, cacheErr.Error())
+	if entry, ok := s.Index.LookupID(ident.ID); ok {
+		return entry.Name, true
 	}
 
-	c.JSON(http.StatusOK, p)
+	if cached, ok := s.cacheLoad(ctx, dexNumberKey(ident.ID)); ok {
+		if name, isName := cached.(string); isName {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// rememberName records the canonical name of a species fetched by dex number, so later
+// lookups by number share the cache entries of lookups by name.
+func (s *Service) rememberName(ctx context.Context, ident Identifier, name string) string {
+	if ident.ID > 0 {
+		if cacheErr := s.cacheSave(ctx, dexNumberKey(ident.ID), name, 0, SourcePokeAPI); cacheErr != nil {
+			s.Logger.ErrorCtx(ctx, "failed to save dex number in cache", "id", ident.ID, "error", cacheErr)
+		}
+	}
+
+	return name
+}
+
+func dexNumberKey(id int) string {
+	return "id/" + strconv.Itoa(id)
 }
 
 func getFirstEnglishFlavorText(entries []api.FlavorText) (string, string) {
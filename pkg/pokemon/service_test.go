@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"pokedex-clone/pkg/api"
 	"pokedex-clone/pkg/api/mocks"
 	"pokedex-clone/pkg/pokemon"
@@ -53,7 +54,14 @@ func TestGetPokemon(t *testing.T) {
 			wantStatus:        http.StatusNotFound,
 			wantErr:           nil,
 			getSpeciesReturns: nil,
-			getSpeciesErr:     gin.Error{Err: fmt.Errorf("not found")},
+			getSpeciesErr:     &gin.Error{Err: api.ErrNotFound},
+		},
+		"pokemon returns 502 when get species fails otherwise": {
+			name:              "mewtwo",
+			wantStatus:        http.StatusBadGateway,
+			wantErr:           nil,
+			getSpeciesReturns: nil,
+			getSpeciesErr:     fmt.Errorf("unknown error, status code: 503"),
 		},
 	}
 
@@ -92,8 +100,18 @@ func TestGetPokemonFails(t *testing.T) {
 		wantStatus int
 		wantErr    error
 	}{
-		"get pokemon fails 400 with non-alphanumeric name": {
-			name:       "123",
+		"get pokemon fails 400 with punctuation in name": {
+			name:       "pika$chu",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    nil,
+		},
+		"get pokemon fails 400 with dex number zero": {
+			name:       "0",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    nil,
+		},
+		"get pokemon fails 400 with dangling hyphen": {
+			name:       "ho-",
 			wantStatus: http.StatusBadRequest,
 			wantErr:    nil,
 		},
@@ -115,7 +133,7 @@ func TestGetPokemonFails(t *testing.T) {
 			req, err := http.NewRequest(http.MethodGet, "/pokemon/"+tc.name, nil)
 			assert.Equal(t, err, tc.wantErr)
 
-			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), tc.name).Times(0)
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).Times(0)
 
 			rr := httptest.NewRecorder()
 			router.ServeHTTP(rr, req)
@@ -124,6 +142,68 @@ func TestGetPokemonFails(t *testing.T) {
 	}
 }
 
+func TestGetPokemonByIdentifier(t *testing.T) {
+	tests := map[string]struct {
+		identifiers   []string
+		wantSpeciesID string
+		wantName      string
+	}{
+		"dex number, capitalized and lowercase names share one cache entry": {
+			identifiers:   []string{"25", "Pikachu", "pikachu"},
+			wantSpeciesID: "25",
+			wantName:      "pikachu",
+		},
+		"hyphenated names are accepted": {
+			identifiers:   []string{"ho-oh", "Ho-Oh"},
+			wantSpeciesID: "ho-oh",
+			wantName:      "ho-oh",
+		},
+		"display names are canonicalized": {
+			identifiers:   []string{"Mr. Mime", "mr-mime"},
+			wantSpeciesID: "mr-mime",
+			wantName:      "mr-mime",
+		},
+		"accents are stripped": {
+			identifiers:   []string{"Flabébé", "flabebe"},
+			wantSpeciesID: "flabebe",
+			wantName:      "flabebe",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			storageAPI := storage.NewStore()
+
+			ctrl := gomock.NewController(t)
+
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			service := pokemon.NewService(storageAPI, mockPokeAPI, mockTranslationsAPI)
+
+			router := gin.Default()
+			router.GET("/pokemon/:name", service.Get)
+
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), tc.wantSpeciesID).Return(&api.PokemonSpecies{
+				Name: tc.wantName,
+			}, nil).Times(1)
+
+			for _, identifier := range tc.identifiers {
+				req, err := http.NewRequest(http.MethodGet, "/pokemon/"+url.PathEscape(identifier), nil)
+				assert.Nil(t, err)
+
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusOK, rr.Code)
+
+				var pokemon pokemon.Pokemon
+				err = json.Unmarshal(rr.Body.Bytes(), &pokemon)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.wantName, pokemon.Name)
+			}
+		})
+	}
+}
+
 func TestPokemonTranslation200(t *testing.T) {
 	yodaTranslatedPokemon := pokemon.Pokemon{
 		Name:        "mewtwo",
@@ -453,3 +533,89 @@ func TestFailedPokemonTranslationReturnsDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslationRules(t *testing.T) {
+	rules := pokemon.TranslationRules{
+		Habitats: []string{"cave", "rare"},
+		Special:  api.TTypeShakespeare,
+		Default:  api.TTypeYoda,
+	}
+
+	tests := map[string]struct {
+		habitat     string
+		isLegendary bool
+		want        api.TranslationType
+	}{
+		"special habitat": {
+			habitat: "rare",
+			want:    api.TTypeShakespeare,
+		},
+		"legendary species aren't special unless configured": {
+			habitat:     "forest",
+			isLegendary: true,
+			want:        api.TTypeYoda,
+		},
+		"other habitats get the default": {
+			habitat: "forest",
+			want:    api.TTypeYoda,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rules.For(tc.habitat, tc.isLegendary))
+		})
+	}
+}
+
+func TestSetSettingsAppliesToLaterRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+
+	router := gin.Default()
+	router.GET("/pokemon/translated/:name", service.GetTranslated)
+
+	species := &api.PokemonSpecies{
+		Name: "thepoet",
+		FlavorTextEntries: []api.FlavorText{
+			{FlavorText: "Some text.", Language: api.NamedAPIResource{Name: "en"}},
+		},
+		Habitat: api.NamedAPIResource{Name: "somewhere"},
+	}
+	translated := func(text string) *api.TranslateAPIResponse {
+		return &api.TranslateAPIResponse{Success: api.Success{Total: 1}, Contents: api.Contents{Translated: text}}
+	}
+
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "thepoet").Return(species, nil).Times(2)
+	gomock.InOrder(
+		mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "thepoet", "Some text.", api.TTypeShakespeare).
+			Return(translated("Some text, forsooth."), nil),
+		mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), "thepoet", "Some text.", api.TTypeYoda).
+			Return(translated("Some text, it is."), nil),
+	)
+
+	get := func() string {
+		req, err := http.NewRequest(http.MethodGet, "/pokemon/translated/thepoet", nil)
+		assert.Nil(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var p pokemon.Pokemon
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &p))
+
+		return p.Description
+	}
+
+	assert.Equal(t, "Some text, forsooth.", get())
+
+	settings := service.Settings()
+	settings.Translation.Default = api.TTypeYoda
+	service.SetSettings(settings)
+
+	assert.Equal(t, "Some text, it is.", get())
+}
@@ -0,0 +1,67 @@
+package pokemon
+
+import (
+	"pokedex-clone/pkg/api"
+	"time"
+)
+
+// Settings are the service options that can change while it is running, see Service.SetSettings.
+type Settings struct {
+	// SpeciesTTL is how long fetched species stay cached, zero caches them forever.
+	SpeciesTTL time.Duration
+	// TranslationTTL is how long translated descriptions stay cached, zero caches them forever.
+	TranslationTTL time.Duration
+	// Translation picks the translation applied to each species.
+	Translation TranslationRules
+}
+
+// TranslationRules picks the translation of a species description from its habitat and legendary status.
+type TranslationRules struct {
+	// Habitats translated with Special, e.g. cave.
+	Habitats []string
+	// Legendary species are translated with Special when set.
+	Legendary bool
+	// Special is the translation for the species matched by Habitats or Legendary, e.g. yoda.
+	Special api.TranslationType
+	// Default is the translation for every other species, e.g. shakespeare.
+	Default api.TranslationType
+}
+
+// DefaultSettings caches forever and translates cave and legendary species to yoda, every other
+// species to shakespeare.
+func DefaultSettings() Settings {
+	return Settings{
+		Translation: TranslationRules{
+			Habitats:  []string{"cave"},
+			Legendary: true,
+			Special:   api.TTypeYoda,
+			Default:   api.TTypeShakespeare,
+		},
+	}
+}
+
+// For returns the translation for a species living in habitat.
+func (r TranslationRules) For(habitat string, isLegendary bool) api.TranslationType {
+	if isLegendary && r.Legendary {
+		return r.Special
+	}
+
+	for _, h := range r.Habitats {
+		if h == habitat {
+			return r.Special
+		}
+	}
+
+	return r.Default
+}
+
+// Settings returns the settings in use.
+func (s *Service) Settings() Settings {
+	settings, _ := s.settings.Load().(Settings)
+	return settings
+}
+
+// SetSettings replaces the settings in use, requests already being served keep the previous ones.
+func (s *Service) SetSettings(settings Settings) {
+	s.settings.Store(settings)
+}
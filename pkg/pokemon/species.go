@@ -0,0 +1,70 @@
+package pokemon
+
+import (
+	"context"
+	"pokedex-clone/pkg/api"
+	"strconv"
+)
+
+// Cache key prefixes of the pokeapi resources served as they are, e.g. by the GraphQL endpoint.
+const (
+	speciesPrefix = "species/"
+	chainPrefix   = "evolution-chain/"
+)
+
+// FetchSpecies returns the pokeapi species named raw, a species name or national dex number, from the cache or
+// the pokeapi. It fails with ErrInvalidIdentifier when raw can't name a species.
+func (s *Service) FetchSpecies(ctx context.Context, raw string) (*api.PokemonSpecies, error) {
+	ident, err := ParseIdentifier(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	name, known := s.lookupName(ctx, ident)
+	if known {
+		if cached, ok := s.cacheLoad(ctx, speciesPrefix+name); ok {
+			if species, isSpecies := cached.(*api.PokemonSpecies); isSpecies {
+				return species, nil
+			}
+		}
+	}
+
+	species, err := s.PokeAPI.GetSpecies(ctx, ident.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if !known {
+		name = s.rememberName(ctx, ident, species.Name)
+	}
+	s.indexDescriptions(species)
+
+	cacheErr := s.cacheSave(ctx, speciesPrefix+name, species, s.Settings().SpeciesTTL, SourcePokeAPI)
+	if cacheErr != nil {
+		s.Logger.ErrorCtx(ctx, "failed to save species in cache", "name", name, "error", cacheErr)
+	}
+
+	return species, nil
+}
+
+// FetchEvolutionChain returns the pokeapi evolution chain numbered id, from the cache or the pokeapi. Chains are
+// cached as long as the species are.
+func (s *Service) FetchEvolutionChain(ctx context.Context, id int) (*api.EvolutionChain, error) {
+	key := chainPrefix + strconv.Itoa(id)
+	if cached, ok := s.cacheLoad(ctx, key); ok {
+		if chain, isChain := cached.(*api.EvolutionChain); isChain {
+			return chain, nil
+		}
+	}
+
+	chain, err := s.PokeAPI.GetEvolutionChain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheErr := s.cacheSave(ctx, key, chain, s.Settings().SpeciesTTL, SourcePokeAPI); cacheErr != nil {
+		s.Logger.ErrorCtx(ctx, "failed to save evolution chain in cache", "id", id, "error", cacheErr)
+	}
+
+	return chain, nil
+}
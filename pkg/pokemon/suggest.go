@@ -0,0 +1,189 @@
+package pokemon
+
+import (
+	"sort"
+	"strings"
+)
+
+// Search modes supported by Index.Search.
+const (
+	SearchModePrefix = "prefix"
+	SearchModeFuzzy  = "fuzzy"
+)
+
+type rankedEntry struct {
+	entry    IndexEntry
+	distance int
+	score    int
+}
+
+// Search returns up to limit indexed species matching query, which should already be canonical.
+// Prefix mode returns the species whose name starts with query in dex order, fuzzy mode ranks
+// species by edit distance, favoring names that sound like query.
+func (i *Index) Search(query, mode string, limit int) []IndexEntry {
+	i.RLock()
+	defer i.RUnlock()
+
+	if mode == SearchModeFuzzy {
+		return i.fuzzy(query, limit)
+	}
+
+	matches := make([]IndexEntry, 0, limit)
+	for _, e := range i.entries {
+		if len(matches) == limit {
+			break
+		}
+		if strings.HasPrefix(e.Name, query) {
+			matches = append(matches, e)
+		}
+	}
+
+	return matches
+}
+
+// Suggest returns up to limit "did you mean" candidates for a name that wasn't found.
+func (i *Index) Suggest(name string, limit int) []IndexEntry {
+	i.RLock()
+	defer i.RUnlock()
+
+	return i.fuzzy(name, limit)
+}
+
+// fuzzy ranks the indexed species against query, callers must hold the read lock.
+func (i *Index) fuzzy(query string, limit int) []IndexEntry {
+	maxDistance := len(query) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	querySound := soundex(query)
+
+	var ranked []rankedEntry
+	for _, e := range i.entries {
+		distance := editDistance(query, e.Name)
+		soundsAlike := querySound != "" && soundex(e.Name) == querySound
+
+		if distance > maxDistance && !(soundsAlike && distance <= len(query)/2) {
+			continue
+		}
+
+		score := 2 * distance
+		if soundsAlike {
+			score--
+		}
+		ranked = append(ranked, rankedEntry{entry: e, distance: distance, score: score})
+	}
+
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].score != ranked[b].score {
+			return ranked[a].score < ranked[b].score
+		}
+		if ranked[a].distance != ranked[b].distance {
+			return ranked[a].distance < ranked[b].distance
+		}
+		return ranked[a].entry.ID < ranked[b].entry.ID
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	matches := make([]IndexEntry, 0, len(ranked))
+	for _, r := range ranked {
+		matches = append(matches, r.entry)
+	}
+
+	return matches
+}
+
+// editDistance returns the optimal string alignment distance between a and b, i.e. the
+// Levenshtein distance where swapping two adjacent characters counts as a single edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+// soundexCodes is the soundex digit of every letter from a to z, zero for the vowels, h, w and y.
+var soundexCodes = [26]byte{
+	'b' - 'a': '1', 'f' - 'a': '1', 'p' - 'a': '1', 'v' - 'a': '1',
+	'c' - 'a': '2', 'g' - 'a': '2', 'j' - 'a': '2', 'k' - 'a': '2', 'q' - 'a': '2', 's' - 'a': '2', 'x' - 'a': '2',
+	'z' - 'a': '2',
+	'd' - 'a': '3', 't' - 'a': '3',
+	'l' - 'a': '4',
+	'm' - 'a': '5', 'n' - 'a': '5',
+	'r' - 'a': '6',
+}
+
+// soundex returns the american soundex code of the letters in s, or "" if s has no letters.
+func soundex(s string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			continue
+		}
+
+		digit := soundexCodes[r-'a']
+		if len(code) == 0 {
+			code = append(code, byte(r-'a'+'A'))
+			last = digit
+			continue
+		}
+
+		if digit != 0 && digit != last {
+			code = append(code, digit)
+			if len(code) == cap(code) {
+				break
+			}
+		}
+
+		// h and w don't separate letters with the same code, vowels do
+		if r != 'h' && r != 'w' {
+			last = digit
+		}
+	}
+
+	if len(code) == 0 {
+		return ""
+	}
+
+	for len(code) < cap(code) {
+		code = append(code, '0')
+	}
+
+	return string(code)
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,300 @@
+package pokemon
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"pokedex-clone/pkg/api"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V2Prefix is the path prefix of the v2 routes, whose links point at other v2 routes.
+const V2Prefix = "/v2"
+
+// Presenter shapes the responses of an API version, the handlers fetching what they present are shared.
+type Presenter interface {
+	// Pokemon returns the response serving p, translated tells whether it was requested translated.
+	Pokemon(ctx context.Context, p *Pokemon, translated bool) interface{}
+	List(l *PokemonList) interface{}
+	// WithTypes tells whether the pokemon served carry their types, which are then fetched along with them.
+	WithTypes() bool
+}
+
+// Version serves the pokemon endpoints of an API version.
+type Version struct {
+	service   *Service
+	presenter Presenter
+}
+
+// V1 returns the handlers of the first API version, which serve Pokemon and PokemonList as they are.
+func (s *Service) V1() *Version {
+	return &Version{service: s, presenter: v1{}}
+}
+
+// V2 returns the handlers of the second API version, which serve PokemonV2 and v2 links.
+func (s *Service) V2() *Version {
+	return &Version{service: s, presenter: v2{service: s}}
+}
+
+func (v *Version) Get(c *gin.Context) {
+	v.service.get(c, v.presenter)
+}
+
+func (v *Version) GetTranslated(c *gin.Context) {
+	v.service.getTranslated(c, v.presenter)
+}
+
+func (v *Version) List(c *gin.Context) {
+	v.service.list(c, v.presenter)
+}
+
+type v1 struct{}
+
+func (v1) Pokemon(_ context.Context, p *Pokemon, _ bool) interface{} {
+	return p
+}
+
+func (v1) List(l *PokemonList) interface{} {
+	return l
+}
+
+func (v1) WithTypes() bool {
+	return false
+}
+
+// PokemonV2 is a pokemon as served by the v2 routes.
+type PokemonV2 struct {
+	XMLName     xml.Name `json:"-" yaml:"-" xml:"pokemon"`
+	ID          int      `json:"id" yaml:"id" xml:"id"`
+	Name        string   `json:"name" yaml:"name" xml:"name"`
+	Description string   `json:"description" yaml:"description" xml:"description"`
+	Habitat     string   `json:"habitat" yaml:"habitat" xml:"habitat"`
+	IsLegendary bool     `json:"isLegendary" yaml:"isLegendary" xml:"isLegendary"`
+	// Types are ordered by slot, they are empty when they couldn't be fetched.
+	Types []string `json:"types" yaml:"types" xml:"types>type"`
+	// Translation is only set on translated responses.
+	Translation *TranslationInfo `json:"translation,omitempty" yaml:"translation,omitempty" xml:"translation,omitempty"`
+	Links       Links            `json:"links" yaml:"links" xml:"links"`
+}
+
+// Links are the v2 routes serving a pokemon.
+type Links struct {
+	Self       string `json:"self" yaml:"self" xml:"self"`
+	Pokemon    string `json:"pokemon" yaml:"pokemon" xml:"pokemon"`
+	Translated string `json:"translated" yaml:"translated" xml:"translated"`
+}
+
+// CSVHeader flattens the types into a space separated field, and the translation into its type.
+func (p *PokemonV2) CSVHeader() []string {
+	return []string{"id", "name", "description", "habitat", "isLegendary", "types", "translation", "translated"}
+}
+
+func (p *PokemonV2) CSVRecords() [][]string {
+	var translationType, translated string
+	if p.Translation != nil {
+		translationType, translated = p.Translation.Type, strconv.FormatBool(p.Translation.Translated)
+	}
+
+	return [][]string{{
+		strconv.Itoa(p.ID), p.Name, p.Description, p.Habitat, strconv.FormatBool(p.IsLegendary),
+		strings.Join(p.Types, " "), translationType, translated,
+	}}
+}
+
+type v2 struct {
+	service *Service
+}
+
+func (v v2) Pokemon(_ context.Context, p *Pokemon, translated bool) interface{} {
+	// the types were fetched along with p, they are only missing when they couldn't be
+	types := p.Types
+	if types == nil {
+		types = []string{}
+	}
+
+	escaped := url.PathEscape(p.Name)
+	res := &PokemonV2{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Habitat:     p.Habitat,
+		IsLegendary: p.IsLegendary,
+		Types:       types,
+		Links: Links{
+			Pokemon:    V2Prefix + "/pokemon/" + escaped,
+			Translated: V2Prefix + "/pokemon/translated/" + escaped,
+		},
+	}
+
+	res.Links.Self = res.Links.Pokemon
+	if translated {
+		res.Links.Self = res.Links.Translated
+		res.Translation = p.Translation
+	}
+
+	return res
+}
+
+func (v v2) List(l *PokemonList) interface{} {
+	res := *l
+	res.Results = make([]PokemonListItem, 0, len(l.Results))
+	for _, item := range l.Results {
+		item.URL = V2Prefix + item.URL
+		res.Results = append(res.Results, item)
+	}
+
+	return &res
+}
+
+func (v v2) WithTypes() bool {
+	return true
+}
+
+// FetchTypes returns the types of p, or those cached along with it, which are fetched from the pokeapi and
+// cached when they are missing. Types are left out of the response, rather than failing it, when they can't be
+// fetched.
+func (s *Service) FetchTypes(ctx context.Context, p *Pokemon) []string {
+	if p.Types != nil {
+		return p.Types
+	}
+
+	// the pokemon are cached under their name, and their translation type when translated
+	key := p.Name
+	if key == "" {
+		key, _ = s.lookupName(ctx, Identifier{ID: p.ID})
+	}
+	if p.Translation != nil {
+		key += string(s.Settings().Translation.For(p.Habitat, p.IsLegendary))
+	}
+
+	types := p.Types
+	if cached, ok := s.cacheLoad(ctx, key); ok {
+		if cachedPokemon, isPokemon := cached.(*Pokemon); isPokemon {
+			types = cachedPokemon.Types
+			if types == nil {
+				types = s.addTypes(ctx, key, cachedPokemon).Types
+			}
+		}
+	} else {
+		types = s.fetchTypes(ctx, p)
+	}
+
+	if types == nil {
+		return []string{}
+	}
+
+	return types
+}
+
+// fetchSpecies fetches the species of ident from the pokeapi, along with the types of its default pokemon when
+// withTypes, which are nil when they couldn't be fetched. Both are fetched at once when the dex number is known.
+func (s *Service) fetchSpecies(
+	ctx context.Context,
+	ident Identifier,
+	withTypes bool,
+) (*api.PokemonSpecies, []string, error) {
+	if !withTypes {
+		species, err := s.PokeAPI.GetSpecies(ctx, ident.String())
+		return species, nil, err
+	}
+
+	// the default pokemon of a species shares its id, not always its name, e.g. deoxys-normal
+	known := Pokemon{Name: ident.Name, ID: ident.ID}
+	if entry, ok := s.Index.Lookup(ident.Name); ok && known.ID == 0 {
+		known.ID = entry.ID
+	}
+
+	var types []string
+	var wg sync.WaitGroup
+	if known.ID > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			types = s.fetchTypes(ctx, &known)
+		}()
+	}
+
+	species, err := s.PokeAPI.GetSpecies(ctx, ident.String())
+	wg.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if known.ID == 0 {
+		types = s.fetchTypes(ctx, &Pokemon{Name: species.Name, ID: species.ID})
+	}
+
+	return species, types, nil
+}
+
+// addTypes fetches the types of p, which is cached under key, and caches them along with it until p expires.
+func (s *Service) addTypes(ctx context.Context, key string, p *Pokemon) *Pokemon {
+	types := s.fetchTypes(ctx, p)
+	if types == nil {
+		return p
+	}
+
+	res := *p
+	res.Types = types
+	entry, ok := s.StorageAPI.Inspect(key)
+	if !ok {
+		return &res
+	}
+
+	var ttl time.Duration
+	if !entry.ExpiresAt.IsZero() {
+		if ttl = time.Until(entry.ExpiresAt); ttl <= 0 {
+			return &res
+		}
+	}
+	if cacheErr := s.cacheSave(ctx, key, &res, ttl, entry.Source); cacheErr != nil {
+		s.Logger.ErrorCtx(ctx, "failed to save types in cache", "name", p.Name, "error", cacheErr)
+	}
+
+	return &res
+}
+
+// fetchTypes fetches the types of the default pokemon of p from the pokeapi, ordered by slot, or returns nil when
+// they can't be fetched.
+func (s *Service) fetchTypes(ctx context.Context, p *Pokemon) []string {
+	res, err := s.PokeAPI.GetPokemon(ctx, strconv.Itoa(p.ID))
+	if err != nil {
+		s.Logger.WarnCtx(ctx, "failed to fetch types, serving the pokemon without them", "name", p.Name, "error", err)
+		return nil
+	}
+
+	sort.Slice(res.Types, func(i, j int) bool { return res.Types[i].Slot < res.Types[j].Slot })
+	types := make([]string, len(res.Types))
+	for i, t := range res.Types {
+		types[i] = t.Type.Name
+	}
+
+	return types
+}
+
+// Deprecated is the gin middleware announcing the deprecation of the routes it serves, with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a link to the v2 route succeeding them.
+// Sunset is left out when zero.
+func Deprecated(deprecation, sunset time.Time, prefix string) gin.HandlerFunc {
+	deprecationHeader := "@" + strconv.FormatInt(deprecation.Unix(), 10)
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("Deprecation", deprecationHeader)
+		if !sunset.IsZero() {
+			header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+
+		successor := V2Prefix + strings.TrimPrefix(c.Request.URL.Path, prefix)
+		header.Add("Link", "<"+successor+`>; rel="successor-version"`)
+
+		c.Next()
+	}
+}
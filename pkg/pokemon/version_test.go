@@ -0,0 +1,225 @@
+package pokemon_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV2(t *testing.T) {
+	species := &api.PokemonSpecies{
+		ID:          150,
+		Name:        "mewtwo",
+		IsLegendary: true,
+		Habitat:     api.NamedAPIResource{Name: "rare"},
+		FlavorTextEntries: []api.FlavorText{
+			{FlavorText: "It was created by a scientist.", Language: api.NamedAPIResource{Name: "en"}},
+		},
+	}
+	mewtwo := &api.Pokemon{
+		ID:   150,
+		Name: "mewtwo",
+		Types: []api.PokemonType{
+			{Slot: 2, Type: api.NamedAPIResource{Name: "fairy"}},
+			{Slot: 1, Type: api.NamedAPIResource{Name: "psychic"}},
+		},
+	}
+
+	tests := map[string]struct {
+		target      string
+		typesErr    error
+		want        pokemon.PokemonV2
+		expectCalls func(p *mocks.MockPokeAPI, tr *mocks.MockTranslationsAPI)
+	}{
+		"pokemon": {
+			target: "/v2/pokemon/mewtwo",
+			want: pokemon.PokemonV2{
+				ID:          150,
+				Name:        "mewtwo",
+				Description: "It was created by a scientist.",
+				Habitat:     "rare",
+				IsLegendary: true,
+				Types:       []string{"psychic", "fairy"},
+				Links: pokemon.Links{
+					Self:       "/v2/pokemon/mewtwo",
+					Pokemon:    "/v2/pokemon/mewtwo",
+					Translated: "/v2/pokemon/translated/mewtwo",
+				},
+			},
+		},
+		"translated pokemon": {
+			target: "/v2/pokemon/translated/mewtwo",
+			want: pokemon.PokemonV2{
+				ID:          150,
+				Name:        "mewtwo",
+				Description: "Created by a scientist, it was.",
+				Habitat:     "rare",
+				IsLegendary: true,
+				Types:       []string{"psychic", "fairy"},
+				Translation: &pokemon.TranslationInfo{Type: "yoda", Translated: true},
+				Links: pokemon.Links{
+					Self:       "/v2/pokemon/translated/mewtwo",
+					Pokemon:    "/v2/pokemon/mewtwo",
+					Translated: "/v2/pokemon/translated/mewtwo",
+				},
+			},
+			expectCalls: func(_ *mocks.MockPokeAPI, tr *mocks.MockTranslationsAPI) {
+				tr.EXPECT().GetTranslation(gomock.Any(), "mewtwo", gomock.Any(), api.TTypeYoda).Return(
+					&api.TranslateAPIResponse{
+						Success:  api.Success{Total: 1},
+						Contents: api.Contents{Translated: "Created by a scientist, it was."},
+					}, nil)
+			},
+		},
+		"types are left out when they can't be fetched": {
+			target:   "/v2/pokemon/mewtwo",
+			typesErr: fmt.Errorf("unavailable"),
+			want: pokemon.PokemonV2{
+				ID:          150,
+				Name:        "mewtwo",
+				Description: "It was created by a scientist.",
+				Habitat:     "rare",
+				IsLegendary: true,
+				Types:       []string{},
+				Links: pokemon.Links{
+					Self:       "/v2/pokemon/mewtwo",
+					Pokemon:    "/v2/pokemon/mewtwo",
+					Translated: "/v2/pokemon/translated/mewtwo",
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+			mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+			mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "mewtwo").Return(species, nil)
+			mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(mewtwo, tt.typesErr)
+			if tt.expectCalls != nil {
+				tt.expectCalls(mockPokeAPI, mockTranslationsAPI)
+			}
+			service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mockTranslationsAPI)
+
+			router := gin.New()
+			router.GET("/v2/pokemon/:name", service.V2().Get)
+			router.GET("/v2/pokemon/translated/:name", service.V2().GetTranslated)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"isLegendary":true`)
+
+			var got pokemon.PokemonV2
+			assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestV2CachesTypesWithSpecies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	// the types are fetched along with the species, once for both the v1 and v2 routes
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), "150").Return(&api.PokemonSpecies{
+		ID:   150,
+		Name: "mewtwo",
+		FlavorTextEntries: []api.FlavorText{
+			{FlavorText: "It was created by a scientist.", Language: api.NamedAPIResource{Name: "en"}},
+		},
+	}, nil)
+	mockPokeAPI.EXPECT().GetPokemon(gomock.Any(), "150").Return(&api.Pokemon{
+		ID:    150,
+		Name:  "mewtwo",
+		Types: []api.PokemonType{{Slot: 1, Type: api.NamedAPIResource{Name: "psychic"}}},
+	}, nil)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	router := gin.New()
+	router.GET("/pokemon/:name", service.V1().Get)
+	router.GET("/v2/pokemon/:name", service.V2().Get)
+
+	for _, target := range []string{"/v2/pokemon/150", "/v2/pokemon/mewtwo", "/pokemon/mewtwo"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, target)
+		if target != "/pokemon/mewtwo" {
+			assert.Contains(t, rr.Body.String(), `"types":["psychic"]`, target)
+		}
+	}
+}
+
+func TestV2List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, 1).Return(&api.NamedAPIResourceList{
+		Count:   2,
+		Results: []api.NamedAPIResource{speciesResource(150, "mewtwo")},
+	}, nil)
+	service := pokemon.NewService(storage.NewStore(), mockPokeAPI, mocks.NewMockTranslationsAPI(ctrl))
+
+	router := gin.New()
+	router.GET("/v2/pokemon", service.V2().List)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v2/pokemon?limit=1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var page pokemon.PokemonList
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, "/v2/pokemon/mewtwo", page.Results[0].URL)
+	assert.Equal(t, "/v2/pokemon?limit=1&offset=1", page.Next)
+}
+
+func TestDeprecated(t *testing.T) {
+	deprecation := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		prefix        string
+		sunset        time.Time
+		target        string
+		wantSunset    string
+		wantSuccessor string
+	}{
+		"versioned route": {
+			prefix:        "/v1",
+			sunset:        sunset,
+			target:        "/v1/pokemon/mewtwo",
+			wantSunset:    "Mon, 19 Apr 2027 00:00:00 GMT",
+			wantSuccessor: `</v2/pokemon/mewtwo>; rel="successor-version"`,
+		},
+		"unversioned route without a sunset": {
+			target:        "/pokemon/translated/mewtwo",
+			wantSuccessor: `</v2/pokemon/translated/mewtwo>; rel="successor-version"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(pokemon.Deprecated(deprecation, tt.sunset, tt.prefix))
+			router.GET(tt.target, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, "@1792368000", rr.Header().Get("Deprecation"))
+			assert.Equal(t, tt.wantSunset, rr.Header().Get("Sunset"))
+			assert.Equal(t, tt.wantSuccessor, rr.Header().Get("Link"))
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package pokemon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"pokedex-clone/pkg/logging"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const defaultWarmConcurrency = 4
+
+// WarmTarget fills a cache one species at a time. Service implements it in-process, the warm
+// command implements it against a running server.
+type WarmTarget interface {
+	// WarmSpecies caches the species, reporting whether it was already cached.
+	WarmSpecies(ctx context.Context, name string) (bool, error)
+	// WarmTranslated caches the translated species, reporting whether the translations API was called.
+	WarmTranslated(ctx context.Context, name string) (bool, error)
+}
+
+// WarmOptions configures a cache warm-up run.
+type WarmOptions struct {
+	// Concurrency bounds the number of species warmed at the same time.
+	Concurrency int
+	// RequestsPerSecond limits how many species lookups and translations are started per second,
+	// keeping the run under the upstream rate limits. Zero means no limit.
+	RequestsPerSecond float64
+	// TranslationBudget is the number of translations API calls the run may spend,
+	// translations are skipped when it is zero.
+	TranslationBudget int
+	// ProgressFile records every warmed species so an interrupted run can resume where it
+	// stopped, progress isn't persisted when it is empty.
+	ProgressFile string
+	// ProgressEvery logs progress after every n warmed species, zero disables progress logs.
+	ProgressEvery int
+}
+
+// WarmReport summarizes a cache warm-up run.
+type WarmReport struct {
+	Total              int               `json:"total"`
+	Fetched            int               `json:"fetched"`
+	AlreadyCached      int               `json:"already_cached"`
+	Resumed            int               `json:"resumed"`
+	Failed             int               `json:"failed"`
+	Translated         int               `json:"translated"`
+	TranslationSkipped int               `json:"translation_skipped"`
+	Duration           time.Duration     `json:"duration"`
+	Failures           map[string]string `json:"failures,omitempty"`
+}
+
+func (r *WarmReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "warmed %d species in %s: %d fetched, %d already cached, %d resumed, %d failed",
+		r.Total, r.Duration.Round(time.Millisecond), r.Fetched, r.AlreadyCached, r.Resumed, r.Failed)
+	fmt.Fprintf(&b, "; %d translated, %d translations skipped", r.Translated, r.TranslationSkipped)
+
+	names := make([]string, 0, len(r.Failures))
+	for name := range r.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %s", name, r.Failures[name])
+	}
+
+	return b.String()
+}
+
+// WarmSpecies implements WarmTarget through the same logic used by Get.
+func (s *Service) WarmSpecies(ctx context.Context, name string) (bool, error) {
+	if s.StorageAPI.Exist(name) {
+		return true, nil
+	}
+
+	_, err := s.fetchPokemon(ctx, Identifier{Name: name}, false)
+
+	return false, err
+}
+
+// WarmTranslated implements WarmTarget through the same logic used by GetTranslated.
+func (s *Service) WarmTranslated(ctx context.Context, name string) (bool, error) {
+	_, called, err := s.fetchTranslated(ctx, Identifier{Name: name}, translateOptions{})
+
+	return called, err
+}
+
+// WarmCache walks the species index and fills the cache through the service itself.
+func (s *Service) WarmCache(ctx context.Context, opts WarmOptions) (*WarmReport, error) {
+	entries, err := s.Index.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+
+	return Warm(ctx, s, names, opts)
+}
+
+// Warm fills target with every species in names, bounded by the concurrency, rate and
+// translation budget in opts. It stops early when ctx is done and reports what was warmed so far.
+func Warm(ctx context.Context, target WarmTarget, names []string, opts WarmOptions) (*WarmReport, error) {
+	started := time.Now()
+
+	if opts.Concurrency < 1 {
+		opts.Concurrency = defaultWarmConcurrency
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if opts.RequestsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), 1)
+	}
+
+	done, err := readProgress(opts.ProgressFile)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := openProgress(opts.ProgressFile)
+	if err != nil {
+		return nil, err
+	}
+	defer progress.Close()
+
+	w := &warmer{
+		target:   target,
+		limiter:  limiter,
+		budget:   opts.TranslationBudget,
+		progress: progress,
+		report: &WarmReport{
+			Total:    len(names),
+			Failures: make(map[string]string),
+		},
+		every: opts.ProgressEvery,
+	}
+
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range work {
+				w.warm(ctx, name)
+			}
+		}()
+	}
+
+feed:
+	for _, name := range names {
+		if done[name] {
+			w.advance(func(r *WarmReport) { r.Resumed++ })
+			continue
+		}
+
+		select {
+		case work <- name:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	w.report.Duration = time.Since(started)
+
+	return w.report, ctx.Err()
+}
+
+type warmer struct {
+	target   WarmTarget
+	limiter  *rate.Limiter
+	progress *progressFile
+	every    int
+
+	sync.Mutex
+	budget int
+	report *WarmReport
+}
+
+func (w *warmer) warm(ctx context.Context, name string) {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return
+	}
+
+	cached, err := w.target.WarmSpecies(ctx, name)
+	if err != nil {
+		w.fail(name, err)
+		return
+	}
+
+	if w.reserveTranslation() {
+		if err = w.limiter.Wait(ctx); err != nil {
+			w.refundTranslation()
+			return
+		}
+
+		called, tErr := w.target.WarmTranslated(ctx, name)
+		if tErr != nil {
+			w.refundTranslation()
+			w.fail(name, tErr)
+			return
+		}
+
+		if called {
+			w.record(func(r *WarmReport) { r.Translated++ })
+		} else {
+			w.refundTranslation()
+		}
+	} else {
+		w.record(func(r *WarmReport) { r.TranslationSkipped++ })
+	}
+
+	if progressErr := w.progress.Add(name); progressErr != nil {
+		logging.Warnf("failed to record warm-up progress for %s: [%v]", name, progressErr)
+	}
+
+	w.advance(func(r *WarmReport) {
+		if cached {
+			r.AlreadyCached++
+		} else {
+			r.Fetched++
+		}
+	})
+}
+
+func (w *warmer) fail(name string, err error) {
+	w.advance(func(r *WarmReport) {
+		r.Failed++
+		r.Failures[name] = err.Error()
+	})
+}
+
+// record applies fn to the report.
+func (w *warmer) record(fn func(r *WarmReport)) {
+	w.Lock()
+	defer w.Unlock()
+
+	fn(w.report)
+}
+
+// advance applies fn, which must count one more processed species, and logs progress when due.
+func (w *warmer) advance(fn func(r *WarmReport)) {
+	w.Lock()
+	defer w.Unlock()
+
+	fn(w.report)
+
+	processed := w.report.Fetched + w.report.AlreadyCached + w.report.Resumed + w.report.Failed
+	if w.every > 0 && processed > 0 && processed%w.every == 0 {
+		logging.Infof("warm-up progress: %d/%d species (%d failed, %d translated)",
+			processed, w.report.Total, w.report.Failed, w.report.Translated)
+	}
+}
+
+func (w *warmer) reserveTranslation() bool {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.budget <= 0 {
+		return false
+	}
+	w.budget--
+
+	return true
+}
+
+func (w *warmer) refundTranslation() {
+	w.Lock()
+	defer w.Unlock()
+
+	w.budget++
+}
+
+// progressFile appends warmed species names to a file, one per line.
+type progressFile struct {
+	sync.Mutex
+	f *os.File
+}
+
+func openProgress(path string) (*progressFile, error) {
+	if path == "" {
+		return &progressFile{}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &progressFile{f: f}, nil
+}
+
+func (p *progressFile) Add(name string) error {
+	if p.f == nil {
+		return nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	_, err := p.f.WriteString(name + "\n")
+
+	return err
+}
+
+func (p *progressFile) Close() {
+	if p.f != nil {
+		p.f.Close()
+	}
+}
+
+// readProgress returns the species recorded by a previous run.
+func readProgress(path string) (map[string]bool, error) {
+	done := make(map[string]bool)
+	if path == "" {
+		return done, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			done[name] = true
+		}
+	}
+
+	return done, scanner.Err()
+}
@@ -0,0 +1,79 @@
+package pokemon_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/api/mocks"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockPokeAPI := mocks.NewMockPokeAPI(ctrl)
+	mockPokeAPI.EXPECT().GetGeneration(gomock.Any(), gomock.Any()).Return(nil, api.ErrNotFound).AnyTimes()
+	mockTranslationsAPI := mocks.NewMockTranslationsAPI(ctrl)
+	storageAPI := storage.NewStore()
+	service := pokemon.NewService(storageAPI, mockPokeAPI, mockTranslationsAPI)
+
+	mockPokeAPI.EXPECT().ListSpecies(gomock.Any(), 0, gomock.Any()).Return(&api.NamedAPIResourceList{
+		Count: 4,
+		Results: []api.NamedAPIResource{
+			speciesResource(1, "bulbasaur"),
+			speciesResource(4, "charmander"),
+			speciesResource(7, "squirtle"),
+			speciesResource(25, "pikachu"),
+		},
+	}, nil)
+
+	species := func(_ context.Context, name string) (*api.PokemonSpecies, error) {
+		if name == "squirtle" {
+			return nil, fmt.Errorf("upstream unavailable")
+		}
+
+		return &api.PokemonSpecies{
+			Name: name,
+			FlavorTextEntries: []api.FlavorText{
+				{FlavorText: "some text", Language: api.NamedAPIResource{Name: "en"}},
+			},
+		}, nil
+	}
+	mockPokeAPI.EXPECT().GetSpecies(gomock.Any(), gomock.Any()).DoAndReturn(species).AnyTimes()
+	mockTranslationsAPI.EXPECT().GetTranslation(gomock.Any(), gomock.Any(), "some text", api.TTypeShakespeare).
+		Return(&api.TranslateAPIResponse{}, nil).Times(1)
+
+	progressFile := filepath.Join(t.TempDir(), "progress")
+	assert.Nil(t, os.WriteFile(progressFile, []byte("bulbasaur\n"), 0o600))
+
+	assert.Nil(t, storageAPI.Save("pikachu", &pokemon.Pokemon{Name: "pikachu"}))
+
+	report, err := service.WarmCache(context.Background(), pokemon.WarmOptions{
+		Concurrency:       1,
+		TranslationBudget: 1,
+		ProgressFile:      progressFile,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Resumed)
+	assert.Equal(t, 1, report.Fetched)
+	assert.Equal(t, 1, report.AlreadyCached)
+	assert.Equal(t, 1, report.Failed)
+	assert.Contains(t, report.Failures, "squirtle")
+	assert.Equal(t, 1, report.Translated)
+	assert.Equal(t, 1, report.TranslationSkipped)
+
+	assert.True(t, storageAPI.Exist("charmander"))
+
+	progress, err := os.ReadFile(progressFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "bulbasaur\ncharmander\npikachu\n", string(progress))
+}
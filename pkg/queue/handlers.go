@@ -0,0 +1,31 @@
+package queue
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register adds the task route to routes:
+//
+//	GET /tasks/:id  get a task, or be redirected to its result once it succeeded
+func (q *Queue) Register(routes gin.IRoutes) {
+	routes.GET(Path+"/:id", q.Get)
+}
+
+// Get returns a task, unless it succeeded, in which case it answers 303 See Other with the location of its result.
+// Tasks are only served to the client they were enqueued for, they are not found for the others.
+func (q *Queue) Get(c *gin.Context) {
+	task, ok := q.Lookup(c.Param("id"), q.Client(c))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	if task.State == StateSucceeded && task.Location != "" {
+		c.Redirect(http.StatusSeeOther, task.Location)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
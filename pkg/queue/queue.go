@@ -0,0 +1,411 @@
+// Queue package provides a task queue kept in a storage backend and worked by a pool of workers. Tasks are picked
+// by priority, paced by a per client rate limiter, and retried with exponential backoff when they fail.
+package queue
+
+import (
+	"context"
+	"errors"
+	"pokedex-clone/pkg/random"
+	"pokedex-clone/pkg/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Path is the route prefix of the tasks.
+	Path = "/tasks"
+
+	storePrefix = "task/"
+	// idleWait is how long idle workers sleep for when no task is due later, unless woken up by a new task.
+	idleWait = time.Minute
+)
+
+// Priorities of the tasks, the higher ones are picked first.
+const (
+	PriorityLow = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// State is where a task is at, queued tasks are waiting for a worker, possibly to be retried.
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+)
+
+// Finished tells whether a task in state s is done changing.
+func (s State) Finished() bool {
+	return s == StateSucceeded || s == StateFailed
+}
+
+// errPermanent marks the errors of the tasks that fail the same way when retried.
+var errPermanent = errors.New("permanent failure")
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Is(target error) bool {
+	return target == errPermanent
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps the error of a task that would fail again, the task fails without being retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Handler does the work of a task, given its payload. Failed tasks are retried unless the error is Permanent.
+type Handler func(ctx context.Context, payload string) error
+
+// Spec describes a task to enqueue.
+type Spec struct {
+	// Key identifies the work of the task, enqueueing the key of an unfinished task of the same client returns
+	// that task.
+	Key string
+	// Payload is passed to the handler.
+	Payload string
+	// Location is where the result of the task is served once it succeeded.
+	Location string
+	// Priority is one of the priority constants.
+	Priority int
+	// Client is the key of the client the task is done for, the only one it is served to, see Limiter.
+	Client string
+}
+
+// Task is an enqueued Spec.
+type Task struct {
+	ID       string `json:"id"`
+	State    State  `json:"state"`
+	Priority int    `json:"priority"`
+	Attempts int    `json:"attempts"`
+	// Error is the error of the last attempt, the task is retried at NextAttemptAt unless it failed.
+	Error         string    `json:"error,omitempty"`
+	Location      string    `json:"location"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	key     string
+	payload string
+	client  string
+}
+
+// Limiter paces the attempts of the tasks of every client.
+type Limiter interface {
+	// Key identifies the client of c, clients with an empty key aren't limited.
+	Key(c *gin.Context) string
+	// Wait blocks until the client identified by key may attempt one more task, or ctx is done.
+	Wait(ctx context.Context, key string) error
+}
+
+// Options configure the queue returned by New.
+type Options struct {
+	// Workers is the number of tasks attempted at once.
+	Workers int
+	// MaxAttempts is the number of times a task is attempted before failing.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubling with every retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// TTL is how long finished tasks are kept for, they are kept until removed from the store when zero.
+	TTL time.Duration
+	// Limiter paces the attempts, they aren't limited when nil.
+	Limiter Limiter
+	// Failed is called with the tasks failing for good and their payload before they are saved as failed, e.g. to
+	// keep them aside, when not nil.
+	Failed func(task Task, payload string)
+}
+
+// Queue holds the tasks, which its workers attempt once Run.
+type Queue struct {
+	store   *storage.Store
+	handler Handler
+	opts    Options
+
+	mu sync.Mutex
+	// enqueued is closed and replaced whenever a task is enqueued, waking up the idle workers.
+	enqueued chan struct{}
+	// pending holds the unfinished tasks by ID, and keys their IDs by client and key, so that neither Enqueue nor
+	// the workers list the store, which keeps the finished tasks too.
+	pending map[string]Task
+	keys    map[clientKey]string
+}
+
+type clientKey struct {
+	client, key string
+}
+
+// New returns a queue doing its tasks with handler and keeping them in store, apart from the cache whose
+// evictions would drop queued tasks.
+func New(store *storage.Store, handler Handler, opts Options) *Queue {
+	q := &Queue{
+		store:    store,
+		handler:  handler,
+		opts:     opts,
+		enqueued: make(chan struct{}),
+		pending:  make(map[string]Task),
+		keys:     make(map[clientKey]string),
+	}
+
+	for _, meta := range store.List(storePrefix) {
+		if task, ok := q.load(strings.TrimPrefix(meta.Key, storePrefix)); ok && !task.State.Finished() {
+			q.index(task)
+		}
+	}
+
+	return q
+}
+
+// Enqueue adds a task for spec, unless a task of the same client with the same key is unfinished, in which case
+// that task is returned with the higher of both priorities.
+func (q *Queue) Enqueue(spec Spec) (Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if id, ok := q.keys[clientKey{client: spec.Client, key: spec.Key}]; ok {
+		task := q.pending[id]
+		if spec.Priority > task.Priority {
+			task.Priority = spec.Priority
+			if err := q.save(task); err != nil {
+				return Task{}, err
+			}
+		}
+		return task, nil
+	}
+
+	id, err := random.Hex(16)
+	if err != nil {
+		return Task{}, err
+	}
+
+	now := time.Now()
+	task := Task{
+		ID:            id,
+		State:         StateQueued,
+		Priority:      spec.Priority,
+		Location:      spec.Location,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+		key:           spec.Key,
+		payload:       spec.Payload,
+		client:        spec.Client,
+	}
+	if err = q.save(task); err != nil {
+		return Task{}, err
+	}
+
+	close(q.enqueued)
+	q.enqueued = make(chan struct{})
+
+	return task, nil
+}
+
+// Lookup returns the task id, unless it was enqueued for another client than client.
+func (q *Queue) Lookup(id, client string) (Task, bool) {
+	task, ok := q.load(id)
+	if !ok || task.client != client {
+		return Task{}, false
+	}
+
+	return task, true
+}
+
+// Client returns the key of the client of c, see Limiter.
+func (q *Queue) Client(c *gin.Context) string {
+	if q.opts.Limiter == nil {
+		return ""
+	}
+
+	return q.opts.Limiter.Key(c)
+}
+
+// Run attempts the tasks with the workers until ctx is done. The tasks attempted when the previous run stopped are
+// queued again first.
+func (q *Queue) Run(ctx context.Context) {
+	q.mu.Lock()
+	for _, task := range q.pending {
+		if task.State == StateRunning {
+			task.State = StateQueued
+			// a task still saved as running is requeued by the next run
+			_ = q.save(task)
+		}
+	}
+	q.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		task, enqueued, wait, ok := q.claim(time.Now())
+		if ok {
+			q.attempt(ctx, task)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+		case <-enqueued:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// claim marks the due task of the highest priority as running and returns it. When no task is due it returns how
+// long to wait for the next one, and a channel closed when a task is enqueued meanwhile.
+func (q *Queue) claim(now time.Time) (Task, <-chan struct{}, time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next *Task
+	wait := idleWait
+	for _, task := range q.pending {
+		task := task
+		if task.State != StateQueued {
+			continue
+		}
+		if due := task.NextAttemptAt.Sub(now); due > 0 {
+			if due < wait {
+				wait = due
+			}
+			continue
+		}
+		if next == nil || task.Priority > next.Priority ||
+			task.Priority == next.Priority && task.NextAttemptAt.Before(next.NextAttemptAt) {
+			next = &task
+		}
+	}
+
+	if next == nil {
+		return Task{}, q.enqueued, wait, false
+	}
+
+	next.State, next.UpdatedAt = StateRunning, now
+	if err := q.save(*next); err != nil {
+		return Task{}, q.enqueued, wait, false
+	}
+
+	return *next, nil, 0, true
+}
+
+// attempt does task, saving how it went. Tasks attempted when ctx is done are left running, for the next run to
+// queue them again.
+func (q *Queue) attempt(ctx context.Context, task Task) {
+	err := q.wait(ctx, task.client)
+	if err == nil {
+		err = q.handler(ctx, task.payload)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	task.Attempts++
+	task.UpdatedAt = now
+	switch {
+	case err == nil:
+		task.State, task.Error = StateSucceeded, ""
+	case errors.Is(err, errPermanent) || task.Attempts >= q.opts.MaxAttempts:
+		task.State, task.Error = StateFailed, err.Error()
+	default:
+		task.State, task.Error = StateQueued, err.Error()
+		task.NextAttemptAt = now.Add(q.backoff(task.Attempts))
+	}
+
+	if task.State == StateFailed && q.opts.Failed != nil {
+		q.opts.Failed(task, task.payload)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// the priority may have been raised meanwhile by Enqueue
+	if current, ok := q.pending[task.ID]; ok {
+		task.Priority = current.Priority
+	}
+	// a task left running in store is requeued and attempted again by the next run
+	_ = q.save(task)
+}
+
+// backoff returns the delay before attempting a task again after its attempts failed.
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.opts.Backoff
+	for i := 1; i < attempts && delay < q.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if q.opts.MaxBackoff > 0 && delay > q.opts.MaxBackoff {
+		delay = q.opts.MaxBackoff
+	}
+
+	return delay
+}
+
+func (q *Queue) wait(ctx context.Context, client string) error {
+	if q.opts.Limiter == nil {
+		return ctx.Err()
+	}
+
+	return q.opts.Limiter.Wait(ctx, client)
+}
+
+func (q *Queue) load(id string) (Task, bool) {
+	value, ok := q.store.Load(storePrefix + id)
+	if !ok {
+		return Task{}, false
+	}
+	task, ok := value.(Task)
+
+	return task, ok
+}
+
+// save stores task and indexes it, q.mu must be held.
+func (q *Queue) save(task Task) error {
+	var ttl time.Duration
+	if task.State.Finished() {
+		ttl = q.opts.TTL
+	}
+
+	if err := q.store.SaveWithTTL(storePrefix+task.ID, task, ttl); err != nil {
+		return err
+	}
+	q.index(task)
+
+	return nil
+}
+
+// index adds task to the pending tasks, or removes it once finished, q.mu must be held unless in New.
+func (q *Queue) index(task Task) {
+	if task.State.Finished() {
+		delete(q.pending, task.ID)
+		delete(q.keys, clientKey{client: task.client, key: task.key})
+		return
+	}
+
+	q.pending[task.ID] = task
+	q.keys[clientKey{client: task.client, key: task.key}] = task.ID
+}
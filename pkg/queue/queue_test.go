@@ -0,0 +1,275 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/storage"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a handler recording the payloads it was called with, failing them as told by fail.
+type recorder struct {
+	mu       sync.Mutex
+	payloads []string
+	fail     func(payload string, attempt int) error
+}
+
+func (r *recorder) handle(_ context.Context, payload string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads = append(r.payloads, payload)
+
+	attempt := 0
+	for _, p := range r.payloads {
+		if p == payload {
+			attempt++
+		}
+	}
+	if r.fail != nil {
+		return r.fail(payload, attempt)
+	}
+
+	return nil
+}
+
+func (r *recorder) calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.payloads...)
+}
+
+const clientHeader = "X-Client"
+
+// limiter keys the clients by a header and records the clients it waited for.
+type limiter struct {
+	mu      sync.Mutex
+	clients []string
+}
+
+func (l *limiter) Key(c *gin.Context) string {
+	return c.GetHeader(clientHeader)
+}
+
+func (l *limiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clients = append(l.clients, key)
+
+	return ctx.Err()
+}
+
+func options() queue.Options {
+	return queue.Options{
+		Workers:     1,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  2 * time.Millisecond,
+	}
+}
+
+func run(t *testing.T, q *queue.Queue) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func finished(t *testing.T, q *queue.Queue, id, client string) queue.Task {
+	t.Helper()
+
+	var task queue.Task
+	assert.Eventually(t, func() bool {
+		task, _ = q.Lookup(id, client)
+		return task.State.Finished()
+	}, time.Second, time.Millisecond)
+
+	return task
+}
+
+func TestAttempts(t *testing.T) {
+	tests := map[string]struct {
+		fail         func(payload string, attempt int) error
+		wantState    queue.State
+		wantAttempts int
+		wantError    string
+	}{
+		"succeeds": {
+			wantState:    queue.StateSucceeded,
+			wantAttempts: 1,
+		},
+		"succeeds once retried": {
+			fail: func(_ string, attempt int) error {
+				if attempt < 3 {
+					return errors.New("429 too many requests")
+				}
+				return nil
+			},
+			wantState:    queue.StateSucceeded,
+			wantAttempts: 3,
+		},
+		"fails after the last attempt": {
+			fail: func(string, int) error {
+				return errors.New("429 too many requests")
+			},
+			wantState:    queue.StateFailed,
+			wantAttempts: 3,
+			wantError:    "429 too many requests",
+		},
+		"fails permanently": {
+			fail: func(string, int) error {
+				return queue.Permanent(errors.New("invalid payload"))
+			},
+			wantState:    queue.StateFailed,
+			wantAttempts: 1,
+			wantError:    "invalid payload",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{fail: tc.fail}
+			l := &limiter{}
+			var failed []string
+			opts := options()
+			opts.Limiter = l
+			opts.Failed = func(task queue.Task, payload string) {
+				failed = append(failed, task.ID+" "+payload)
+			}
+			q := queue.New(storage.NewStore(), r.handle, opts)
+			run(t, q)
+
+			task, err := q.Enqueue(queue.Spec{Key: "mewtwo", Payload: "mewtwo", Client: "ash"})
+			assert.Nil(t, err)
+
+			task = finished(t, q, task.ID, "ash")
+			assert.Equal(t, tc.wantState, task.State)
+			assert.Equal(t, tc.wantAttempts, task.Attempts)
+			assert.Equal(t, tc.wantError, task.Error)
+			assert.Len(t, r.calls(), tc.wantAttempts)
+			if tc.wantState == queue.StateFailed {
+				assert.Equal(t, []string{task.ID + " mewtwo"}, failed)
+			} else {
+				assert.Empty(t, failed)
+			}
+			l.mu.Lock()
+			assert.Equal(t, "ash", l.clients[0])
+			l.mu.Unlock()
+		})
+	}
+}
+
+func TestPriorities(t *testing.T) {
+	r := &recorder{}
+	q := queue.New(storage.NewStore(), r.handle, options())
+
+	var last queue.Task
+	for _, spec := range []queue.Spec{
+		{Key: "low", Payload: "low", Priority: queue.PriorityLow},
+		{Key: "normal", Payload: "normal", Priority: queue.PriorityNormal},
+		{Key: "raised", Payload: "raised", Priority: queue.PriorityLow},
+		{Key: "high", Payload: "high", Priority: queue.PriorityHigh},
+		{Key: "raised", Payload: "raised again", Priority: queue.PriorityHigh},
+	} {
+		task, err := q.Enqueue(spec)
+		assert.Nil(t, err)
+		last = task
+	}
+	assert.Equal(t, queue.PriorityHigh, last.Priority)
+
+	run(t, q)
+	assert.Eventually(t, func() bool { return len(r.calls()) == 4 }, time.Second, time.Millisecond)
+	// raised was enqueued before high, the other key enqueued twice is only queued once
+	assert.Equal(t, []string{"raised", "high", "normal", "low"}, r.calls())
+}
+
+func TestResume(t *testing.T) {
+	store := storage.NewStore()
+
+	// the first run stops while attempting the task
+	attempting := make(chan struct{})
+	stopped := queue.New(store, func(ctx context.Context, _ string) error {
+		close(attempting)
+		<-ctx.Done()
+		return ctx.Err()
+	}, options())
+	task, err := stopped.Enqueue(queue.Spec{Key: "mewtwo", Payload: "mewtwo"})
+	assert.Nil(t, err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stopped.Run(ctx)
+		close(done)
+	}()
+	<-attempting
+	stop()
+	<-done
+
+	task, _ = stopped.Lookup(task.ID, "")
+	assert.Equal(t, queue.StateRunning, task.State)
+
+	r := &recorder{}
+	resumed := queue.New(store, r.handle, options())
+	run(t, resumed)
+
+	task = finished(t, resumed, task.ID, "")
+	assert.Equal(t, queue.StateSucceeded, task.State)
+	assert.Equal(t, []string{"mewtwo"}, r.calls())
+}
+
+func TestGet(t *testing.T) {
+	opts := options()
+	opts.Limiter = &limiter{}
+	q := queue.New(storage.NewStore(), (&recorder{}).handle, opts)
+	router := gin.New()
+	q.Register(router)
+
+	spec := queue.Spec{Key: "mewtwo", Payload: "mewtwo", Location: "/v2/pokemon/translated/mewtwo", Client: "ash"}
+	task, err := q.Enqueue(spec)
+	assert.Nil(t, err)
+	// the same work enqueued by another client is a task of its own
+	spec.Client = "misty"
+	other, err := q.Enqueue(spec)
+	assert.Nil(t, err)
+	assert.NotEqual(t, task.ID, other.ID)
+
+	serve := func(id, client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, queue.Path+"/"+id, nil)
+		req.Header.Set(clientHeader, client)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(task.ID, "ash")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"state":"queued"`)
+	// tasks aren't served to the other clients
+	assert.Equal(t, http.StatusNotFound, serve(task.ID, "misty").Code)
+	assert.Equal(t, http.StatusNotFound, serve(task.ID, "").Code)
+
+	run(t, q)
+	finished(t, q, task.ID, "ash")
+	rr = serve(task.ID, "ash")
+	assert.Equal(t, http.StatusSeeOther, rr.Code)
+	assert.Equal(t, "/v2/pokemon/translated/mewtwo", rr.Header().Get("Location"))
+
+	assert.Equal(t, http.StatusNotFound, serve("unknown", "ash").Code)
+}
@@ -0,0 +1,18 @@
+// Package random generates the identifiers and secrets handed out by the API, e.g. the API keys, the job and task
+// ids and the webhook secrets.
+package random
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Hex returns n cryptographically random bytes, hex encoded.
+func Hex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
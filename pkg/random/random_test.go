@@ -0,0 +1,22 @@
+package random_test
+
+import (
+	"encoding/hex"
+	"pokedex-clone/pkg/random"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHex(t *testing.T) {
+	first, err := random.Hex(16)
+	require.NoError(t, err)
+	second, err := random.Hex(16)
+	require.NoError(t, err)
+
+	decoded, err := hex.DecodeString(first)
+	require.NoError(t, err)
+	assert.Len(t, decoded, 16)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,227 @@
+// Ratelimit package provides gin middlewares limiting how many requests the server accepts per
+// second, overall and per client, and the checks applying the same limits to the gRPC calls. The
+// limits can be changed while the server is running.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// Limiter rejects requests beyond its rate with 429 Too Many Requests.
+type Limiter struct {
+	limiter *rate.Limiter
+}
+
+// New returns a Limiter accepting rps requests per second with bursts of up to burst requests,
+// an rps of zero accepts every request.
+func New(rps float64, burst int) *Limiter {
+	return &Limiter{
+		limiter: rate.NewLimiter(limit(rps), burst),
+	}
+}
+
+// SetLimit changes the rate and burst of l, see New.
+func (l *Limiter) SetLimit(rps float64, burst int) {
+	l.limiter.SetLimit(limit(rps))
+	l.limiter.SetBurst(burst)
+}
+
+func limit(rps float64) rate.Limit {
+	if rps > 0 {
+		return rate.Limit(rps)
+	}
+
+	return rate.Inf
+}
+
+// Allow takes a token, reporting whether there was one. It limits the requests served outside of gin, e.g.
+// the gRPC calls.
+func (l *Limiter) Allow() bool {
+	return l.limiter.Allow()
+}
+
+// Handle is the gin middleware.
+func (l *Limiter) Handle(c *gin.Context) {
+	reservation := l.limiter.Reserve()
+	if !reservation.OK() || reservation.Delay() > 0 {
+		retryAfter := reservation.Delay()
+		reservation.Cancel()
+
+		reject(c, retryAfter)
+		return
+	}
+
+	c.Next()
+}
+
+// Keyed gives every client its own token bucket, e.g. one per API key. The buckets of the clients gone quiet are
+// evicted by RunEviction, so that clients rotating their addresses don't grow them without bounds.
+type Keyed struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*bucket
+	key      func(c *gin.Context) string
+}
+
+// bucket is the token bucket of a client, along with the last time it was used.
+type bucket struct {
+	*rate.Limiter
+	used time.Time
+}
+
+// NewKeyed returns a Keyed accepting rps requests per second with bursts of up to burst requests
+// from every client, see New. key identifies the client of a request, requests it returns an
+// empty key for aren't limited.
+func NewKeyed(rps float64, burst int, key func(c *gin.Context) string) *Keyed {
+	return &Keyed{
+		limit:    limit(rps),
+		burst:    burst,
+		limiters: make(map[string]*bucket),
+		key:      key,
+	}
+}
+
+// SetLimit changes the rate and burst of every client, see New.
+func (k *Keyed) SetLimit(rps float64, burst int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.limit, k.burst = limit(rps), burst
+	for _, l := range k.limiters {
+		l.SetLimit(k.limit)
+		l.SetBurst(k.burst)
+	}
+}
+
+func (k *Keyed) limiter(key string) *rate.Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	b, ok := k.limiters[key]
+	if !ok {
+		b = &bucket{Limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.limiters[key] = b
+	}
+	b.used = time.Now()
+
+	return b.Limiter
+}
+
+// Evict removes the buckets unused for idle, and the full ones, which a new bucket replaces as is. It returns how
+// many buckets are left.
+func (k *Keyed) Evict(idle time.Duration) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	for key, b := range k.limiters {
+		if now.Sub(b.used) >= idle || b.TokensAt(now) >= float64(b.Burst()) {
+			delete(k.limiters, key)
+		}
+	}
+
+	return len(k.limiters)
+}
+
+// RunEviction evicts the buckets unused for interval, and the full ones, every interval until ctx is done.
+func (k *Keyed) RunEviction(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.Evict(interval)
+		}
+	}
+}
+
+// Handle is the gin middleware. Limited responses carry the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, in seconds, of the client bucket.
+func (k *Keyed) Handle(c *gin.Context) {
+	key := k.key(c)
+	if key == "" {
+		c.Next()
+		return
+	}
+
+	l := k.limiter(key)
+	if l.Limit() == rate.Inf {
+		c.Next()
+		return
+	}
+
+	now := time.Now()
+	reservation := l.ReserveN(now, 1)
+	if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+		retryAfter := reservation.DelayFrom(now)
+		reservation.CancelAt(now)
+
+		setHeaders(c, l.Burst(), 0, retryAfter)
+		reject(c, retryAfter)
+		return
+	}
+
+	tokens := l.TokensAt(now)
+	refill := time.Duration((float64(l.Burst()) - tokens) / float64(l.Limit()) * float64(time.Second))
+	setHeaders(c, l.Burst(), int(math.Floor(tokens)), refill)
+
+	c.Next()
+}
+
+// Allow takes a token from the bucket of the client of c, reporting whether there was one. It limits what a
+// handler does on behalf of a request rather than the request itself, e.g. the translations of a GraphQL query.
+func (k *Keyed) Allow(c *gin.Context) bool {
+	return k.AllowKey(k.key(c))
+}
+
+// AllowKey is Allow for the client identified by key, for the requests served outside of gin, e.g. the gRPC
+// calls, which share the buckets of the HTTP requests of the same client.
+func (k *Keyed) AllowKey(key string) bool {
+	if key == "" {
+		return true
+	}
+
+	return k.limiter(key).Allow()
+}
+
+// Key returns the key of the client of c, which is empty for the clients that aren't limited.
+func (k *Keyed) Key(c *gin.Context) string {
+	return k.key(c)
+}
+
+// Wait blocks until the client identified by key may take one more token, or ctx is done. It paces the work
+// done on behalf of a client after its request was served, e.g. the translations of a job.
+func (k *Keyed) Wait(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+
+	return k.limiter(key).Wait(ctx)
+}
+
+func setHeaders(c *gin.Context, limit, remaining int, reset time.Duration) {
+	c.Header("RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("RateLimit-Reset", seconds(reset))
+}
+
+func reject(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", seconds(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,120 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := ratelimit.New(1, 2)
+
+	router := gin.Default()
+	router.Use(limiter.Handle)
+	router.GET("/pokemon", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/pokemon", nil)
+		assert.Nil(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, get().Code)
+	assert.Equal(t, http.StatusOK, get().Code)
+
+	rr := get()
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	limiter.SetLimit(0, 0)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, get().Code)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	limiter := ratelimit.NewKeyed(1, 2, func(c *gin.Context) string { return c.GetHeader("X-API-Key") })
+
+	router := gin.Default()
+	router.Use(limiter.Handle)
+	router.GET("/pokemon", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(key string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/pokemon", nil)
+		assert.Nil(t, err)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	tests := []struct {
+		key           string
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{key: "ash", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		{key: "ash", wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "2"},
+		{key: "ash", wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "1"},
+		// every key has its own bucket
+		{key: "misty", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		// requests without a key aren't limited
+		{wantStatus: http.StatusOK},
+		{wantStatus: http.StatusOK},
+		{wantStatus: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		rr := get(tt.key)
+		assert.Equal(t, tt.wantStatus, rr.Code, i)
+		assert.Equal(t, tt.wantRemaining, rr.Header().Get("RateLimit-Remaining"), i)
+		assert.Equal(t, tt.wantReset, rr.Header().Get("RateLimit-Reset"), i)
+		if tt.key != "" {
+			assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"), i)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests {
+			assert.Equal(t, "1", rr.Header().Get("Retry-After"), i)
+		}
+	}
+
+	limiter.SetLimit(0, 0)
+	for i := 0; i < 10; i++ {
+		rr := get("ash")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("RateLimit-Limit"), "unlimited keys get no headers")
+	}
+}
+
+func TestKeyedEvict(t *testing.T) {
+	limiter := ratelimit.NewKeyed(100, 2, func(c *gin.Context) string { return "" })
+	for _, key := range []string{"ip:10.0.0.1", "ip:10.0.0.2", "ip:10.0.0.3"} {
+		assert.True(t, limiter.AllowKey(key))
+	}
+
+	// the buckets were just used and aren't full yet
+	assert.Equal(t, 3, limiter.Evict(time.Hour))
+	// idle buckets are evicted
+	assert.Equal(t, 0, limiter.Evict(0))
+
+	assert.True(t, limiter.AllowKey("ip:10.0.0.1"))
+	assert.True(t, limiter.AllowKey("ip:10.0.0.2"))
+	assert.Equal(t, 2, limiter.Evict(time.Hour))
+	// full buckets are evicted too, a new one replaces them as they are
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, limiter.Evict(time.Hour))
+	assert.True(t, limiter.AllowKey("ip:10.0.0.1"))
+}
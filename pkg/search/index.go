@@ -0,0 +1,357 @@
+// Search package provides full-text search over pokemon descriptions. Flavor texts are kept in an
+// inverted index, English texts are stemmed, and matches are ranked with BM25.
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// bm25K1 controls term frequency saturation and bm25B document length normalization.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// English is the language whose texts and queries get stemmed.
+	English = "en"
+
+	maxSnippetRunes = 160
+	highlightStart  = "<em>"
+	highlightEnd    = "</em>"
+)
+
+// Entry is a single description to index, one per language and game version.
+type Entry struct {
+	Text     string
+	Language string
+	Version  string
+}
+
+// Result is a species matching a query, along with its best matching description.
+type Result struct {
+	Species  string
+	Score    float64
+	Language string
+	Version  string
+	Snippet  string
+}
+
+type document struct {
+	species  string
+	language string
+	version  string
+	text     string
+	terms    map[string]int
+	length   int
+}
+
+// Index is a thread safe inverted index of species descriptions.
+type Index struct {
+	sync.RWMutex
+	nextID      int
+	docs        map[int]*document
+	bySpecies   map[string][]int
+	postings    map[string]map[int]int
+	totalLength int
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		docs:      make(map[int]*document),
+		bySpecies: make(map[string][]int),
+		postings:  make(map[string]map[int]int),
+	}
+}
+
+// Add indexes the descriptions of a species, replacing any previously indexed for it.
+// Identical texts repeated across game versions are only indexed once.
+func (i *Index) Add(species string, entries []Entry) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.remove(species)
+
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		text := normalizeText(e.Text)
+		key := e.Language + "\x00" + text
+		if text == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		doc := &document{
+			species:  species,
+			language: e.Language,
+			version:  e.Version,
+			text:     text,
+			terms:    make(map[string]int),
+		}
+		for _, tok := range tokenize(text) {
+			doc.terms[termFor(tok.term, e.Language)]++
+			doc.length++
+		}
+
+		id := i.nextID
+		i.nextID++
+		i.docs[id] = doc
+		i.bySpecies[species] = append(i.bySpecies[species], id)
+		i.totalLength += doc.length
+		for term, freq := range doc.terms {
+			if i.postings[term] == nil {
+				i.postings[term] = make(map[int]int)
+			}
+			i.postings[term][id] = freq
+		}
+	}
+}
+
+// Remove drops every description indexed for species.
+func (i *Index) Remove(species string) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.remove(species)
+}
+
+// Len returns the number of indexed species.
+func (i *Index) Len() int {
+	i.RLock()
+	defer i.RUnlock()
+
+	return len(i.bySpecies)
+}
+
+func (i *Index) remove(species string) {
+	for _, id := range i.bySpecies[species] {
+		doc := i.docs[id]
+		for term := range doc.terms {
+			delete(i.postings[term], id)
+			if len(i.postings[term]) == 0 {
+				delete(i.postings, term)
+			}
+		}
+		i.totalLength -= doc.length
+		delete(i.docs, id)
+	}
+	delete(i.bySpecies, species)
+}
+
+// Search returns up to limit species whose descriptions match query, best match first.
+// When language is set only descriptions in that language are considered.
+func (i *Index) Search(query, language string, limit int) []Result {
+	i.RLock()
+	defer i.RUnlock()
+
+	if len(i.docs) == 0 {
+		return nil
+	}
+
+	var queryTerms []string
+	for _, tok := range tokenize(normalizeText(query)) {
+		queryTerms = append(queryTerms, tok.term)
+	}
+
+	avgLength := float64(i.totalLength) / float64(len(i.docs))
+	scores := make(map[int]float64)
+	for _, raw := range queryTerms {
+		// English documents are indexed by stem, everything else by the raw term
+		for _, term := range uniqueTerms(raw, Stem(raw)) {
+			postings := i.postings[term]
+			idf := math.Log(1 + (float64(len(i.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+			for id, freq := range postings {
+				doc := i.docs[id]
+				if termFor(raw, doc.language) != term || (language != "" && doc.language != language) {
+					continue
+				}
+
+				tf := float64(freq)
+				saturation := tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLength)
+				scores[id] += idf * tf * (bm25K1 + 1) / saturation
+			}
+		}
+	}
+
+	best := make(map[string]int)
+	for id, score := range scores {
+		species := i.docs[id].species
+		if current, ok := best[species]; !ok || score > scores[current] || score == scores[current] && id < current {
+			best[species] = id
+		}
+	}
+
+	results := make([]Result, 0, len(best))
+	for species, id := range best {
+		doc := i.docs[id]
+		results = append(results, Result{
+			Species:  species,
+			Score:    scores[id],
+			Language: doc.language,
+			Version:  doc.version,
+			Snippet:  highlight(doc.text, doc.language, queryTerms),
+		})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Species < results[b].Species
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lowercase, unaccented terms with their byte offsets. Han and kana
+// characters are emitted one per token since those scripts don't separate words with spaces.
+func tokenize(text string) []token {
+	var (
+		tokens  []token
+		current strings.Builder
+		start   = -1
+	)
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{term: current.String(), start: start, end: end})
+			current.Reset()
+			start = -1
+		}
+	}
+
+	for pos, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush(pos)
+			tokens = append(tokens, token{term: string(r), start: pos, end: pos + len(string(r))})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if start < 0 {
+				start = pos
+			}
+			// fold diacritics so "pokémon" and "pokemon" are the same term
+			for _, folded := range norm.NFD.String(string(unicode.ToLower(r))) {
+				if !unicode.Is(unicode.Mn, folded) {
+					current.WriteRune(folded)
+				}
+			}
+		default:
+			flush(pos)
+		}
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// termFor returns the form a token is indexed under for documents in the given language.
+func termFor(tok, language string) string {
+	if language == English {
+		return Stem(tok)
+	}
+
+	return tok
+}
+
+// normalizeText collapses the form feeds, line breaks and soft hyphens pokeapi flavor texts
+// carry over from the games into single spaces.
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\u00ad", "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// highlight wraps the query terms found in text, trimming long texts to a window around
+// the first match. The text is HTML escaped, so the highlight tags are the only markup.
+func highlight(text, language string, queryTerms []string) string {
+	wanted := make(map[string]bool, len(queryTerms))
+	for _, t := range queryTerms {
+		wanted[termFor(t, language)] = true
+	}
+
+	var matches []token
+	for _, tok := range tokenize(text) {
+		if wanted[termFor(tok.term, language)] {
+			matches = append(matches, tok)
+		}
+	}
+
+	from, to := snippetWindow(text, matches)
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+
+	pos := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:m.start]))
+		b.WriteString(highlightStart)
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString(highlightEnd)
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+
+	if to < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// snippetWindow returns the byte range of at most maxSnippetRunes runes of text,
+// starting a little before the first match.
+func snippetWindow(text string, matches []token) (int, int) {
+	runes := []rune(text)
+	if len(runes) <= maxSnippetRunes {
+		return 0, len(text)
+	}
+
+	startRune := 0
+	if len(matches) > 0 {
+		startRune = len([]rune(text[:matches[0].start])) - maxSnippetRunes/4
+		if startRune < 0 {
+			startRune = 0
+		}
+		if startRune > len(runes)-maxSnippetRunes {
+			startRune = len(runes) - maxSnippetRunes
+		}
+	}
+
+	from := len(string(runes[:startRune]))
+	to := from + len(string(runes[startRune:startRune+maxSnippetRunes]))
+
+	return from, to
+}
+
+func uniqueTerms(terms ...string) []string {
+	unique := terms[:0]
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	return unique
+}
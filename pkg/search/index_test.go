@@ -0,0 +1,108 @@
+package search_test
+
+import (
+	"pokedex-clone/pkg/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() *search.Index {
+	index := search.NewIndex()
+	index.Add("charmander", []search.Entry{
+		{Text: "Obviously prefers\nhot places. When\fit rains, steam\nis said to spout\nfrom the tip of\nits tail.", Language: "en", Version: "red"},
+		{Text: "The flame on its tail shows the strength of its life force. If it is weak, the flame also burns weakly.", Language: "en", Version: "x"},
+		{Text: "Préfère les endroits chauds. Quand il pleut, de la vapeur se forme au bout de sa queue.", Language: "fr", Version: "x"},
+	})
+	index.Add("magmar", []search.Entry{
+		{Text: "Its body always burns with an orange glow that enables it to hide perfectly among flames.", Language: "en", Version: "red"},
+		{Text: "Its body always burns with an orange glow that enables it to hide perfectly among flames.", Language: "en", Version: "blue"},
+	})
+	index.Add("squirtle", []search.Entry{
+		{Text: "After birth, its back swells and hardens into a shell. Powerfully sprays foam from its mouth.", Language: "en", Version: "red"},
+	})
+
+	return index
+}
+
+func TestSearch(t *testing.T) {
+	tests := map[string]struct {
+		query       string
+		language    string
+		wantSpecies []string
+	}{
+		"stemmed terms match across word forms": {
+			query:       "burning flame",
+			wantSpecies: []string{"charmander", "magmar"},
+		},
+		"terms are case and line break insensitive": {
+			query:       "STEAM",
+			wantSpecies: []string{"charmander"},
+		},
+		"accents are folded": {
+			query:       "prefere",
+			language:    "fr",
+			wantSpecies: []string{"charmander"},
+		},
+		"language filter excludes other languages": {
+			query:       "queue",
+			language:    "en",
+			wantSpecies: []string{},
+		},
+		"unknown terms match nothing": {
+			query:       "psychic",
+			wantSpecies: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results := newTestIndex().Search(tc.query, tc.language, 10)
+
+			species := make([]string, 0, len(results))
+			for _, r := range results {
+				species = append(species, r.Species)
+			}
+			assert.Equal(t, tc.wantSpecies, species)
+		})
+	}
+}
+
+func TestSearchHighlightsSnippet(t *testing.T) {
+	results := newTestIndex().Search("spouting", "", 1)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "red", results[0].Version)
+	assert.Equal(t,
+		"Obviously prefers hot places. When it rains, steam is said to <em>spout</em> from the tip of its tail.",
+		results[0].Snippet)
+}
+
+func TestSearchEscapesSnippet(t *testing.T) {
+	index := search.NewIndex()
+	index.Add("porygon", []search.Entry{
+		{Text: `A <script>alert("virtual")</script> pokemon made of program code & data.`, Language: "en", Version: "red"},
+	})
+
+	results := index.Search("program", "", 1)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t,
+			"A &lt;script&gt;alert(&#34;virtual&#34;)&lt;/script&gt; pokemon made of <em>program</em> code &amp; data.",
+			results[0].Snippet)
+	}
+}
+
+func TestAddReplacesSpeciesDescriptions(t *testing.T) {
+	index := newTestIndex()
+	index.Add("squirtle", []search.Entry{
+		{Text: "It shelters itself in its shell then strikes back with spouts of water.", Language: "en", Version: "x"},
+	})
+
+	assert.Empty(t, index.Search("foam", "", 10))
+	assert.Len(t, index.Search("water", "", 10), 1)
+	assert.Equal(t, 3, index.Len())
+
+	index.Remove("squirtle")
+	assert.Empty(t, index.Search("water", "", 10))
+	assert.Equal(t, 2, index.Len())
+}
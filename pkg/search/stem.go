@@ -0,0 +1,311 @@
+package search
+
+// Stem reduces an English word to its stem using the Porter stemming algorithm, so "burning",
+// "burned" and "burns" all become "burn". Words containing anything but ASCII lowercase letters
+// are returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+
+	return string(z.b[:z.k+1])
+}
+
+// stemmer holds the word being stemmed in b[0..k], j is the end of the stem
+// once a suffix has been matched by ends.
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant.
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !z.cons(i - 1)
+	}
+
+	return true
+}
+
+// m measures the number of consonant sequences in b[0..j], i.e. the n in [C](VC){n}[V].
+func (z *stemmer) m() int {
+	n, i := 0, 0
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[0..j] contains a vowel.
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// doubleC reports whether b[j-1..j] is a double consonant.
+func (z *stemmer) doubleC(j int) bool {
+	if j < 1 || z.b[j] != z.b[j-1] {
+		return false
+	}
+
+	return z.cons(j)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant and the last consonant
+// is not w, x or y, e.g. "hop" but not "snow".
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+
+	return true
+}
+
+// ends reports whether b[0..k] ends with s, setting j to the end of the remaining stem.
+func (z *stemmer) ends(s string) bool {
+	l := len(s)
+	if l > z.k+1 || string(z.b[z.k-l+1:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - l
+
+	return true
+}
+
+// setTo replaces b[j+1..k] with s.
+func (z *stemmer) setTo(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+// r replaces the matched suffix with s when the stem has a consonant sequence.
+func (z *stemmer) r(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// step1ab removes plurals and -ed or -ing, e.g. "caresses" to "caress" and "hopping" to "hop".
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setTo("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+		return
+	}
+
+	if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		switch {
+		case z.ends("at"):
+			z.setTo("ate")
+		case z.ends("bl"):
+			z.setTo("ble")
+		case z.ends("iz"):
+			z.setTo("ize")
+		case z.doubleC(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		case z.m() == 1 && z.cvc(z.k):
+			z.setTo("e")
+		}
+	}
+}
+
+// step1c turns a terminal y into i when there is another vowel in the stem.
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// replaceFirst applies r to the first of the suffix pairs matching the word.
+func (z *stemmer) replaceFirst(pairs ...string) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if z.ends(pairs[i]) {
+			z.r(pairs[i+1])
+			return
+		}
+	}
+}
+
+// step2 maps double suffixes to single ones, e.g. "-ization" to "-ize".
+func (z *stemmer) step2() {
+	switch z.b[z.k-1] {
+	case 'a':
+		z.replaceFirst("ational", "ate", "tional", "tion")
+	case 'c':
+		z.replaceFirst("enci", "ence", "anci", "ance")
+	case 'e':
+		z.replaceFirst("izer", "ize")
+	case 'l':
+		z.replaceFirst("bli", "ble", "alli", "al", "entli", "ent", "eli", "e", "ousli", "ous")
+	case 'o':
+		z.replaceFirst("ization", "ize", "ation", "ate", "ator", "ate")
+	case 's':
+		z.replaceFirst("alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous")
+	case 't':
+		z.replaceFirst("aliti", "al", "iviti", "ive", "biliti", "ble")
+	case 'g':
+		z.replaceFirst("logi", "log")
+	}
+}
+
+// step3 deals with -ic-, -full, -ness etc.
+func (z *stemmer) step3() {
+	switch z.b[z.k] {
+	case 'e':
+		z.replaceFirst("icate", "ic", "ative", "", "alize", "al")
+	case 'i':
+		z.replaceFirst("iciti", "ic")
+	case 'l':
+		z.replaceFirst("ical", "ic", "ful", "")
+	case 's':
+		z.replaceFirst("ness", "")
+	}
+}
+
+// step4 removes -ant, -ence etc. when the stem has more than one consonant sequence.
+func (z *stemmer) step4() {
+	var suffixes []string
+	switch z.b[z.k-1] {
+	case 'a':
+		suffixes = []string{"al"}
+	case 'c':
+		suffixes = []string{"ance", "ence"}
+	case 'e':
+		suffixes = []string{"er"}
+	case 'i':
+		suffixes = []string{"ic"}
+	case 'l':
+		suffixes = []string{"able", "ible"}
+	case 'n':
+		suffixes = []string{"ant", "ement", "ment", "ent"}
+	case 'o':
+		if z.ends("ion") && z.j >= 0 && (z.b[z.j] == 's' || z.b[z.j] == 't') {
+			break
+		}
+		suffixes = []string{"ou"}
+	case 's':
+		suffixes = []string{"ism"}
+	case 't':
+		suffixes = []string{"ate", "iti"}
+	case 'u':
+		suffixes = []string{"ous"}
+	case 'v':
+		suffixes = []string{"ive"}
+	case 'z':
+		suffixes = []string{"ize"}
+	default:
+		return
+	}
+
+	if suffixes != nil {
+		matched := false
+		for _, s := range suffixes {
+			if z.ends(s) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return
+		}
+	}
+
+	if z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 removes a final -e and reduces -ll to -l when the stem is long enough.
+func (z *stemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		a := z.m()
+		if a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+
+	if z.b[z.k] == 'l' && z.doubleC(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
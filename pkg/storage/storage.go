@@ -1,59 +1,244 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Store is the thread safe in memory key value store.
 type Store struct {
 	sync.RWMutex
-	values map[string]interface{}
+	values map[string]entry
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// Stats counts the lookups and evictions of a Store since it was created.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// entry is a stored value, expiresAt is zero for values that never expire.
+type entry struct {
+	value     interface{}
+	createdAt time.Time
+	expiresAt time.Time
+	source    string
+}
+
+// Meta describes a stored value, ExpiresAt is zero for values that never expire.
+type Meta struct {
+	Key       string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// Source is where the value came from, as given to SaveWithSource.
+	Source string
+}
+
+// Entry is a stored value along with its metadata.
+type Entry struct {
+	Meta
+	Value interface{}
+}
+
+func (e entry) meta(key string) Meta {
+	return Meta{Key: key, CreatedAt: e.createdAt, ExpiresAt: e.expiresAt, Source: e.source}
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
 }
 
 // NewStore creates an empty Store.
 func NewStore() *Store {
 	return &Store{
-		values: make(map[string]interface{}),
+		values: make(map[string]entry),
 	}
 }
 
-// Load returns the value for the specified key.
+// Load returns the value for the specified key, expired values are reported as missing.
 func (s *Store) Load(key string) (interface{}, bool) {
 	s.RLock()
 	defer s.RUnlock()
 	result, ok := s.values[key]
-	return result, ok
+	if !ok || result.expired(time.Now()) {
+		s.misses.Add(1)
+		return nil, false
+	}
+	s.hits.Add(1)
+	return result.value, true
 }
 
 // Remove removes the given key.
 func (s *Store) Remove(key string) {
+	s.Lock()
+	defer s.Unlock()
 	delete(s.values, key)
 }
 
-// Exist checks if the given key exists.
+// Exist checks if the given key exists and hasn't expired.
 func (s *Store) Exist(key string) bool {
 	s.RLock()
 	defer s.RUnlock()
-	_, ok := s.values[key]
-	return ok
+	result, ok := s.values[key]
+	return ok && !result.expired(time.Now())
 }
 
 // Save persists the give key/vale combination.
 func (s *Store) Save(key string, value interface{}) error {
+	return s.SaveWithTTL(key, value, 0)
+}
+
+// SaveWithTTL persists the given key/value combination for ttl, a ttl of zero never expires.
+func (s *Store) SaveWithTTL(key string, value interface{}, ttl time.Duration) error {
+	return s.SaveWithSource(key, value, ttl, "")
+}
+
+// SaveWithSource is SaveWithTTL recording where the value came from, e.g. the upstream API.
+func (s *Store) SaveWithSource(key string, value interface{}, ttl time.Duration, source string) error {
+	now := time.Now()
+	e := entry{value: value, createdAt: now, source: source}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+
 	s.Lock()
 	defer s.Unlock()
-	s.values[key] = value
+	s.values[key] = e
 	return nil
 }
 
 // LoadAll is returning all the key/value from the store.
-// It returns a map of keys to values.
+// It returns a map of keys to values, leaving out expired values.
 func (s *Store) LoadAll() (map[string]interface{}, error) {
 	s.RLock()
 	defer s.RUnlock()
+	now := time.Now()
 	copyValues := make(map[string]interface{}, len(s.values))
 	for k, v := range s.values {
-		copyValues[k] = v
+		if !v.expired(now) {
+			copyValues[k] = v.value
+		}
 	}
 	return copyValues, nil
 }
+
+// Inspect returns the value and metadata of key, without counting as a lookup in the stats.
+func (s *Store) Inspect(key string) (Entry, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	e, ok := s.values[key]
+	if !ok || e.expired(time.Now()) {
+		return Entry{}, false
+	}
+	return Entry{Meta: e.meta(key), Value: e.value}, true
+}
+
+// List returns the metadata of the values whose key starts with prefix, sorted by key.
+func (s *Store) List(prefix string) []Meta {
+	s.RLock()
+	defer s.RUnlock()
+	now := time.Now()
+	metas := make([]Meta, 0, len(s.values))
+	for k, v := range s.values {
+		if strings.HasPrefix(k, prefix) && !v.expired(now) {
+			metas = append(metas, v.meta(k))
+		}
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Key < metas[j].Key })
+	return metas
+}
+
+// RemoveMatching removes the keys matching the path.Match pattern, returning how many were removed.
+func (s *Store) RemoveMatching(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	removed := 0
+	for k := range s.values {
+		if matched, _ := path.Match(pattern, k); matched {
+			delete(s.values, k)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Flush removes every value, returning how many were removed.
+func (s *Store) Flush() int {
+	s.Lock()
+	defer s.Unlock()
+	removed := len(s.values)
+	s.values = make(map[string]entry)
+	return removed
+}
+
+// EvictExpired removes the expired values, returning how many were removed.
+func (s *Store) EvictExpired() int {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	evicted := 0
+	for k, v := range s.values {
+		if v.expired(now) {
+			delete(s.values, k)
+			evicted++
+		}
+	}
+	s.evictions.Add(uint64(evicted))
+	return evicted
+}
+
+// RunEviction evicts expired values every interval until ctx is done.
+func (s *Store) RunEviction(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.EvictExpired()
+		}
+	}
+}
+
+// Ping reports whether the store can serve lookups. The in memory store always can, unless it
+// wasn't created with NewStore, it takes ctx so backends with connections can be probed alike.
+func (s *Store) Ping(ctx context.Context) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.values == nil {
+		return errors.New("store not initialized")
+	}
+	return ctx.Err()
+}
+
+// Stats returns the lookup and eviction counters of the store.
+func (s *Store) Stats() Stats {
+	s.RLock()
+	entries := len(s.values)
+	s.RUnlock()
+
+	return Stats{
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Evictions: s.evictions.Load(),
+		Entries:   entries,
+	}
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// StdoutExporter writes every span as a line of JSON, e.g. to follow traces locally without a collector.
+type StdoutExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewStdoutExporter returns an exporter writing to w, usually os.Stdout.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{enc: json.NewEncoder(w)}
+}
+
+type stdoutSpan struct {
+	Name         string                 `json:"name"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Kind         string                 `json:"kind"`
+	Start        time.Time              `json:"start"`
+	Duration     string                 `json:"duration"`
+	Status       string                 `json:"status"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *StdoutExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, s := range spans {
+		out := stdoutSpan{
+			Name:     s.Name(),
+			TraceID:  s.SpanContext().TraceID().String(),
+			SpanID:   s.SpanContext().SpanID().String(),
+			Kind:     s.SpanKind().String(),
+			Start:    s.StartTime(),
+			Duration: s.EndTime().Sub(s.StartTime()).String(),
+			Status:   s.Status().Code.String(),
+		}
+		if s.Parent().IsValid() {
+			out.ParentSpanID = s.Parent().SpanID().String()
+		}
+		if attrs := s.Attributes(); len(attrs) > 0 {
+			out.Attributes = make(map[string]interface{}, len(attrs))
+			for _, kv := range attrs {
+				out.Attributes[string(kv.Key)] = kv.Value.AsInterface()
+			}
+		}
+
+		if err := e.enc.Encode(out); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return ctx.Err()
+}
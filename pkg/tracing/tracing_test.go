@@ -0,0 +1,165 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"pokedex-clone/pkg/tracing"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const inboundTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// upstream serves body and records the span contexts propagated in the requests it receives.
+type upstream struct {
+	mu      sync.Mutex
+	parents []trace.SpanContext
+}
+
+func (u *upstream) serve(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		u.mu.Lock()
+		u.parents = append(u.parents, trace.SpanContextFromContext(ctx))
+		u.mu.Unlock()
+
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestSpanTree(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer(tracing.TracerName)
+
+	var poke, translations upstream
+	pokeServer := httptest.NewServer(poke.serve(`{
+		"name": "mewtwo",
+		"habitat": {"name": "rare"},
+		"is_legendary": true,
+		"flavor_text_entries": [{"flavor_text": "Created by a scientist.", "language": {"name": "en"}}]
+	}`))
+	defer pokeServer.Close()
+	translationsServer := httptest.NewServer(translations.serve(
+		`{"success": {"total": 1}, "contents": {"translated": "Created by a scientist, it was."}}`))
+	defer translationsServer.Close()
+
+	pokeClient := api.NewClient(pokeServer.URL+"/", time.Second)
+	pokeClient.Name = "pokeapi"
+	pokeClient.Tracer = tracer
+	translationsClient := api.NewClient(translationsServer.URL+"/", time.Second)
+	translationsClient.Name = "funtranslations"
+	translationsClient.Tracer = tracer
+
+	service := pokemon.NewService(storage.NewStore(), api.Poke{Client: pokeClient},
+		api.Translations{Client: translationsClient})
+	service.Tracer = tracer
+
+	router := gin.New()
+	router.Use(tracing.Middleware(tracer))
+	router.GET("/pokemon/translated/:name", service.GetTranslated)
+
+	req, err := http.NewRequest(http.MethodGet, "/pokemon/translated/mewtwo", nil)
+	assert.Nil(t, err)
+	req.Header.Set("traceparent", inboundTraceparent)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+
+	server, ok := spans["GET /pokemon/translated/:name"]
+	if !assert.True(t, ok, "server span missing from %v", spans) {
+		return
+	}
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+
+	for _, name := range []string{"storage.Load", "storage.Save", "pokeapi pokemon-species", "funtranslations yoda"} {
+		child, ok := spans[name]
+		if !assert.True(t, ok, "span %q missing", name) {
+			continue
+		}
+		assert.Equal(t, server.SpanContext.TraceID(), child.SpanContext.TraceID(), name)
+		assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID(), name)
+	}
+
+	// upstream requests carry the client span as their parent
+	for name, u := range map[string]*upstream{"pokeapi pokemon-species": &poke, "funtranslations yoda": &translations} {
+		if assert.Len(t, u.parents, 1, name) {
+			assert.Equal(t, spans[name].SpanContext.SpanID(), u.parents[0].SpanID(), name)
+			assert.Equal(t, trace.SpanKindClient, spans[name].SpanKind, name)
+		}
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracing.NewStdoutExporter(&out)))
+
+	router := gin.New()
+	router.Use(tracing.Middleware(provider.Tracer(tracing.TracerName)))
+	router.GET("/healthy", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	req, err := http.NewRequest(http.MethodGet, "/healthy", nil)
+	assert.Nil(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var span map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &span))
+	assert.Equal(t, "GET /healthy", span["name"])
+	assert.Equal(t, "server", span["kind"])
+	assert.Equal(t, "Error", span["status"])
+	assert.Nil(t, span["parent_span_id"])
+	attributes, _ := span["attributes"].(map[string]interface{})
+	assert.Equal(t, float64(http.StatusInternalServerError), attributes["http.status_code"])
+}
+
+func TestGRPCSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	interceptor := tracing.UnaryServerInterceptor(provider.Tracer(tracing.TracerName))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", inboundTraceparent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pokedex.v1.Pokedex/GetPokemon"}
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		// the handler sees the server span
+		assert.True(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "/pokedex.v1.Pokedex/GetPokemon", spans[0].Name)
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].Parent.TraceID().String())
+		assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+	}
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"pokedex-clone/pkg/admin"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// Path is the route prefix of the subscriptions, within the admin routes.
+const Path = "/webhooks"
+
+// SubscribeRequest is the body of POST /admin/webhooks, Events are event type constants.
+type SubscribeRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+// CreatedSubscription is the response of POST /admin/webhooks, the only one carrying the secret signing the
+// deliveries.
+type CreatedSubscription struct {
+	Subscription
+	Secret string `json:"secret"`
+}
+
+// Register adds the webhook routes to group, the admin routes:
+//
+//	GET    /webhooks                              list the subscriptions, without their secrets
+//	POST   /webhooks                              subscribe a URL to event types
+//	GET    /webhooks/:id                          get a subscription
+//	DELETE /webhooks/:id                          remove a subscription
+//	GET    /webhooks/dead-letters                 list the deliveries which failed every attempt
+//	POST   /webhooks/dead-letters/:id/redeliver   queue a dead letter for delivery again
+//	DELETE /webhooks/dead-letters/:id             remove a dead letter
+func (d *Dispatcher) Register(group *gin.RouterGroup) {
+	group.GET(Path, d.List)
+	group.POST(Path, d.Create)
+	group.GET(Path+"/:id", d.Get)
+	group.DELETE(Path+"/:id", d.Delete)
+	group.GET(Path+"/dead-letters", d.ListDeadLetters)
+	group.POST(Path+"/dead-letters/:id/redeliver", d.RedeliverDeadLetter)
+	group.DELETE(Path+"/dead-letters/:id", d.DeleteDeadLetter)
+}
+
+// List returns every subscription.
+func (d *Dispatcher) List(c *gin.Context) {
+	subs := d.Subscriptions()
+	if subs == nil {
+		subs = []Subscription{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": subs})
+}
+
+// Create adds a subscription, the secret is only part of this response.
+func (d *Dispatcher) Create(c *gin.Context) {
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, secret, err := d.Subscribe(req.URL, req.Events)
+	switch {
+	case errors.Is(err, ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin.AddAudit(c, slog.String("webhook_id", sub.ID), slog.String("webhook_url", sub.URL))
+	c.Header("Location", c.FullPath()+"/"+sub.ID)
+	c.JSON(http.StatusCreated, CreatedSubscription{Subscription: sub, Secret: secret})
+}
+
+// Get returns a subscription, without its secret.
+func (d *Dispatcher) Get(c *gin.Context) {
+	sub, ok := d.Lookup(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// Delete removes a subscription.
+func (d *Dispatcher) Delete(c *gin.Context) {
+	id := c.Param("id")
+	admin.AddAudit(c, slog.String("webhook_id", id))
+
+	if err := d.Unsubscribe(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeadLetters returns every dead letter, the last one first.
+func (d *Dispatcher) ListDeadLetters(c *gin.Context) {
+	letters := d.DeadLetters()
+	if letters == nil {
+		letters = []DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dead_letters": letters})
+}
+
+// RedeliverDeadLetter queues a dead letter again, it leaves the list.
+func (d *Dispatcher) RedeliverDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	admin.AddAudit(c, slog.String("delivery_id", id))
+
+	switch err := d.Redeliver(id); {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnsubscribed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteDeadLetter removes a dead letter.
+func (d *Dispatcher) DeleteDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	admin.AddAudit(c, slog.String("delivery_id", id))
+
+	if err := d.RemoveDeadLetter(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
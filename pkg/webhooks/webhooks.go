@@ -0,0 +1,596 @@
+// Webhooks package provides the webhook subscriptions and the delivery of their events: deliveries are signed with
+// the secret of their subscription, retried with exponential backoff by a queue, and kept in a dead-letter list
+// once every attempt failed. Deliveries only reach public addresses, and don't follow redirects.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/queue"
+	"pokedex-clone/pkg/random"
+	"pokedex-clone/pkg/storage"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Event types subscriptions may subscribe to.
+const (
+	// EventTranslationCompleted is published when a description is translated by the translations API, its data
+	// is a Translation.
+	EventTranslationCompleted = "translation.completed"
+	// EventCacheInvalidated is published when cache entries are deleted through the admin API, its data is an
+	// admin.Invalidation.
+	EventCacheInvalidated = "cache.invalidated"
+	// EventUpstreamDegraded is published when an upstream API starts failing, its data is a Degradation.
+	EventUpstreamDegraded = "upstream.degraded"
+)
+
+// Headers of the deliveries.
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+	// TimestampHeader holds the Unix time the delivery was attempted at, it is signed along with the body.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader holds the signature of the delivery, see Sign.
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	subscriptionPrefix = "webhook/subscription/"
+	deliveryPrefix     = "webhook/delivery/"
+	deadLetterPrefix   = "webhook/dead-letter/"
+	signaturePrefix    = "sha256="
+	// maxResponseBody is how much of the response bodies is read, so connections are reused.
+	maxResponseBody = 4096
+	// publishBuffer is the number of events the hooks hand over to Run at most, the next ones are dropped.
+	publishBuffer = 1024
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid is returned when subscribing with an invalid URL or event type.
+	ErrInvalid = errors.New("invalid subscription")
+	// ErrUnsubscribed is returned when redelivering a dead letter whose subscription was removed.
+	ErrUnsubscribed = errors.New("subscription removed")
+	// errPrivate is returned when delivering to an address that isn't public.
+	errPrivate = errors.New("private, loopback and link-local addresses are not allowed")
+)
+
+// Subscription is a URL the events of some types are delivered to.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+
+	secret string
+}
+
+// Event is the body of the deliveries.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DeadLetter is a delivery which failed every attempt.
+type DeadLetter struct {
+	// ID is the ID of the delivery, sent in the DeliveryHeader.
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Event          Event     `json:"event"`
+	Attempts       int       `json:"attempts"`
+	Error          string    `json:"error"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// Translation is the data of the translation.completed events.
+type Translation struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// Degradation is the data of the upstream.degraded events, describing the first failed call.
+type Degradation struct {
+	API        string `json:"api"`
+	Operation  string `json:"operation"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error"`
+}
+
+// delivery is an event waiting to be delivered to a subscription.
+type delivery struct {
+	ID           string
+	Subscription string
+	Event        Event
+}
+
+// Options configure the dispatcher returned by New.
+type Options struct {
+	// Workers is the number of deliveries attempted at once.
+	Workers int
+	// Timeout bounds every attempt.
+	Timeout time.Duration
+	// MaxAttempts is the number of times a delivery is attempted before it is dead-lettered.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubling with every retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// TTL is how long dead letters and finished deliveries are kept for, they are kept until removed from the
+	// store when zero.
+	TTL time.Duration
+	// AllowPrivate lets the subscriptions target private, loopback and link-local addresses, e.g. receivers
+	// running next to the service in tests.
+	AllowPrivate bool
+}
+
+// Dispatcher holds the subscriptions and delivers them the events published, once Run.
+type Dispatcher struct {
+	// Logger logs the events that couldn't be published, New sets the default logger.
+	Logger *slog.Logger
+
+	store        *storage.Store
+	queue        *queue.Queue
+	client       *http.Client
+	ttl          time.Duration
+	allowPrivate bool
+	// events are the events published by the hooks, waiting for Run to queue their deliveries.
+	events chan Event
+
+	mu sync.Mutex
+	// degraded holds the upstream APIs whose last call failed.
+	degraded map[string]bool
+}
+
+// New returns a dispatcher keeping its subscriptions and deliveries in store, apart from the cache so that
+// flushing it unsubscribes no one.
+func New(store *storage.Store, opts Options) *Dispatcher {
+	d := &Dispatcher{
+		Logger:       slog.Default(),
+		store:        store,
+		ttl:          opts.TTL,
+		allowPrivate: opts.AllowPrivate,
+		events:       make(chan Event, publishBuffer),
+		degraded:     make(map[string]bool),
+	}
+	d.client = d.newClient(opts.Timeout)
+	d.queue = queue.New(store, d.deliver, queue.Options{
+		Workers:     opts.Workers,
+		MaxAttempts: opts.MaxAttempts,
+		Backoff:     opts.Backoff,
+		MaxBackoff:  opts.MaxBackoff,
+		TTL:         opts.TTL,
+		Failed:      d.deadLetter,
+	})
+
+	return d
+}
+
+// Run queues the deliveries of the events published by the hooks and delivers them until ctx is done, the
+// deliveries interrupted by the previous run are attempted again.
+func (d *Dispatcher) Run(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.queue.Run(ctx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			return
+		case event := <-d.events:
+			if err := d.dispatch(event); err != nil {
+				d.Logger.Error("failed to publish event", "type", event.Type, "error", err)
+			}
+		}
+	}
+}
+
+// Subscribe adds a subscription delivering the events of the given types to rawURL, returning it along with the
+// secret signing its deliveries.
+func (d *Dispatcher) Subscribe(rawURL string, events []string) (Subscription, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, "", fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalid)
+	}
+	// the host names are only resolved when delivering, which checks the addresses again
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	isLocalhost := strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost")
+	if !d.allowPrivate && (isLocalhost || ip != nil && !public(ip)) {
+		return Subscription{}, "", fmt.Errorf("%w: url must not target a private, loopback or link-local address",
+			ErrInvalid)
+	}
+	if len(events) == 0 {
+		return Subscription{}, "", fmt.Errorf("%w: events are required", ErrInvalid)
+	}
+	for _, event := range events {
+		switch event {
+		case EventTranslationCompleted, EventCacheInvalidated, EventUpstreamDegraded:
+		default:
+			return Subscription{}, "", fmt.Errorf("%w: unknown event type %q", ErrInvalid, event)
+		}
+	}
+
+	id, err := random.Hex(8)
+	if err != nil {
+		return Subscription{}, "", err
+	}
+	secret, err := random.Hex(32)
+	if err != nil {
+		return Subscription{}, "", err
+	}
+
+	sub := Subscription{
+		ID:        id,
+		URL:       u.String(),
+		Events:    append([]string(nil), events...),
+		CreatedAt: time.Now(),
+		secret:    secret,
+	}
+	if err = d.store.Save(subscriptionPrefix+id, sub); err != nil {
+		return Subscription{}, "", err
+	}
+
+	return sub, secret, nil
+}
+
+// Subscriptions returns every subscription, oldest first.
+func (d *Dispatcher) Subscriptions() []Subscription {
+	var subs []Subscription
+	for _, meta := range d.store.List(subscriptionPrefix) {
+		if sub, ok := d.Lookup(strings.TrimPrefix(meta.Key, subscriptionPrefix)); ok {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+
+	return subs
+}
+
+// Lookup returns the subscription id.
+func (d *Dispatcher) Lookup(id string) (Subscription, bool) {
+	value, ok := d.store.Load(subscriptionPrefix + id)
+	if !ok {
+		return Subscription{}, false
+	}
+	sub, ok := value.(Subscription)
+
+	return sub, ok
+}
+
+// Unsubscribe removes the subscription id, its pending deliveries are dropped.
+func (d *Dispatcher) Unsubscribe(id string) error {
+	if !d.store.Exist(subscriptionPrefix + id) {
+		return fmt.Errorf("subscription %w", ErrNotFound)
+	}
+	d.store.Remove(subscriptionPrefix + id)
+
+	return nil
+}
+
+// Publish queues the delivery of an event of the given type, whose data is encoded as JSON, to the subscriptions
+// to that type.
+func (d *Dispatcher) Publish(eventType string, data interface{}) error {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+
+	return d.dispatch(event)
+}
+
+func newEvent(eventType string, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	id, err := random.Hex(16)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{ID: id, Type: eventType, CreatedAt: time.Now(), Data: raw}, nil
+}
+
+// dispatch queues the deliveries of event.
+func (d *Dispatcher) dispatch(event Event) error {
+	for _, sub := range d.Subscriptions() {
+		if !sub.subscribes(event.Type) {
+			continue
+		}
+		if err := d.enqueue(sub.ID, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Translated publishes a translation.completed event for p, it is meant to be the pokemon.Service OnTranslated
+// hook.
+func (d *Dispatcher) Translated(p pokemon.Pokemon) {
+	translation := Translation{ID: p.ID, Name: p.Name, Description: p.Description}
+	if p.Translation != nil {
+		translation.Type = p.Translation.Type
+	}
+	d.publish(EventTranslationCompleted, translation)
+}
+
+// Invalidated publishes a cache.invalidated event, it is meant to be the admin.Cache OnInvalidate hook.
+func (d *Dispatcher) Invalidated(invalidation admin.Invalidation) {
+	d.publish(EventCacheInvalidated, invalidation)
+}
+
+// ObserveCall publishes an upstream.degraded event when an upstream API fails after its previous call didn't, it
+// is meant to be an api.Client hook. Calls fail when no response was received, or when rate limited or answered
+// with a server error, the calls cancelled by their clients are ignored.
+func (d *Dispatcher) ObserveCall(call api.Call) {
+	if errors.Is(call.Err, context.Canceled) {
+		return
+	}
+	failed := call.Err != nil && (call.StatusCode == 0 || call.StatusCode == http.StatusTooManyRequests ||
+		call.StatusCode >= http.StatusInternalServerError)
+
+	d.mu.Lock()
+	degraded := failed && !d.degraded[call.API]
+	d.degraded[call.API] = failed
+	d.mu.Unlock()
+
+	if degraded {
+		d.publish(EventUpstreamDegraded, Degradation{
+			API:        call.API,
+			Operation:  call.Operation,
+			StatusCode: call.StatusCode,
+			Error:      call.Err.Error(),
+		})
+	}
+}
+
+// DeadLetters returns the deliveries which failed every attempt, the last one first.
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	var letters []DeadLetter
+	for _, meta := range d.store.List(deadLetterPrefix) {
+		if letter, ok := d.lookupDeadLetter(strings.TrimPrefix(meta.Key, deadLetterPrefix)); ok {
+			letters = append(letters, letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].FailedAt.After(letters[j].FailedAt) })
+
+	return letters
+}
+
+// Redeliver queues the delivery of the dead letter id again, removing it from the list.
+func (d *Dispatcher) Redeliver(id string) error {
+	letter, ok := d.lookupDeadLetter(id)
+	if !ok {
+		return fmt.Errorf("dead letter %w", ErrNotFound)
+	}
+	if _, ok = d.Lookup(letter.SubscriptionID); !ok {
+		return ErrUnsubscribed
+	}
+
+	if err := d.save(delivery{ID: id, Subscription: letter.SubscriptionID, Event: letter.Event}); err != nil {
+		return err
+	}
+	if _, err := d.queue.Enqueue(queue.Spec{Key: id, Payload: id, Priority: queue.PriorityNormal}); err != nil {
+		return err
+	}
+	d.store.Remove(deadLetterPrefix + id)
+
+	return nil
+}
+
+// RemoveDeadLetter removes the dead letter id from the list.
+func (d *Dispatcher) RemoveDeadLetter(id string) error {
+	if !d.store.Exist(deadLetterPrefix + id) {
+		return fmt.Errorf("dead letter %w", ErrNotFound)
+	}
+	d.store.Remove(deadLetterPrefix + id)
+
+	return nil
+}
+
+// Sign returns the signature of a delivery attempted at timestamp, a Unix time: the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the secret of the subscription and prefixed with "sha256=".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// publish is Publish for the hooks, which are called on the request path: the event is handed over to Run, and
+// dropped when too many are waiting already, rather than holding the request.
+func (d *Dispatcher) publish(eventType string, data interface{}) {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		d.Logger.Error("failed to publish event", "type", eventType, "error", err)
+		return
+	}
+
+	select {
+	case d.events <- event:
+	default:
+		d.Logger.Warn("too many events waiting, dropping event", "type", eventType, "id", event.ID)
+	}
+}
+
+func (d *Dispatcher) enqueue(subscription string, event Event) error {
+	id, err := random.Hex(16)
+	if err != nil {
+		return err
+	}
+	if err = d.save(delivery{ID: id, Subscription: subscription, Event: event}); err != nil {
+		return err
+	}
+	_, err = d.queue.Enqueue(queue.Spec{Key: id, Payload: id, Priority: queue.PriorityNormal})
+
+	return err
+}
+
+// deliver attempts the delivery id, it is the handler of the queue. Deliveries to removed subscriptions are
+// dropped.
+func (d *Dispatcher) deliver(ctx context.Context, id string) error {
+	dl, ok := d.lookupDelivery(id)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("delivery %w", ErrNotFound))
+	}
+	sub, ok := d.Lookup(dl.Subscription)
+	if !ok {
+		d.store.Remove(deliveryPrefix + id)
+		return nil
+	}
+
+	body, err := json.Marshal(dl.Event)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(EventHeader, dl.Event.Type)
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(sub.secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBody))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	d.store.Remove(deliveryPrefix + id)
+
+	return nil
+}
+
+// deadLetter keeps the delivery of task aside, it is called by the queue once every attempt failed.
+func (d *Dispatcher) deadLetter(task queue.Task, id string) {
+	dl, ok := d.lookupDelivery(id)
+	if !ok {
+		return
+	}
+	d.store.Remove(deliveryPrefix + id)
+	sub, ok := d.Lookup(dl.Subscription)
+	if !ok {
+		return
+	}
+
+	letter := DeadLetter{
+		ID:             id,
+		SubscriptionID: sub.ID,
+		URL:            sub.URL,
+		Event:          dl.Event,
+		Attempts:       task.Attempts,
+		Error:          task.Error,
+		FailedAt:       task.UpdatedAt,
+	}
+	if err := d.store.SaveWithTTL(deadLetterPrefix+id, letter, d.ttl); err != nil {
+		d.Logger.Error("failed to save dead letter", "id", id, "error", err)
+	}
+}
+
+// newClient returns the client of the deliveries, which doesn't follow redirects, and refuses to connect to the
+// addresses that aren't public unless allowed, whatever the host names of the subscriptions resolve to.
+func (d *Dispatcher) newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !d.allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !public(ip) {
+				return fmt.Errorf("connecting to %s: %w", host, errPrivate)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would connect on behalf of the deliveries, out of reach of the dialer
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// public tells whether ip is a public address, neither private, loopback, link-local, multicast nor unspecified.
+func public(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+func (d *Dispatcher) lookupDelivery(id string) (delivery, bool) {
+	value, ok := d.store.Load(deliveryPrefix + id)
+	if !ok {
+		return delivery{}, false
+	}
+	dl, ok := value.(delivery)
+
+	return dl, ok
+}
+
+func (d *Dispatcher) lookupDeadLetter(id string) (DeadLetter, bool) {
+	value, ok := d.store.Load(deadLetterPrefix + id)
+	if !ok {
+		return DeadLetter{}, false
+	}
+	letter, ok := value.(DeadLetter)
+
+	return letter, ok
+}
+
+func (d *Dispatcher) save(dl delivery) error {
+	return d.store.Save(deliveryPrefix+dl.ID, dl)
+}
+
+func (s Subscription) subscribes(eventType string) bool {
+	for _, event := range s.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
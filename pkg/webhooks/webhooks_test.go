@@ -0,0 +1,354 @@
+package webhooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pokedex-clone/pkg/admin"
+	"pokedex-clone/pkg/api"
+	"pokedex-clone/pkg/pokemon"
+	"pokedex-clone/pkg/storage"
+	"pokedex-clone/pkg/webhooks"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver is a webhook endpoint recording the deliveries whose signature is valid, answering them with the
+// status returned by status.
+type receiver struct {
+	*httptest.Server
+	secret string
+
+	mu         sync.Mutex
+	deliveries []delivery
+	attempts   int
+	status     func(attempt int) int
+}
+
+type delivery struct {
+	ID    string
+	Event webhooks.Event
+}
+
+func newReceiver(t *testing.T, status func(attempt int) int) *receiver {
+	t.Helper()
+
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func (r *receiver) handle(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+
+	want := webhooks.Sign(r.secret, req.Header.Get(webhooks.TimestampHeader), body)
+	if !hmac.Equal([]byte(want), []byte(req.Header.Get(webhooks.SignatureHeader))) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.status != nil {
+		if status := r.status(r.attempts); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	var event webhooks.Event
+	if err = json.Unmarshal(body, &event); err != nil || event.Type != req.Header.Get(webhooks.EventHeader) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.deliveries = append(r.deliveries, delivery{ID: req.Header.Get(webhooks.DeliveryHeader), Event: event})
+}
+
+func (r *receiver) received() []delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]delivery(nil), r.deliveries...)
+}
+
+func options() webhooks.Options {
+	return webhooks.Options{
+		Workers:     1,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  2 * time.Millisecond,
+		// the receivers listen on the loopback interface
+		AllowPrivate: true,
+	}
+}
+
+func run(t *testing.T, d *webhooks.Dispatcher) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func subscribe(t *testing.T, d *webhooks.Dispatcher, r *receiver, events ...string) webhooks.Subscription {
+	t.Helper()
+
+	sub, secret, err := d.Subscribe(r.URL, events)
+	assert.Nil(t, err)
+	r.mu.Lock()
+	r.secret = secret
+	r.mu.Unlock()
+
+	return sub
+}
+
+func TestDelivery(t *testing.T) {
+	d := webhooks.New(storage.NewStore(), options())
+	translations := newReceiver(t, nil)
+	subscribe(t, d, translations, webhooks.EventTranslationCompleted)
+	everything := newReceiver(t, nil)
+	subscribe(t, d, everything,
+		webhooks.EventTranslationCompleted, webhooks.EventCacheInvalidated, webhooks.EventUpstreamDegraded)
+	run(t, d)
+
+	d.Translated(pokemon.Pokemon{
+		ID:          150,
+		Name:        "mewtwo",
+		Description: "Created by a scientist, it was.",
+		Translation: &pokemon.TranslationInfo{Type: "yoda", Translated: true},
+	})
+	d.Invalidated(admin.Invalidation{Pattern: "mewtwo*", Removed: 2})
+
+	assert.Eventually(t, func() bool {
+		return len(translations.received()) == 1 && len(everything.received()) == 2
+	}, time.Second, time.Millisecond)
+
+	translation := translations.received()[0]
+	assert.Equal(t, webhooks.EventTranslationCompleted, translation.Event.Type)
+	assert.JSONEq(t, `{"id": 150, "name": "mewtwo", "description": "Created by a scientist, it was.", "type": "yoda"}`,
+		string(translation.Event.Data))
+
+	types := map[string]string{}
+	for _, received := range everything.received() {
+		types[received.Event.Type] = string(received.Event.Data)
+	}
+	assert.JSONEq(t, `{"pattern": "mewtwo*", "removed": 2}`, types[webhooks.EventCacheInvalidated])
+	// both subscriptions got the same event, in deliveries of their own
+	assert.Equal(t, translation.Event.ID, everything.received()[0].Event.ID)
+	assert.NotEqual(t, translation.ID, everything.received()[0].ID)
+
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDeadLetters(t *testing.T) {
+	down := true
+	var mu sync.Mutex
+	r := newReceiver(t, func(int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	d := webhooks.New(storage.NewStore(), options())
+	sub := subscribe(t, d, r, webhooks.EventCacheInvalidated)
+	run(t, d)
+
+	assert.Nil(t, d.Publish(webhooks.EventCacheInvalidated, admin.Invalidation{Flush: true, Removed: 5}))
+
+	var letters []webhooks.DeadLetter
+	assert.Eventually(t, func() bool {
+		letters = d.DeadLetters()
+		return len(letters) == 1
+	}, time.Second, time.Millisecond)
+	letter := letters[0]
+	assert.Equal(t, sub.ID, letter.SubscriptionID)
+	assert.Equal(t, r.URL, letter.URL)
+	assert.Equal(t, 3, letter.Attempts)
+	assert.Equal(t, "unexpected status code: 503", letter.Error)
+	assert.Empty(t, r.received())
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	assert.Nil(t, d.Redeliver(letter.ID))
+	assert.Empty(t, d.DeadLetters())
+
+	assert.Eventually(t, func() bool { return len(r.received()) == 1 }, time.Second, time.Millisecond)
+	received := r.received()[0]
+	assert.Equal(t, letter.ID, received.ID)
+	assert.Equal(t, letter.Event.ID, received.Event.ID)
+
+	assert.ErrorIs(t, d.Redeliver(letter.ID), webhooks.ErrNotFound)
+}
+
+func TestRedirectsAreNotFollowed(t *testing.T) {
+	target := newReceiver(t, nil)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+
+	d := webhooks.New(storage.NewStore(), options())
+	sub, _, err := d.Subscribe(redirect.URL, []string{webhooks.EventCacheInvalidated})
+	assert.Nil(t, err)
+	run(t, d)
+
+	assert.Nil(t, d.Publish(webhooks.EventCacheInvalidated, admin.Invalidation{Flush: true}))
+
+	var letters []webhooks.DeadLetter
+	assert.Eventually(t, func() bool {
+		letters = d.DeadLetters()
+		return len(letters) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, sub.ID, letters[0].SubscriptionID)
+	assert.Equal(t, "unexpected status code: 307", letters[0].Error)
+	assert.Empty(t, target.received())
+}
+
+func TestObserveCall(t *testing.T) {
+	r := newReceiver(t, nil)
+	d := webhooks.New(storage.NewStore(), options())
+	subscribe(t, d, r, webhooks.EventUpstreamDegraded)
+
+	unavailable := errors.New("unknown error, status code: 503")
+	for _, call := range []api.Call{
+		{API: "pokeapi", Operation: "pokemon-species", StatusCode: http.StatusOK},
+		// not found calls don't degrade the upstream API, cancelled ones are ignored
+		{API: "pokeapi", Operation: "pokemon-species", StatusCode: http.StatusNotFound, Err: errors.New("not found")},
+		{API: "pokeapi", Operation: "pokemon-species", Err: context.Canceled},
+		{API: "pokeapi", Operation: "pokemon-species", StatusCode: http.StatusServiceUnavailable, Err: unavailable},
+		// the upstream API is degraded already
+		{API: "pokeapi", Operation: "pokemon", Err: context.DeadlineExceeded},
+		{API: "funtranslations", Operation: "yoda", StatusCode: http.StatusTooManyRequests, Err: errors.New("429")},
+		// it recovers and fails again
+		{API: "pokeapi", Operation: "pokemon", StatusCode: http.StatusOK},
+		{API: "pokeapi", Operation: "pokemon", Err: context.DeadlineExceeded},
+	} {
+		d.ObserveCall(call)
+	}
+	run(t, d)
+
+	assert.Eventually(t, func() bool { return len(r.received()) == 3 }, time.Second, time.Millisecond)
+	var data []string
+	for _, received := range r.received() {
+		data = append(data, string(received.Event.Data))
+	}
+	assert.ElementsMatch(t, []string{
+		`{"api":"pokeapi","operation":"pokemon-species","status_code":503,"error":"unknown error, status code: 503"}`,
+		`{"api":"funtranslations","operation":"yoda","status_code":429,"error":"429"}`,
+		`{"api":"pokeapi","operation":"pokemon","error":"context deadline exceeded"}`,
+	}, data)
+}
+
+func TestSubscriptions(t *testing.T) {
+	opts := options()
+	opts.AllowPrivate = false
+	d := webhooks.New(storage.NewStore(), opts)
+	router := gin.New()
+	d.Register(router.Group("/admin"))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+
+	tests := map[string]struct {
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		"subscription": {
+			body:       `{"url": "https://example.com/hooks", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusCreated,
+		},
+		"relative url": {
+			body:       `{"url": "/hooks", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid subscription: url must be an absolute http or https URL",
+		},
+		"loopback url": {
+			body:       `{"url": "http://127.0.0.1:8080/hooks", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid subscription: url must not target a private, loopback or link-local address",
+		},
+		"localhost url": {
+			body:       `{"url": "http://localhost/hooks", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"link-local url": {
+			body:       `{"url": "http://169.254.169.254/latest/meta-data", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"private url": {
+			body:       `{"url": "https://[fd00::1]/hooks", "events": ["translation.completed"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"unknown event type": {
+			body:       `{"url": "https://example.com/hooks", "events": ["pokemon.caught"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  `invalid subscription: unknown event type \"pokemon.caught\"`,
+		},
+		"no event types": {
+			body:       `{"url": "https://example.com/hooks", "events": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := serve(http.MethodPost, "/admin"+webhooks.Path, tc.body)
+			assert.Equal(t, tc.wantStatus, rr.Code, rr.Body.String())
+			if tc.wantError != "" {
+				assert.JSONEq(t, `{"error": "`+tc.wantError+`"}`, rr.Body.String())
+			}
+		})
+	}
+
+	subs := d.Subscriptions()
+	if !assert.Len(t, subs, 1) {
+		return
+	}
+	target := "/admin" + webhooks.Path + "/" + subs[0].ID
+
+	rr := serve(http.MethodGet, target, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+	rr = serve(http.MethodGet, "/admin"+webhooks.Path, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, target, "").Code)
+
+	rr = serve(http.MethodGet, "/admin"+webhooks.Path+"/dead-letters", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"dead_letters": []}`, rr.Body.String())
+	rr = serve(http.MethodPost, "/admin"+webhooks.Path+"/dead-letters/unknown/redeliver", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}